@@ -5,33 +5,87 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
 
 	"github.com/steipete/gifgrep/internal/termcaps"
 )
 
 type report struct {
-	Detected     string `json:"detected"`
-	TermProgram  string `json:"term_program,omitempty"`
-	Term         string `json:"term,omitempty"`
-	ItermSession string `json:"iterm_session_id,omitempty"`
-	KittyWindow  string `json:"kitty_window_id,omitempty"`
+	termcaps.Report
+	// ProviderKeys records which provider API keys are set (never their values).
+	ProviderKeys map[string]bool `json:"provider_keys"`
+}
+
+var providerKeys = []struct{ name, env string }{
+	{"tenor", "TENOR_API_KEY"},
+	{"giphy", "GIPHY_API_KEY"},
+	{"heypster", "HEYPSTER_API_KEY"},
+}
+
+func (r report) fields() []termcaps.Field {
+	fields := r.Fields()
+	for _, p := range providerKeys {
+		fields = append(fields, termcaps.Field{Key: p.name + "_key", Value: strconv.FormatBool(r.ProviderKeys[p.name])})
+	}
+	return fields
+}
+
+// expectations collects --expect values. Each is a comma-separated list of
+// key=value pairs; a bare value is shorthand for detected=<value>.
+type expectations []termcaps.Field
+
+func (e *expectations) String() string { return "" }
+
+func (e *expectations) Set(v string) error {
+	for _, part := range strings.Split(v, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			key, value = "detected", part
+		}
+		*e = append(*e, termcaps.Field{Key: strings.TrimSpace(key), Value: strings.TrimSpace(value)})
+	}
+	return nil
+}
+
+// check returns one message per unmet expectation.
+func (e expectations) check(fields []termcaps.Field) []string {
+	got := make(map[string]string, len(fields))
+	for _, f := range fields {
+		got[f.Key] = f.Value
+	}
+	var failures []string
+	for _, want := range e {
+		v, ok := got[want.Key]
+		switch {
+		case !ok:
+			failures = append(failures, fmt.Sprintf("unknown key %q", want.Key))
+		case v != want.Value:
+			failures = append(failures, fmt.Sprintf("%s: expected %q, got %q", want.Key, want.Value, v))
+		}
+	}
+	return failures
 }
 
 func main() {
-	var expect string
-	var asJSON bool
-	flag.StringVar(&expect, "expect", "", "Expected protocol: none|kitty|iterm (optional)")
-	flag.BoolVar(&asJSON, "json", true, "Emit JSON")
+	var expect expectations
+	var asJSON, probe bool
+	flag.Var(&expect, "expect", "Expected values, e.g. kitty or detected=sixel,truecolor=true,cell=10x20 (repeatable)")
+	flag.BoolVar(&asJSON, "json", true, "Emit JSON (false: aligned key/value text)")
+	flag.BoolVar(&probe, "probe", true, "Query the terminal over /dev/tty (false: environment only)")
 	flag.Parse()
 
-	detected := termcaps.DetectInlineRobust(os.Getenv)
-
 	r := report{
-		Detected:     detected.String(),
-		TermProgram:  os.Getenv("TERM_PROGRAM"),
-		Term:         os.Getenv("TERM"),
-		ItermSession: os.Getenv("ITERM_SESSION_ID"),
-		KittyWindow:  os.Getenv("KITTY_WINDOW_ID"),
+		Report:       termcaps.Inspect(os.Getenv, probe),
+		ProviderKeys: map[string]bool{},
+	}
+	for _, p := range providerKeys {
+		r.ProviderKeys[p.name] = strings.TrimSpace(os.Getenv(p.env)) != ""
 	}
 
 	if asJSON {
@@ -42,14 +96,24 @@ func main() {
 			os.Exit(1)
 		}
 	} else {
-		if _, err := fmt.Fprintln(os.Stdout, r.Detected); err != nil {
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, f := range r.fields() {
+			v := f.Value
+			if v == "" {
+				v = "-"
+			}
+			_, _ = fmt.Fprintf(tw, "%s\t%s\n", f.Key, v)
+		}
+		if err := tw.Flush(); err != nil {
 			_, _ = fmt.Fprintf(os.Stderr, "write: %v\n", err)
 			os.Exit(1)
 		}
 	}
 
-	if expect != "" && expect != r.Detected {
-		fmt.Fprintf(os.Stderr, "expected %q, got %q\n", expect, r.Detected)
+	if failures := expect.check(r.fields()); len(failures) > 0 {
+		for _, f := range failures {
+			_, _ = fmt.Fprintln(os.Stderr, f)
+		}
 		os.Exit(1)
 	}
 }
@@ -0,0 +1,52 @@
+package gifdecode
+
+import "errors"
+
+// ErrNotGIF is returned when editing data that is not a GIF.
+var ErrNotGIF = errors.New("not a gif")
+
+// AddComment returns a copy of data with a comment extension holding text
+// inserted after the logical screen descriptor (and global color table), so
+// readers find it before any frame.
+func AddComment(data []byte, text string) ([]byte, error) {
+	hdr, _, ok := walkGIF(data, func(gifBlock) bool { return false })
+	if !ok {
+		return nil, ErrNotGIF
+	}
+	pos := 13
+	if hdr.Fields&0x80 != 0 {
+		pos += colorTableSize(hdr.Fields)
+	}
+	if pos > len(data) {
+		return nil, ErrNotGIF
+	}
+	block := []byte{gifExtension, extComment}
+	for rest := []byte(text); len(rest) > 0; {
+		n := min(len(rest), 255)
+		block = append(block, byte(n))
+		block = append(block, rest[:n]...)
+		rest = rest[n:]
+	}
+	block = append(block, 0)
+
+	out := make([]byte, 0, len(data)+len(block))
+	out = append(out, data[:pos]...)
+	out = append(out, block...)
+	return append(out, data[pos:]...), nil
+}
+
+// subBlockData joins the payloads of the data sub-blocks starting at
+// block[0] (a size byte), stopping at the terminator.
+func subBlockData(block []byte) []byte {
+	var out []byte
+	for pos := 0; pos < len(block); {
+		size := int(block[pos])
+		pos++
+		if size == 0 || pos+size > len(block) {
+			break
+		}
+		out = append(out, block[pos:pos+size]...)
+		pos += size
+	}
+	return out
+}
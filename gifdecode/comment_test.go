@@ -0,0 +1,41 @@
+package gifdecode
+
+import (
+	"bytes"
+	"errors"
+	"image/gif"
+	"strings"
+	"testing"
+)
+
+func TestAddCommentRoundTrip(t *testing.T) {
+	src := makeTestGIF(3)
+	text := "gifgrep provenance\n" + strings.Repeat("long title ", 40)
+	out, err := AddComment(src, text)
+	if err != nil {
+		t.Fatalf("add comment: %v", err)
+	}
+	info, err := Inspect(out)
+	if err != nil {
+		t.Fatalf("inspect: %v", err)
+	}
+	if len(info.Comments) != 1 || info.Comments[0] != text {
+		t.Fatalf("comments = %q", info.Comments)
+	}
+	if info.Frames != 3 || !info.Complete {
+		t.Fatalf("frames %d complete %v", info.Frames, info.Complete)
+	}
+	g, err := gif.DecodeAll(bytes.NewReader(out))
+	if err != nil {
+		t.Fatalf("image/gif rejects the result: %v", err)
+	}
+	if len(g.Image) != 3 {
+		t.Fatalf("decoded %d frames", len(g.Image))
+	}
+}
+
+func TestAddCommentRejectsNonGIF(t *testing.T) {
+	if _, err := AddComment([]byte("\x89PNG\r\n\x1a\n0000000"), "x"); !errors.Is(err, ErrNotGIF) {
+		t.Fatalf("err = %v", err)
+	}
+}
@@ -44,11 +44,26 @@ func DecodeReader(r io.Reader, opts Options) (*Frames, error) {
 }
 
 func decodeBytes(data []byte, opts Options) (*Frames, error) {
+	if layout, ok := scanGIF(data, opts.MaxFrames); ok {
+		if err := checkLayout(layout, opts); err != nil {
+			return nil, err
+		}
+		if layout.Truncated {
+			data = truncatedGIF(data, layout)
+		}
+	}
 	g, err := gif.DecodeAll(bytes.NewReader(data))
 	if err != nil {
 		if opts.StrictGIF {
 			return nil, err
 		}
+		cfg, _, cfgErr := image.DecodeConfig(bytes.NewReader(data))
+		if cfgErr != nil {
+			return nil, err
+		}
+		if exceedsPixels(cfg.Width, cfg.Height, opts.MaxPixels) {
+			return nil, fmt.Errorf("%w: pixels=%d limit=%d", ErrTooLarge, int64(cfg.Width)*int64(cfg.Height), opts.MaxPixels)
+		}
 		img, _, imgErr := image.Decode(bytes.NewReader(data))
 		if imgErr != nil {
 			return nil, err
@@ -58,6 +73,20 @@ func decodeBytes(data []byte, opts Options) (*Frames, error) {
 	return decodeGIF(g, opts)
 }
 
+// checkLayout applies the pixel limits before image/gif allocates frames.
+func checkLayout(layout gifLayout, opts Options) error {
+	if layout.Width <= 0 || layout.Height <= 0 {
+		return nil
+	}
+	if exceedsPixels(layout.Width, layout.Height, opts.MaxPixels) {
+		return fmt.Errorf("%w: pixels=%d limit=%d", ErrTooLarge, layout.Width*layout.Height, opts.MaxPixels)
+	}
+	if exceedsTotalPixels(layout.Frames, layout.Width, layout.Height, opts.MaxTotalPixels) {
+		return fmt.Errorf("%w: total pixels=%d limit=%d", ErrTooLarge, int64(layout.Frames)*int64(layout.Width)*int64(layout.Height), opts.MaxTotalPixels)
+	}
+	return nil
+}
+
 func decodeGIF(g *gif.GIF, opts Options) (*Frames, error) {
 	if len(g.Image) == 0 {
 		return nil, ErrNoFrames
@@ -83,7 +112,11 @@ func decodeGIF(g *gif.GIF, opts Options) (*Frames, error) {
 	if opts.MaxFrames > 0 && opts.MaxFrames < limit {
 		limit = opts.MaxFrames
 	}
+	if exceedsTotalPixels(limit, width, height, opts.MaxTotalPixels) {
+		return nil, fmt.Errorf("%w: total pixels=%d limit=%d", ErrTooLarge, int64(limit)*int64(width)*int64(height), opts.MaxTotalPixels)
+	}
 	frames := make([]Frame, 0, limit)
+	var outputBytes int64
 
 	for i := 0; i < limit; i++ {
 		frame := g.Image[i]
@@ -100,6 +133,10 @@ func decodeGIF(g *gif.GIF, opts Options) (*Frames, error) {
 		if err != nil {
 			return nil, err
 		}
+		outputBytes += int64(len(pngData))
+		if exceedsBudget(outputBytes, opts.MaxOutputBytes) {
+			return nil, fmt.Errorf("%w: output bytes=%d limit=%d", ErrTooLarge, outputBytes, opts.MaxOutputBytes)
+		}
 		frames = append(frames, Frame{PNG: pngData, Delay: frameDelay(g, i, opts)})
 
 		switch disposal {
@@ -126,6 +163,9 @@ func singleFrame(img image.Image, opts Options) (*Frames, error) {
 	if err != nil {
 		return nil, err
 	}
+	if exceedsBudget(int64(len(pngData)), opts.MaxOutputBytes) {
+		return nil, fmt.Errorf("%w: output bytes=%d limit=%d", ErrTooLarge, len(pngData), opts.MaxOutputBytes)
+	}
 	return &Frames{
 		Frames: []Frame{{PNG: pngData, Delay: clampDelay(opts.DefaultDelay, opts)}},
 		Width:  width,
@@ -205,3 +245,14 @@ func exceedsPixels(width, height, maxPixels int) bool {
 	pixels := int64(width) * int64(height)
 	return pixels > int64(maxPixels)
 }
+
+func exceedsTotalPixels(frames, width, height int, maxTotal int64) bool {
+	if maxTotal <= 0 {
+		return false
+	}
+	return int64(frames)*int64(width)*int64(height) > maxTotal
+}
+
+func exceedsBudget(used, budget int64) bool {
+	return budget > 0 && used > budget
+}
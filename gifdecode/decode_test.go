@@ -76,6 +76,90 @@ func TestDecodeLimits(t *testing.T) {
 	}
 }
 
+func TestDecodeTotalWorkLimits(t *testing.T) {
+	data := makeTestGIF(3)
+
+	opts := DefaultOptions()
+	opts.MaxTotalPixels = 11
+	_, err := Decode(data, opts)
+	if !errors.Is(err, ErrTooLarge) {
+		t.Fatalf("expected ErrTooLarge for total pixels, got %v", err)
+	}
+
+	opts.MaxFrames = 2
+	if _, err := Decode(data, opts); err != nil {
+		t.Fatalf("expected frame cap to keep total pixels in budget, got %v", err)
+	}
+
+	opts = DefaultOptions()
+	opts.MaxOutputBytes = 1
+	_, err = Decode(data, opts)
+	if !errors.Is(err, ErrTooLarge) {
+		t.Fatalf("expected ErrTooLarge for output bytes, got %v", err)
+	}
+
+	opts = DefaultOptions()
+	opts.MaxTotalPixels = -1
+	opts.MaxOutputBytes = -1
+	frames, err := Decode(data, opts)
+	if err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	if len(frames.Frames) != 3 {
+		t.Fatalf("expected 3 frames, got %d", len(frames.Frames))
+	}
+}
+
+func TestScanGIFTruncatesExtraFrames(t *testing.T) {
+	data := makeTestGIF(3)
+	layout, ok := scanGIF(data, 1)
+	if !ok {
+		t.Fatalf("expected scan to succeed")
+	}
+	if layout.Width != 2 || layout.Height != 2 || layout.Frames != 1 || !layout.Truncated {
+		t.Fatalf("unexpected layout: %+v", layout)
+	}
+	g, err := gif.DecodeAll(bytes.NewReader(truncatedGIF(data, layout)))
+	if err != nil {
+		t.Fatalf("decode truncated: %v", err)
+	}
+	if len(g.Image) != 1 {
+		t.Fatalf("expected 1 frame, got %d", len(g.Image))
+	}
+
+	layout, ok = scanGIF(data, 0)
+	if !ok || layout.Frames != 3 || layout.Truncated {
+		t.Fatalf("unexpected full layout: %+v", layout)
+	}
+	if _, ok := scanGIF([]byte("nope"), 0); ok {
+		t.Fatalf("expected scan to reject non-gif data")
+	}
+}
+
+func TestDecodeRejectsOversizedCanvasBeforeDecoding(t *testing.T) {
+	data := makeTestGIF(1)
+	// Claim a 65535x65535 logical screen; image/gif would reject the frame
+	// bounds, but the limit must trip first.
+	data[6], data[7], data[8], data[9] = 0xff, 0xff, 0xff, 0xff
+	_, err := Decode(data, DefaultOptions())
+	if !errors.Is(err, ErrTooLarge) {
+		t.Fatalf("expected ErrTooLarge, got %v", err)
+	}
+}
+
+func TestSingleFrameOutputBudget(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("png encode failed: %v", err)
+	}
+	opts := DefaultOptions()
+	opts.MaxOutputBytes = 1
+	if _, err := Decode(buf.Bytes(), opts); !errors.Is(err, ErrTooLarge) {
+		t.Fatalf("expected ErrTooLarge, got %v", err)
+	}
+}
+
 func TestBackgroundDisposalUsesColor(t *testing.T) {
 	data := makeBackgroundGIF()
 	frames, err := Decode(data, DefaultOptions())
@@ -287,6 +371,9 @@ func TestWithDefaultsMaxDelayClamp(t *testing.T) {
 	if opts.MaxDelay != opts.MinDelay {
 		t.Fatalf("expected MaxDelay to be clamped to MinDelay")
 	}
+	if opts.MaxTotalPixels != defaultMaxTotalPixels || opts.MaxOutputBytes != defaultMaxOutputBytes {
+		t.Fatalf("expected total work limits to default")
+	}
 }
 
 func makeTestGIF(count int) []byte {
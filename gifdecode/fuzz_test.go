@@ -0,0 +1,103 @@
+package gifdecode
+
+import (
+	"bytes"
+	"image/png"
+	"testing"
+)
+
+var fuzzFixtures = []string{
+	"animexample2.gif",
+	"youtube-loading-3.gif",
+	"knowledge-human-pink.gif",
+	"animation-loading1.gif",
+	"walk-cycle.gif",
+}
+
+func addDecodeSeeds(f *testing.F) {
+	f.Helper()
+	for _, name := range fuzzFixtures {
+		f.Add(readFixture(f, name))
+	}
+	f.Add(makeTestGIF(3))
+	f.Add(makeBackgroundGIF())
+	f.Add(makeOutOfRangeBackgroundGIF())
+	f.Add(makeDelayGIF([]int{0, 1, 300}))
+	f.Add([]byte("GIF89a"))
+	f.Add([]byte("nope"))
+}
+
+func fuzzOptions() Options {
+	opts := DefaultOptions()
+	opts.MaxPixels = 1 << 20
+	opts.MaxTotalPixels = 1 << 22
+	opts.MaxOutputBytes = 8 << 20
+	opts.MaxFrames = 8
+	return opts
+}
+
+func FuzzDecode(f *testing.F) {
+	addDecodeSeeds(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		opts := fuzzOptions()
+		frames, err := Decode(data, opts)
+		if err != nil {
+			return
+		}
+		if frames.Width <= 0 || frames.Height <= 0 {
+			t.Fatalf("invalid size %dx%d", frames.Width, frames.Height)
+		}
+		if len(frames.Frames) == 0 || len(frames.Frames) > opts.MaxFrames {
+			t.Fatalf("unexpected frame count %d", len(frames.Frames))
+		}
+		for i, frame := range frames.Frames {
+			if frame.Delay < opts.MinDelay || frame.Delay > opts.MaxDelay {
+				t.Fatalf("frame %d delay %v out of range", i, frame.Delay)
+			}
+			cfg, err := png.DecodeConfig(bytes.NewReader(frame.PNG))
+			if err != nil {
+				t.Fatalf("frame %d png: %v", i, err)
+			}
+			if cfg.Width != frames.Width || cfg.Height != frames.Height {
+				t.Fatalf("frame %d size %dx%d, want %dx%d", i, cfg.Width, cfg.Height, frames.Width, frames.Height)
+			}
+		}
+	})
+}
+
+func FuzzScanGIF(f *testing.F) {
+	addDecodeSeeds(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		layout, ok := scanGIF(data, 2)
+		if !ok {
+			return
+		}
+		if layout.Frames < 1 || layout.Frames > 2 {
+			t.Fatalf("unexpected frame count %d", layout.Frames)
+		}
+		if layout.End <= 13 || layout.End > len(data) {
+			t.Fatalf("end offset %d out of range (len %d)", layout.End, len(data))
+		}
+		if layout.Truncated {
+			if out := truncatedGIF(data, layout); out[len(out)-1] != gifTrailer {
+				t.Fatalf("missing trailer")
+			}
+		}
+	})
+}
+
+func FuzzInspect(f *testing.F) {
+	addDecodeSeeds(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		info, err := Inspect(data)
+		if err != nil {
+			return
+		}
+		if info.Frames < 1 {
+			t.Fatalf("expected at least one frame, got %d", info.Frames)
+		}
+		if info.Format == "gif" && len(info.Delays) != info.Frames {
+			t.Fatalf("delays %d != frames %d", len(info.Delays), info.Frames)
+		}
+	})
+}
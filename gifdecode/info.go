@@ -0,0 +1,114 @@
+package gifdecode
+
+import (
+	"bytes"
+	"image"
+	"time"
+)
+
+// Info is GIF metadata read from the block structure, without decoding pixels.
+type Info struct {
+	Format  string
+	Version string
+	Width   int
+	Height  int
+	Frames  int
+	// Delays are the per-frame delays as stored in the file (not clamped).
+	Delays   []time.Duration
+	Duration time.Duration
+	// LoopCount is 0 for "loop forever", -1 when the GIF has no loop
+	// extension (play once), else the number of extra repetitions.
+	LoopCount         int
+	GlobalPaletteSize int
+	LocalPalettes     int
+	Transparent       bool
+	Complete          bool
+	// Comments are the texts of the comment extensions, in file order.
+	Comments []string
+}
+
+// Inspect reads GIF metadata. Non-GIF images (PNG, JPEG) report their format
+// and dimensions as a single frame.
+func Inspect(data []byte) (*Info, error) {
+	info := &Info{Format: "gif", LoopCount: -1}
+	pendingDelay := time.Duration(0)
+	hdr, complete, ok := walkGIF(data, func(b gifBlock) bool {
+		switch b.Kind {
+		case gifExtension:
+			block := data[b.Start:b.End]
+			switch b.Label {
+			case extGraphicControl:
+				if len(block) >= 8 && block[2] == 4 {
+					pendingDelay = time.Duration(int(block[4])|int(block[5])<<8) * 10 * time.Millisecond
+					if block[3]&0x01 != 0 {
+						info.Transparent = true
+					}
+				}
+			case extApplication:
+				if loop, ok := parseLoopExtension(block); ok {
+					info.LoopCount = loop
+				}
+			case extComment:
+				info.Comments = append(info.Comments, string(subBlockData(block[2:])))
+			}
+		case gifImageDescriptor:
+			info.Frames++
+			info.Delays = append(info.Delays, pendingDelay)
+			info.Duration += pendingDelay
+			pendingDelay = 0
+			if b.Fields&0x80 != 0 {
+				info.LocalPalettes++
+			}
+		}
+		return true
+	})
+	if !ok {
+		return inspectImage(data)
+	}
+	if info.Frames == 0 {
+		return nil, ErrNoFrames
+	}
+	info.Version = hdr.Version
+	info.Width = hdr.Width
+	info.Height = hdr.Height
+	info.Complete = complete
+	if hdr.Fields&0x80 != 0 {
+		info.GlobalPaletteSize = colorTableEntries(hdr.Fields)
+	}
+	return info, nil
+}
+
+// parseLoopExtension reads the NETSCAPE2.0 (or ANIMEXTS1.0) loop count from
+// an application extension block.
+func parseLoopExtension(block []byte) (int, bool) {
+	// 0x21 0xff 0x0b "NETSCAPE2.0" 0x03 0x01 lo hi 0x00
+	if len(block) < 19 || block[2] != 11 {
+		return 0, false
+	}
+	app := string(block[3:14])
+	if app != "NETSCAPE2.0" && app != "ANIMEXTS1.0" {
+		return 0, false
+	}
+	if block[14] != 3 || block[15] != 1 {
+		return 0, false
+	}
+	return int(block[16]) | int(block[17])<<8, true
+}
+
+func inspectImage(data []byte) (*Info, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 {
+		return nil, ErrInvalidSize
+	}
+	return &Info{
+		Format:    format,
+		Width:     cfg.Width,
+		Height:    cfg.Height,
+		Frames:    1,
+		LoopCount: -1,
+		Complete:  true,
+	}, nil
+}
@@ -0,0 +1,112 @@
+package gifdecode
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"testing"
+	"time"
+)
+
+func TestInspectGIF(t *testing.T) {
+	info, err := Inspect(makeTestGIF(3))
+	if err != nil {
+		t.Fatalf("inspect failed: %v", err)
+	}
+	if info.Format != "gif" || info.Version != "GIF89a" {
+		t.Fatalf("unexpected format %q %q", info.Format, info.Version)
+	}
+	if info.Width != 2 || info.Height != 2 || info.Frames != 3 {
+		t.Fatalf("unexpected geometry: %+v", info)
+	}
+	want := []time.Duration{50 * time.Millisecond, 70 * time.Millisecond, 90 * time.Millisecond}
+	if len(info.Delays) != len(want) {
+		t.Fatalf("unexpected delays: %v", info.Delays)
+	}
+	for i := range want {
+		if info.Delays[i] != want[i] {
+			t.Fatalf("delay %d: got %v want %v", i, info.Delays[i], want[i])
+		}
+	}
+	if info.Duration != 210*time.Millisecond {
+		t.Fatalf("unexpected duration %v", info.Duration)
+	}
+	if info.LoopCount != 0 {
+		t.Fatalf("expected loop forever, got %d", info.LoopCount)
+	}
+	if info.GlobalPaletteSize != 2 || info.LocalPalettes != 0 {
+		t.Fatalf("unexpected palette info: %+v", info)
+	}
+	if info.Transparent || !info.Complete {
+		t.Fatalf("unexpected flags: %+v", info)
+	}
+}
+
+func TestInspectLoopAndTransparency(t *testing.T) {
+	pal := color.Palette{color.Transparent, color.White}
+	frame1 := image.NewPaletted(image.Rect(0, 0, 2, 2), pal)
+	frame2 := image.NewPaletted(image.Rect(0, 0, 2, 2), pal)
+	g := &gif.GIF{
+		Image:     []*image.Paletted{frame1, frame2},
+		Delay:     []int{4, 4},
+		LoopCount: 3,
+		Config:    image.Config{Width: 2, Height: 2, ColorModel: pal},
+	}
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, g); err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	info, err := Inspect(buf.Bytes())
+	if err != nil {
+		t.Fatalf("inspect failed: %v", err)
+	}
+	if info.LoopCount != 3 {
+		t.Fatalf("expected loop count 3, got %d", info.LoopCount)
+	}
+	if !info.Transparent {
+		t.Fatalf("expected transparency")
+	}
+}
+
+func TestInspectFixtures(t *testing.T) {
+	for _, name := range fuzzFixtures {
+		data := readFixture(t, name)
+		info, err := Inspect(data)
+		if err != nil {
+			t.Fatalf("%s: inspect failed: %v", name, err)
+		}
+		g, err := gif.DecodeAll(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("%s: decode failed: %v", name, err)
+		}
+		if info.Frames != len(g.Image) || info.LoopCount != g.LoopCount {
+			t.Fatalf("%s: got frames=%d loop=%d, want frames=%d loop=%d", name, info.Frames, info.LoopCount, len(g.Image), g.LoopCount)
+		}
+		if info.Width != g.Config.Width || info.Height != g.Config.Height {
+			t.Fatalf("%s: unexpected size %dx%d", name, info.Width, info.Height)
+		}
+	}
+}
+
+func TestInspectNonGIF(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 3, 4))); err != nil {
+		t.Fatalf("png encode failed: %v", err)
+	}
+	info, err := Inspect(buf.Bytes())
+	if err != nil {
+		t.Fatalf("inspect failed: %v", err)
+	}
+	if info.Format != "png" || info.Width != 3 || info.Height != 4 || info.Frames != 1 {
+		t.Fatalf("unexpected info: %+v", info)
+	}
+	if _, err := Inspect([]byte("nope")); err == nil {
+		t.Fatalf("expected error for invalid data")
+	}
+	if _, err := Inspect([]byte("GIF89a\x01\x00\x01\x00\x00\x00\x00;")); !errors.Is(err, ErrNoFrames) {
+		t.Fatalf("expected ErrNoFrames, got %v", err)
+	}
+}
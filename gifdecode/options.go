@@ -3,9 +3,11 @@ package gifdecode
 import "time"
 
 const (
-	defaultMaxFrames = 60
-	defaultMaxPixels = 40_000_000
-	defaultMaxBytes  = int64(20 << 20)
+	defaultMaxFrames      = 60
+	defaultMaxPixels      = 40_000_000
+	defaultMaxBytes       = int64(20 << 20)
+	defaultMaxTotalPixels = int64(400_000_000)
+	defaultMaxOutputBytes = int64(256 << 20)
 )
 
 const (
@@ -14,14 +16,20 @@ const (
 	maxDelay     = 1 * time.Second
 )
 
+// Options bounds the work Decode is willing to do. Zero values pick the
+// defaults; negative limits disable the corresponding check.
 type Options struct {
-	MaxFrames    int
-	MaxPixels    int
-	MaxBytes     int64
-	DefaultDelay time.Duration
-	MinDelay     time.Duration
-	MaxDelay     time.Duration
-	StrictGIF    bool
+	MaxFrames int
+	MaxPixels int
+	MaxBytes  int64
+	// MaxTotalPixels caps frames × canvas area across the decoded animation.
+	MaxTotalPixels int64
+	// MaxOutputBytes caps the summed size of all encoded PNG frames.
+	MaxOutputBytes int64
+	DefaultDelay   time.Duration
+	MinDelay       time.Duration
+	MaxDelay       time.Duration
+	StrictGIF      bool
 }
 
 func (o Options) withDefaults() Options {
@@ -34,6 +42,12 @@ func (o Options) withDefaults() Options {
 	if o.MaxBytes == 0 {
 		o.MaxBytes = defaultMaxBytes
 	}
+	if o.MaxTotalPixels == 0 {
+		o.MaxTotalPixels = defaultMaxTotalPixels
+	}
+	if o.MaxOutputBytes == 0 {
+		o.MaxOutputBytes = defaultMaxOutputBytes
+	}
 	if o.DefaultDelay == 0 {
 		o.DefaultDelay = defaultDelay
 	}
@@ -51,11 +65,13 @@ func (o Options) withDefaults() Options {
 
 func DefaultOptions() Options {
 	return Options{
-		MaxFrames:    defaultMaxFrames,
-		MaxPixels:    defaultMaxPixels,
-		MaxBytes:     defaultMaxBytes,
-		DefaultDelay: defaultDelay,
-		MinDelay:     minDelay,
-		MaxDelay:     maxDelay,
+		MaxFrames:      defaultMaxFrames,
+		MaxPixels:      defaultMaxPixels,
+		MaxBytes:       defaultMaxBytes,
+		MaxTotalPixels: defaultMaxTotalPixels,
+		MaxOutputBytes: defaultMaxOutputBytes,
+		DefaultDelay:   defaultDelay,
+		MinDelay:       minDelay,
+		MaxDelay:       maxDelay,
 	}
 }
@@ -0,0 +1,158 @@
+package gifdecode
+
+const (
+	gifExtension       = 0x21
+	gifImageDescriptor = 0x2c
+	gifTrailer         = 0x3b
+)
+
+const (
+	extGraphicControl = 0xf9
+	extComment        = 0xfe
+	extApplication    = 0xff
+)
+
+// gifHeader is the GIF header plus logical screen descriptor.
+type gifHeader struct {
+	Version string
+	Width   int
+	Height  int
+	Fields  byte
+}
+
+// gifBlock is one extension or image block; data[Start:End] covers the whole
+// block including its introducer and sub-blocks.
+type gifBlock struct {
+	Kind   byte
+	Label  byte
+	Fields byte
+	Start  int
+	End    int
+}
+
+// walkGIF walks the GIF block structure without decompressing pixel data,
+// calling fn for each extension and image block until fn returns false.
+// complete reports whether the walk reached the trailer; ok is false when
+// the header itself can't be read.
+func walkGIF(data []byte, fn func(b gifBlock) bool) (hdr gifHeader, complete, ok bool) {
+	if len(data) < 13 {
+		return gifHeader{}, false, false
+	}
+	hdr.Version = string(data[:6])
+	if hdr.Version != "GIF87a" && hdr.Version != "GIF89a" {
+		return gifHeader{}, false, false
+	}
+	hdr.Width = int(data[6]) | int(data[7])<<8
+	hdr.Height = int(data[8]) | int(data[9])<<8
+	hdr.Fields = data[10]
+	pos := 13
+	if hdr.Fields&0x80 != 0 {
+		pos += colorTableSize(hdr.Fields)
+	}
+
+	for pos < len(data) {
+		switch data[pos] {
+		case gifExtension:
+			if pos+1 >= len(data) {
+				return hdr, false, true
+			}
+			end, ok := skipSubBlocks(data, pos+2)
+			if !ok {
+				return hdr, false, true
+			}
+			if !fn(gifBlock{Kind: gifExtension, Label: data[pos+1], Start: pos, End: end}) {
+				return hdr, false, true
+			}
+			pos = end
+		case gifImageDescriptor:
+			if pos+10 > len(data) {
+				return hdr, false, true
+			}
+			fields := data[pos+9]
+			end := pos + 10
+			if fields&0x80 != 0 {
+				end += colorTableSize(fields)
+			}
+			// LZW minimum code size precedes the image data sub-blocks.
+			end, ok := skipSubBlocks(data, end+1)
+			if !ok {
+				return hdr, false, true
+			}
+			if !fn(gifBlock{Kind: gifImageDescriptor, Fields: fields, Start: pos, End: end}) {
+				return hdr, false, true
+			}
+			pos = end
+		case gifTrailer:
+			return hdr, true, true
+		default:
+			return hdr, false, true
+		}
+	}
+	return hdr, false, true
+}
+
+// gifLayout is what scanGIF learns from the block structure.
+type gifLayout struct {
+	Width     int
+	Height    int
+	Frames    int
+	End       int
+	Truncated bool
+}
+
+// scanGIF counts frames, stopping after maxFrames (if > 0). It lets
+// decodeBytes reject oversized inputs and trim extra frames before image/gif
+// allocates a paletted image for every frame. ok is false when the data isn't
+// a GIF or is malformed before the first frame; callers then leave error
+// reporting to image/gif.
+func scanGIF(data []byte, maxFrames int) (layout gifLayout, ok bool) {
+	hdr, _, ok := walkGIF(data, func(b gifBlock) bool {
+		if b.Kind != gifImageDescriptor {
+			return true
+		}
+		layout.Frames++
+		layout.End = b.End
+		if maxFrames > 0 && layout.Frames >= maxFrames {
+			layout.Truncated = b.End < len(data) && data[b.End] != gifTrailer
+			return false
+		}
+		return true
+	})
+	if !ok || layout.Frames == 0 {
+		return gifLayout{}, false
+	}
+	layout.Width = hdr.Width
+	layout.Height = hdr.Height
+	return layout, true
+}
+
+func skipSubBlocks(data []byte, pos int) (int, bool) {
+	for {
+		if pos >= len(data) {
+			return pos, false
+		}
+		size := int(data[pos])
+		pos++
+		if size == 0 {
+			return pos, true
+		}
+		pos += size
+	}
+}
+
+func colorTableSize(fields byte) int {
+	return 3 * colorTableEntries(fields)
+}
+
+func colorTableEntries(fields byte) int {
+	return 1 << (1 + uint(fields&0x07))
+}
+
+// truncatedGIF returns data cut after the last kept frame, with a trailer
+// appended so image/gif sees a complete stream.
+func truncatedGIF(data []byte, layout gifLayout) []byte {
+	out := make([]byte, layout.End+1)
+	copy(out, data[:layout.End])
+	out[layout.End] = gifTrailer
+	return out
+}
@@ -2,16 +2,22 @@ package app
 
 import (
 	"bufio"
+	"encoding/csv"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"image"
 	"io"
 	"os"
+	"strconv"
 	"strings"
+	"text/template"
 	"time"
 
 	"github.com/alecthomas/kong"
+	"github.com/steipete/gifgrep/internal/config"
 	"github.com/steipete/gifgrep/internal/download"
+	"github.com/steipete/gifgrep/internal/favorites"
 	"github.com/steipete/gifgrep/internal/model"
 	"github.com/steipete/gifgrep/internal/reveal"
 	"github.com/steipete/gifgrep/internal/search"
@@ -23,10 +29,20 @@ import (
 type CLI struct {
 	Globals Globals `embed:""`
 
-	Search SearchCmd `cmd:"" default:"withargs" help:"Search and print GIF URLs."`
-	TUI    TUICmd    `cmd:"" help:"Interactive browser with inline preview."`
-	Still  StillCmd  `cmd:"" help:"Extract a single frame as PNG."`
-	Sheet  SheetCmd  `cmd:"" help:"Generate a sheet PNG of sampled frames."`
+	Search  SearchCmd  `cmd:"" default:"withargs" help:"Search and print GIF URLs."`
+	TUI     TUICmd     `cmd:"" help:"Interactive browser with inline preview."`
+	Still   StillCmd   `cmd:"" help:"Extract a single frame as PNG."`
+	Sheet   SheetCmd   `cmd:"" help:"Generate a sheet PNG of sampled frames."`
+	Frames  FramesCmd  `cmd:"" help:"Export frames as a numbered PNG sequence."`
+	Edit    EditCmd    `cmd:"" help:"Trim, crop and resize a GIF and re-encode it."`
+	Info    InfoCmd    `cmd:"" help:"Print GIF dimensions, frames, timing and loop metadata."`
+	Fav     FavCmd     `cmd:"" help:"Manage local GIF collections (favorites)."`
+	History HistoryCmd `cmd:"" help:"List or clear past searches."`
+	Config  ConfigCmd  `cmd:"" help:"Show or edit the config file (~/.config/gifgrep/config.toml)."`
+
+	config    *config.File
+	configErr error
+	settings  config.Settings
 }
 
 type Globals struct {
@@ -35,6 +51,7 @@ type Globals struct {
 	Reveal  bool             `help:"Reveal output file in file manager."`
 	Verbose int              `help:"Verbose stderr logs (-vv for more)." short:"v" type:"counter"`
 	Quiet   bool             `help:"Suppress non-essential stderr output." short:"q"`
+	Profile string           `help:"Config profile to use (from config.toml)." env:"GIFGREP_PROFILE"`
 	Version kong.VersionFlag `help:"Show version."`
 }
 
@@ -54,11 +71,16 @@ func (g Globals) toOptions() model.Options {
 type SearchCmd struct {
 	Source   string `help:"Source to search." enum:"auto,tenor,giphy,heypster" default:"auto"`
 	Max      int    `help:"Max results to fetch." name:"max" short:"m" default:"20"`
+	Rating   string `help:"Content rating (auto: provider default)." enum:"auto,g,pg,pg-13,r" default:"auto"`
 	JSON     bool   `help:"Emit JSON array of results."`
 	Number   bool   `help:"Prefix lines with 1-based index." short:"n"`
-	Download bool   `help:"Download results to ~/Downloads."`
-	Format   string `help:"Output format." enum:"auto,plain,tsv,md,url,comment,json" default:"auto"`
-	Thumbs   string `help:"Inline thumbnails (Kitty protocol / iTerm2 images; TTY only)." enum:"auto,always,never" default:"auto"`
+	Download bool   `help:"Download results to ~/Downloads (or --out-dir)."`
+	Jobs     int    `help:"Parallel downloads for --download." short:"j" default:"4"`
+	Copy     string `help:"Copy to the clipboard: url (every result URL) or file (the first GIF)." enum:",url,file" default:"" placeholder:"url|file"`
+	downloadFlags
+	Format   string `help:"Output format." enum:"auto,plain,tsv,md,url,comment,json,ndjson,csv" default:"auto"`
+	Thumbs   string `help:"Inline thumbnails (Kitty / iTerm2 / Sixel; always falls back to block art; TTY only)." enum:"auto,always,never" default:"auto"`
+	Template string `help:"Go text/template per result (.Title .URL .PreviewURL .PageURL .ID .Tags .Width .Height .Index .Provider) or a named template: slack, html, org, csv." short:"t"`
 
 	Query []string `arg:"" name:"query" help:"Search query."`
 }
@@ -74,23 +96,67 @@ func (c *SearchCmd) Run(ctx *kong.Context, cli *CLI) error {
 	opts.Number = c.Number
 	opts.Limit = c.Max
 	opts.Source = c.Source
+	opts.Rating = c.Rating
 	opts.Format = c.Format
 	opts.Thumbs = c.Thumbs
+	opts.Template = cli.settings.LookupTemplate(c.Template)
 	opts.Download = c.Download
+	opts.DownloadJobs = c.Jobs
+	opts.Copy = c.Copy
+	opts.HistoryPath = historyPath()
+	if err := c.downloadFlags.apply(&opts); err != nil {
+		return err
+	}
 	return runSearch(ctx.Stdout, ctx.Stderr, opts, query)
 }
 
+// downloadFlags are shared by search (--download) and the TUI (d).
+type downloadFlags struct {
+	OutDir    string `help:"Download directory (default $XDG_DOWNLOAD_DIR or ~/Downloads)." name:"out-dir" type:"path"`
+	Filename  string `help:"Filename template: {source} {id} {title} {ext} {width} {height}; '/' makes subdirectories." placeholder:"TEMPLATE"`
+	Collision string `help:"When the file exists: suffix (name-1.gif), overwrite or skip." enum:"suffix,overwrite,skip" default:"suffix"`
+	Dedup     string `help:"Check the download directory for the same content (indexed in .gifgrep-index.json): off, skip (keep the existing file) or link." enum:"off,skip,link" default:"off"`
+	Metadata  bool   `help:"Embed provenance (source, id, title, tags, URL) as a GIF comment and the user.xdg.origin.url attribute." negatable:""`
+}
+
+func (f downloadFlags) apply(opts *model.Options) error {
+	if f.Filename != "" {
+		if err := download.ValidateName(f.Filename); err != nil {
+			return err
+		}
+	}
+	opts.DownloadDir = f.OutDir
+	opts.DownloadName = f.Filename
+	opts.DownloadCollision = f.Collision
+	opts.DownloadDedup = f.Dedup
+	opts.DownloadMetadata = f.Metadata
+	return nil
+}
+
 type TUICmd struct {
 	Source string `help:"Source to search." enum:"auto,tenor,giphy,heypster" default:"auto"`
 	Max    int    `help:"Max results to fetch." name:"max" short:"m" default:"20"`
+	Rating string `help:"Content rating (auto: provider default)." enum:"auto,g,pg,pg-13,r" default:"auto"`
+	downloadFlags
+	Collection string `help:"Collection that s stars into." default:"favorites"`
 
-	Query []string `arg:"" optional:"" name:"query" help:"Initial query."`
+	Query []string `arg:"" optional:"" name:"query" help:"Initial query (@name opens a collection if one has that name; @@ searches for a literal @)."`
 }
 
 func (c *TUICmd) Run(_ *kong.Context, cli *CLI) error {
 	opts := cli.Globals.toOptions()
 	opts.Limit = c.Max
 	opts.Source = c.Source
+	opts.Rating = c.Rating
+	opts.Keys = cli.settings.Keys
+	opts.Collection = c.Collection
+	opts.HistoryPath = historyPath()
+	if err := c.downloadFlags.apply(&opts); err != nil {
+		return err
+	}
+	if err := favorites.ValidateName(opts.Collection); err != nil {
+		return err
+	}
 
 	query := strings.TrimSpace(strings.Join(c.Query, " "))
 	return tui.Run(opts, query)
@@ -149,35 +215,128 @@ func (c *SheetCmd) Run(_ *kong.Context, cli *CLI) error {
 	return nil
 }
 
+type FramesCmd struct {
+	GIF    string        `arg:"" name:"gif" help:"GIF path or URL."`
+	Every  int           `help:"Export every Nth frame." name:"every" default:"1"`
+	From   DurationValue `help:"Start timestamp (e.g. 0.5s)." name:"from" default:"0"`
+	To     DurationValue `help:"End timestamp, inclusive (0 = last frame)." name:"to" default:"0"`
+	Output string        `help:"Output directory." name:"output" short:"o" default:"frames"`
+}
+
+func (c *FramesCmd) Run(ctx *kong.Context, cli *CLI) error {
+	opts := cli.Globals.toOptions()
+	opts.GifInput = c.GIF
+	opts.FramesEvery = c.Every
+	opts.FramesFrom = time.Duration(c.From)
+	opts.FramesTo = time.Duration(c.To)
+	opts.OutPath = c.Output
+	manifestPath, err := runFrames(ctx.Stderr, opts)
+	if err != nil {
+		return err
+	}
+	if opts.Reveal {
+		return reveal.Reveal(manifestPath)
+	}
+	return nil
+}
+
+type EditCmd struct {
+	GIF      string        `arg:"" name:"gif" help:"GIF path or URL."`
+	Start    DurationValue `help:"Start timestamp (e.g. 0.5s)." name:"start" default:"0"`
+	End      DurationValue `help:"End timestamp, inclusive (0 = last frame)." name:"end" default:"0"`
+	Crop     CropValue     `help:"Crop rectangle WxH+X+Y (before resizing)." name:"crop"`
+	Width    int           `help:"Output width in px (0 = keep; height follows aspect)." name:"width" default:"0"`
+	Height   int           `help:"Output height in px (0 = keep; width follows aspect)." name:"height" default:"0"`
+	Colors   int           `help:"Max palette colors (2-256)." name:"colors" default:"256"`
+	NoDither bool          `help:"Map colors without Floyd-Steinberg dithering." name:"no-dither"`
+	Output   string        `help:"Output path or '-' for stdout." name:"output" short:"o" default:"edited.gif"`
+}
+
+func (c *EditCmd) Run(ctx *kong.Context, cli *CLI) error {
+	opts := cli.Globals.toOptions()
+	opts.GifInput = c.GIF
+	opts.EditStart = time.Duration(c.Start)
+	opts.EditEnd = time.Duration(c.End)
+	opts.EditCrop = image.Rectangle(c.Crop)
+	opts.EditWidth = c.Width
+	opts.EditHeight = c.Height
+	opts.EditColors = c.Colors
+	opts.EditNoDither = c.NoDither
+	opts.OutPath = c.Output
+	if err := runEdit(ctx.Stderr, opts); err != nil {
+		return err
+	}
+	if opts.Reveal && opts.OutPath != "-" {
+		return reveal.Reveal(resolveEditOutPath(opts))
+	}
+	return nil
+}
+
+type InfoCmd struct {
+	GIF  string `arg:"" name:"gif" help:"GIF path or URL."`
+	JSON bool   `help:"Emit JSON."`
+}
+
+func (c *InfoCmd) Run(ctx *kong.Context, cli *CLI) error {
+	opts := cli.Globals.toOptions()
+	opts.GifInput = c.GIF
+	opts.JSON = c.JSON
+	return runInfo(ctx.Stdout, opts)
+}
+
 func runSearch(stdout io.Writer, stderr io.Writer, opts model.Options, query string) error {
 	if strings.TrimSpace(query) == "" {
 		return errors.New("missing query")
 	}
+	var tmpl *template.Template
+	if opts.Template != "" {
+		var err error
+		if tmpl, err = parseResultTemplate(opts.Template); err != nil {
+			return err
+		}
+	}
 	logSearchConfig(stderr, opts)
 
 	results, err := search.Search(query, opts)
 	if err != nil {
 		return err
 	}
+	if isTerminalWriter(stdout) {
+		recordHistory(stderr, opts, query, len(results))
+	}
 
-	if err := downloadSearchResults(results, opts, stderr); err != nil {
+	// Failed downloads are summarized on stderr; the results still print.
+	downloadErr := downloadSearchResults(results, opts, stderr)
+	if err := writeResults(stdout, opts, tmpl, results); err != nil {
 		return err
 	}
+	if err := copySearchResults(results, opts, stdout, stderr); err != nil {
+		return err
+	}
+	return downloadErr
+}
 
-	format := resolveOutputFormat(opts, stdout)
+func writeResults(stdout io.Writer, opts model.Options, tmpl *template.Template, results []model.Result) error {
 	out := bufio.NewWriter(stdout)
 	defer func() { _ = out.Flush() }()
+	if tmpl != nil {
+		return writeTemplateResults(out, tmpl, results, search.ResolveSource(opts.Source))
+	}
+	format := resolveOutputFormat(opts, stdout)
 	if format == formatJSON {
-		enc := json.NewEncoder(out)
-		enc.SetIndent("", "  ")
-		return enc.Encode(results)
+		return writeJSONResults(out, results)
 	}
 
 	useColor := shouldUseColor(opts, stdout)
 	thumbs := thumbsProtocol(opts, stdout, format)
 	termCols := termColumns(stdout, thumbs)
+	cell := termCellSize(thumbs)
+	if thumbs != termcaps.InlineNone {
+		// Graphics escapes need a passthrough envelope inside tmux/screen.
+		out.Reset(termcaps.PassthroughWriter(stdout, termcaps.DetectMultiplexer(os.Getenv)))
+	}
 
-	writeSearchResults(out, opts, useColor, thumbs, results, termCols, format)
+	writeSearchResults(out, opts, useColor, thumbs, results, termCols, cell, format)
 	return nil
 }
 
@@ -191,20 +350,41 @@ func downloadSearchResults(results []model.Result, opts model.Options, stderr io
 	if !opts.Download {
 		return nil
 	}
+	items := make([]model.Result, 0, len(results))
+	for _, res := range results {
+		if res.URL != "" {
+			items = append(items, res)
+		}
+	}
+	if len(items) == 0 {
+		return nil
+	}
+
+	var meter *downloadMeter
+	var reporter download.Reporter
+	if !opts.Quiet {
+		meter = newDownloadMeter(stderr, len(items), isTerminalWriter(stderr), opts.Verbose > 0)
+		reporter = meter
+	}
+	outcomes := download.SaveAll(items, download.OptionsFrom(opts, search.ResolveSource(opts.Source)), opts.DownloadJobs, reporter)
+	if meter != nil {
+		meter.finish()
+	}
 
 	var lastSaved string
-	for _, res := range results {
-		if res.URL == "" {
+	failed := 0
+	for _, o := range outcomes {
+		if o.Err != nil {
+			failed++
 			continue
 		}
-		savedPath, err := download.ToDownloads(res)
-		if err != nil {
-			return err
-		}
-		lastSaved = savedPath
-		if opts.Verbose > 0 && !opts.Quiet {
-			_, _ = fmt.Fprintf(stderr, "saved %s\n", savedPath)
-		}
+		lastSaved = o.Saved.Path
+	}
+	if !opts.Quiet {
+		writeDownloadSummary(stderr, outcomes)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d downloads failed", failed, len(outcomes))
 	}
 	if opts.Reveal && lastSaved != "" {
 		return reveal.Reveal(lastSaved)
@@ -227,10 +407,22 @@ func termColumns(w io.Writer, thumbs termcaps.InlineProtocol) int {
 	return cols
 }
 
-func writeSearchResults(out *bufio.Writer, opts model.Options, useColor bool, thumbs termcaps.InlineProtocol, results []model.Result, termCols int, format outputFormat) {
+// detectCellSize is swapped out in tests, which must not probe a terminal.
+var detectCellSize = termcaps.DetectCellSize
+
+// termCellSize probes the cell size for thumbnails that are sized in pixels
+// or by aspect; iTerm2 scales images into cells itself.
+func termCellSize(thumbs termcaps.InlineProtocol) termcaps.CellSize {
+	if thumbs == termcaps.InlineNone || thumbs == termcaps.InlineIterm {
+		return termcaps.CellSize{}
+	}
+	return detectCellSize()
+}
+
+func writeSearchResults(out *bufio.Writer, opts model.Options, useColor bool, thumbs termcaps.InlineProtocol, results []model.Result, termCols int, cell termcaps.CellSize, format outputFormat) {
 	switch format {
 	case formatPlain:
-		renderPlain(out, opts, useColor, thumbs, results, termCols)
+		renderPlain(out, opts, useColor, thumbs, results, termCols, cell)
 		return
 	case formatURL:
 		for i, res := range results {
@@ -267,6 +459,38 @@ func writeSearchResults(out *bufio.Writer, opts model.Options, useColor bool, th
 	case formatJSON:
 		// handled by caller
 		return
+	case formatNDJSON:
+		enc := json.NewEncoder(out)
+		for _, res := range results {
+			_ = enc.Encode(res)
+			// One object per line, flushed as it is written.
+			_ = out.Flush()
+		}
+		return
+	case formatCSV:
+		w := csv.NewWriter(out)
+		header := []string{"id", "title", "url", "preview_url", "width", "height", "tags"}
+		if opts.Number {
+			header = append([]string{"index"}, header...)
+		}
+		_ = w.Write(header)
+		for i, res := range results {
+			record := []string{
+				res.ID,
+				normalizeTitle(res),
+				res.URL,
+				res.PreviewURL,
+				strconv.Itoa(res.Width),
+				strconv.Itoa(res.Height),
+				strings.Join(res.Tags, " "),
+			}
+			if opts.Number {
+				record = append([]string{strconv.Itoa(i + 1)}, record...)
+			}
+			_ = w.Write(record)
+		}
+		w.Flush()
+		return
 	case formatTSV, formatAuto:
 		fallthrough
 	default:
@@ -287,6 +511,12 @@ func writeSearchResults(out *bufio.Writer, opts model.Options, useColor bool, th
 	}
 }
 
+func writeJSONResults(out io.Writer, results []model.Result) error {
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(results)
+}
+
 func shouldUseColor(opts model.Options, w io.Writer) bool {
 	if opts.Color == "never" {
 		return false
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"io"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -65,3 +66,49 @@ func TestHelpOutput(t *testing.T) {
 		t.Fatalf("expected --no-color in help")
 	}
 }
+
+func TestRunSearchDownloadTemplateAndCollision(t *testing.T) {
+	gifData := testutil.MakeTestGIF()
+	dir := t.TempDir()
+	testutil.WithTransport(t, &testutil.FakeTransport{GIFData: gifData}, func() {
+		opts := model.Options{
+			Limit:             1,
+			Source:            "tenor",
+			Format:            "url",
+			Download:          true,
+			Verbose:           1,
+			DownloadDir:       dir,
+			DownloadName:      "{source}/{id}-{title}.{ext}",
+			DownloadCollision: "skip",
+		}
+		var stdout, stderr bytes.Buffer
+		if err := runSearch(&stdout, &stderr, opts, "cats"); err != nil {
+			t.Fatalf("runSearch failed: %v", err)
+		}
+		want := filepath.Join(dir, "tenor", "1-Cat_One.gif")
+		if !strings.Contains(stderr.String(), "saved "+want) {
+			t.Fatalf("expected saved %s, got %q", want, stderr.String())
+		}
+
+		stderr.Reset()
+		if err := runSearch(&stdout, &stderr, opts, "cats"); err != nil {
+			t.Fatalf("second runSearch failed: %v", err)
+		}
+		if !strings.Contains(stderr.String(), "exists "+want) {
+			t.Fatalf("expected skip, got %q", stderr.String())
+		}
+	})
+}
+
+func TestDownloadFlagsRejectBadTemplate(t *testing.T) {
+	var opts model.Options
+	if err := (downloadFlags{Filename: "{nope}"}).apply(&opts); err == nil {
+		t.Fatalf("expected template error")
+	}
+	if err := (downloadFlags{OutDir: "/x", Filename: "{id}", Collision: "skip"}).apply(&opts); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	if opts.DownloadDir != "/x" || opts.DownloadName != "{id}" || opts.DownloadCollision != "skip" {
+		t.Fatalf("unexpected options %+v", opts)
+	}
+}
@@ -0,0 +1,179 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/alecthomas/kong"
+	"github.com/steipete/gifgrep/internal/config"
+)
+
+type ConfigCmd struct {
+	Get  ConfigGetCmd  `cmd:"" help:"Print a config value (dotted key, e.g. profiles.work.source)."`
+	Set  ConfigSetCmd  `cmd:"" help:"Set a config value, keeping comments and other entries."`
+	Path ConfigPathCmd `cmd:"" help:"Print the config file path."`
+}
+
+type ConfigGetCmd struct {
+	Key string `arg:"" name:"key" help:"Dotted key."`
+}
+
+func (c *ConfigGetCmd) Run(ctx *kong.Context, cli *CLI) error {
+	if cli.configErr != nil {
+		return cli.configErr
+	}
+	v, ok := cli.config.Get(c.Key)
+	if !ok {
+		return fmt.Errorf("%s is not set in %s", c.Key, cli.config.Path)
+	}
+	_, err := fmt.Fprintln(ctx.Stdout, v)
+	return err
+}
+
+type ConfigSetCmd struct {
+	Key   string `arg:"" name:"key" help:"Dotted key."`
+	Value string `arg:"" name:"value" help:"Value."`
+}
+
+func (c *ConfigSetCmd) Run(_ *kong.Context, cli *CLI) error {
+	path, err := configPath()
+	if err != nil {
+		return err
+	}
+	return config.Set(path, c.Key, c.Value)
+}
+
+type ConfigPathCmd struct{}
+
+func (c *ConfigPathCmd) Run(ctx *kong.Context, _ *CLI) error {
+	path, err := configPath()
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(ctx.Stdout, path)
+	return err
+}
+
+var configPath = config.DefaultPath
+
+// loadConfig reads the config file before parsing; errors surface only for
+// commands that use the settings (see usesConfig), so `config set` and the
+// GIF tools still work with a broken file.
+func (c *CLI) loadConfig() {
+	path, err := configPath()
+	if err != nil {
+		c.configErr = err
+		return
+	}
+	c.config, c.configErr = config.Load(path)
+}
+
+// configError marks errors that come from the config file rather than the
+// command line, so they print without the usage text.
+type configError struct{ error }
+
+func (e configError) Unwrap() error { return e.error }
+
+// BeforeResolve selects the profile (--profile, $GIFGREP_PROFILE or the
+// file's default) so configResolver can fill unset flags from it.
+func (c *CLI) BeforeResolve(ctx *kong.Context) error {
+	if !usesConfig(ctx.Command()) {
+		return nil
+	}
+	if c.configErr != nil {
+		return configError{fmt.Errorf("config: %w", c.configErr)}
+	}
+	if c.config == nil {
+		return nil
+	}
+	name := ""
+	for _, f := range ctx.Flags() {
+		if f.Name == "profile" {
+			name, _ = ctx.FlagValue(f).(string)
+		}
+	}
+	settings, err := c.config.Resolve(name)
+	if err != nil {
+		return configError{fmt.Errorf("config: %w", err)}
+	}
+	c.settings = settings
+	applyConfigEnv(settings.Env)
+	return nil
+}
+
+// usesConfig reports whether a command reads config.toml: search and the TUI
+// take their defaults, keys and env from it, fav ls its templates. The GIF
+// tools (still, sheet, frames, edit, info) and the store commands do not.
+func usesConfig(command string) bool {
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return false
+	}
+	switch fields[0] {
+	case "search", "tui":
+		return true
+	case "fav":
+		return len(fields) > 1 && fields[1] == "ls"
+	}
+	return false
+}
+
+// configResolver supplies config defaults for flags not given on the
+// command line.
+func configResolver(cli *CLI) kong.ResolverFunc {
+	return func(_ *kong.Context, _ *kong.Path, flag *kong.Flag) (any, error) {
+		s := cli.settings
+		var v string
+		switch flag.Name {
+		case "source":
+			v = s.Source
+		case "max":
+			if s.Max > 0 {
+				v = strconv.Itoa(s.Max)
+			}
+		case "format":
+			v = s.Format
+		case "thumbs":
+			v = s.Thumbs
+		case "color":
+			v = s.Color
+		case "rating":
+			v = s.Rating
+		case "template":
+			v = s.Template
+		case "out-dir":
+			v = s.DownloadDir
+		case "filename":
+			v = s.Filename
+		case "collision":
+			v = s.Collision
+		case "dedup":
+			v = s.Dedup
+		case "metadata":
+			if s.Metadata {
+				v = "true"
+			}
+		}
+		if v == "" {
+			return nil, nil
+		}
+		return v, nil
+	}
+}
+
+// applyConfigEnv exports the config's [env] table; the real environment wins.
+func applyConfigEnv(env map[string]string) {
+	for k, v := range env {
+		if _, ok := os.LookupEnv(k); !ok {
+			_ = os.Setenv(k, v)
+		}
+	}
+}
+
+func isConfigError(err error) bool {
+	var cfgErr configError
+	return errors.As(err, &cfgErr)
+}
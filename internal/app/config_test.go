@@ -0,0 +1,198 @@
+package app
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alecthomas/kong"
+	"github.com/steipete/gifgrep/internal/config"
+)
+
+const testConfig = `profile = "work"
+max = 7
+color = "never"
+
+[templates]
+chat = "{{.Title}}: {{.URL}}"
+
+[env]
+GIFGREP_TEST_CONFIG_ENV = "from-config"
+
+[profiles.work]
+source = "tenor"
+rating = "pg"
+format = "md"
+download_dir = "/tmp/work-gifs"
+
+[profiles.work.keys]
+download = "D"
+
+[profiles.home]
+max = 3
+`
+
+func parseWithConfig(t *testing.T, body string, args ...string) (*CLI, error) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	t.Setenv("GIFGREP_CONFIG", path)
+	t.Setenv("GIFGREP_PROFILE", "")
+	_ = os.Unsetenv("GIFGREP_PROFILE")
+
+	cli := &CLI{}
+	cli.loadConfig()
+	parser, err := newParser(cli)
+	if err != nil {
+		t.Fatalf("newParser: %v", err)
+	}
+	_, err = parser.Parse(args)
+	return cli, err
+}
+
+func TestConfigProvidesFlagDefaults(t *testing.T) {
+	t.Setenv("GIFGREP_TEST_CONFIG_ENV", "")
+	_ = os.Unsetenv("GIFGREP_TEST_CONFIG_ENV")
+
+	cli, err := parseWithConfig(t, testConfig, "search", "cats")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	s := cli.Search
+	if s.Max != 7 || s.Source != "tenor" || s.Rating != "pg" || s.Format != "md" || cli.Globals.Color != "never" {
+		t.Fatalf("config defaults not applied: %+v %+v", s, cli.Globals)
+	}
+	if cli.settings.DownloadDir != "/tmp/work-gifs" || cli.settings.Keys["download"] != "D" {
+		t.Fatalf("settings not resolved: %+v", cli.settings)
+	}
+	if got := os.Getenv("GIFGREP_TEST_CONFIG_ENV"); got != "from-config" {
+		t.Fatalf("config env not exported: %q", got)
+	}
+	if got := cli.settings.LookupTemplate("chat"); got != "{{.Title}}: {{.URL}}" {
+		t.Fatalf("named template: %q", got)
+	}
+}
+
+func TestConfigFlagsAndProfileOverride(t *testing.T) {
+	t.Setenv("GIFGREP_TEST_CONFIG_ENV", "from-env")
+
+	cli, err := parseWithConfig(t, testConfig, "search", "--profile", "home", "--max", "2", "--source", "giphy", "cats")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if cli.Search.Max != 2 || cli.Search.Source != "giphy" {
+		t.Fatalf("flags should win: %+v", cli.Search)
+	}
+	if cli.Search.Format != "auto" || cli.Search.Rating != "auto" {
+		t.Fatalf("home profile should not inherit work settings: %+v", cli.Search)
+	}
+	if got := os.Getenv("GIFGREP_TEST_CONFIG_ENV"); got != "from-env" {
+		t.Fatalf("real environment should win: %q", got)
+	}
+
+	cli, err = parseWithConfig(t, testConfig, "search", "--profile", "home", "cats")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if cli.Search.Max != 3 {
+		t.Fatalf("profile max: %d", cli.Search.Max)
+	}
+}
+
+func TestConfigErrors(t *testing.T) {
+	_, err := parseWithConfig(t, testConfig, "search", "--profile", "nope", "cats")
+	if err == nil || !isConfigError(err) || !strings.Contains(err.Error(), `unknown profile "nope"`) {
+		t.Fatalf("expected unknown profile config error, got %v", err)
+	}
+
+	_, err = parseWithConfig(t, "max = \"ten\"\n", "search", "cats")
+	if err == nil || !isConfigError(err) {
+		t.Fatalf("expected config error, got %v", err)
+	}
+
+	// config subcommands still work with a broken file.
+	if _, err := parseWithConfig(t, "max = \"ten\"\n", "config", "path"); err != nil {
+		t.Fatalf("config path should ignore config errors: %v", err)
+	}
+	// So do commands that never read the settings.
+	for _, args := range [][]string{{"info", "a.gif"}, {"still", "a.gif", "--at", "1s"}, {"history"}, {"fav", "add", "https://x.test/a.gif"}} {
+		if _, err := parseWithConfig(t, "max = \"ten\"\n", args...); err != nil {
+			t.Fatalf("%v should ignore config errors: %v", args, err)
+		}
+	}
+	if _, err := parseWithConfig(t, "max = \"ten\"\n", "fav", "ls"); err == nil || !isConfigError(err) {
+		t.Fatalf("fav ls reads templates from the config; expected config error, got %v", err)
+	}
+}
+
+func TestConfigCommands(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gifgrep", "config.toml")
+	t.Setenv("GIFGREP_CONFIG", path)
+
+	stdout := captureStdout(t, func() {
+		if code := Run([]string{"config", "path"}); code != 0 {
+			t.Fatalf("config path exit %d", code)
+		}
+	})
+	if strings.TrimSpace(stdout) != path {
+		t.Fatalf("config path printed %q", stdout)
+	}
+
+	if code := Run([]string{"config", "set", "profiles.work.source", "giphy"}); code != 0 {
+		t.Fatalf("config set exit %d", code)
+	}
+	if code := Run([]string{"config", "set", "bogus", "1"}); code != 1 {
+		t.Fatalf("expected config set to reject unknown keys")
+	}
+	stdout = captureStdout(t, func() {
+		if code := Run([]string{"config", "get", "profiles.work.source"}); code != 0 {
+			t.Fatalf("config get exit %d", code)
+		}
+	})
+	if stdout != "giphy\n" {
+		t.Fatalf("config get printed %q", stdout)
+	}
+}
+
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	orig := os.Stdout
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("pipe: %v", err)
+	}
+	os.Stdout = w
+	done := make(chan string)
+	go func() {
+		var buf bytes.Buffer
+		_, _ = buf.ReadFrom(r)
+		done <- buf.String()
+	}()
+	defer func() { os.Stdout = orig }()
+	fn()
+	_ = w.Close()
+	return <-done
+}
+
+func TestConfigChoicesMatchFlagEnums(t *testing.T) {
+	parser, err := newParser(&CLI{})
+	if err != nil {
+		t.Fatalf("parser: %v", err)
+	}
+	var walk func(node *kong.Node)
+	walk = func(node *kong.Node) {
+		for _, f := range node.Flags {
+			if choices := config.Choices(f.Name); choices != nil && strings.Join(choices, ",") != f.Enum {
+				t.Errorf("%s --%s: flag enum %q, config choices %v", node.Path(), f.Name, f.Enum, choices)
+			}
+		}
+		for _, child := range node.Children {
+			walk(child)
+		}
+	}
+	walk(parser.Model.Node)
+}
@@ -0,0 +1,84 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/steipete/gifgrep/internal/clipboard"
+	"github.com/steipete/gifgrep/internal/download"
+	"github.com/steipete/gifgrep/internal/model"
+	"github.com/steipete/gifgrep/internal/search"
+)
+
+var (
+	copyText = clipboard.CopyText
+	copyFile = clipboard.CopyFile
+)
+
+// copySearchResults handles --copy: every result URL, one per line, or the
+// first result's GIF (fetched into a temp dir) as image data.
+func copySearchResults(results []model.Result, opts model.Options, stdout, stderr io.Writer) error {
+	if opts.Copy == "" {
+		return nil
+	}
+	var withURL []model.Result
+	for _, res := range results {
+		if res.URL != "" {
+			withURL = append(withURL, res)
+		}
+	}
+	if len(withURL) == 0 {
+		return errors.New("copy: no result has a URL")
+	}
+
+	var msg string
+	switch opts.Copy {
+	case "url":
+		urls := make([]string, len(withURL))
+		for i, res := range withURL {
+			urls[i] = res.URL
+		}
+		if err := copyText(terminalWriter(stdout, stderr), strings.Join(urls, "\n")); err != nil {
+			return fmt.Errorf("copy: %w", err)
+		}
+		msg = "copied 1 URL"
+		if len(urls) > 1 {
+			msg = fmt.Sprintf("copied %d URLs", len(urls))
+		}
+	case "file":
+		saved, err := download.Save(withURL[0], download.Options{
+			Dir:       filepath.Join(os.TempDir(), "gifgrep-clipboard"),
+			Collision: download.CollisionOverwrite,
+			Dedup:     download.DedupOff,
+			Source:    search.ResolveSource(opts.Source),
+		})
+		if err != nil {
+			return fmt.Errorf("copy: %w", err)
+		}
+		if err := copyFile(saved.Path); err != nil {
+			return fmt.Errorf("copy: %w", err)
+		}
+		msg = "copied " + normalizeTitle(withURL[0])
+	default:
+		return fmt.Errorf("unknown --copy %q (use url or file)", opts.Copy)
+	}
+	if !opts.Quiet {
+		_, _ = fmt.Fprintln(stderr, msg)
+	}
+	return nil
+}
+
+// terminalWriter picks a terminal for the OSC 52 sequence; stdout is often
+// piped while stderr is not.
+func terminalWriter(stdout, stderr io.Writer) io.Writer {
+	for _, w := range []io.Writer{stdout, stderr} {
+		if isTerminalWriter(w) {
+			return w
+		}
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package app
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/steipete/gifgrep/internal/model"
+)
+
+func stubClipboard(t *testing.T) (text *string, file *string) {
+	t.Helper()
+	prevText, prevFile := copyText, copyFile
+	text, file = new(string), new(string)
+	copyText = func(_ io.Writer, s string) error {
+		*text = s
+		return nil
+	}
+	copyFile = func(path string) error {
+		data, err := os.ReadFile(path)
+		*file = string(data)
+		return err
+	}
+	t.Cleanup(func() { copyText, copyFile = prevText, prevFile })
+	return text, file
+}
+
+func TestCopySearchResultsURLs(t *testing.T) {
+	text, _ := stubClipboard(t)
+	results := []model.Result{{URL: "https://x.test/a.gif"}, {Title: "no url"}, {URL: "https://x.test/b.gif"}}
+	var stderr bytes.Buffer
+	if err := copySearchResults(results, model.Options{Copy: "url"}, io.Discard, &stderr); err != nil {
+		t.Fatalf("copy: %v", err)
+	}
+	if *text != "https://x.test/a.gif\nhttps://x.test/b.gif" {
+		t.Fatalf("copied %q", *text)
+	}
+	if stderr.String() != "copied 2 URLs\n" {
+		t.Fatalf("stderr = %q", stderr.String())
+	}
+
+	if err := copySearchResults([]model.Result{{Title: "x"}}, model.Options{Copy: "url"}, io.Discard, io.Discard); err == nil {
+		t.Fatalf("expected an error without URLs")
+	}
+}
+
+func TestCopySearchResultsFile(t *testing.T) {
+	_, file := stubClipboard(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("GIF89a-first"))
+	}))
+	t.Cleanup(srv.Close)
+	t.Setenv("TMPDIR", t.TempDir())
+
+	results := []model.Result{{ID: "1", Title: "First", URL: srv.URL + "/1.gif"}, {ID: "2", URL: srv.URL + "/2.gif"}}
+	var stderr bytes.Buffer
+	if err := copySearchResults(results, model.Options{Copy: "file"}, io.Discard, &stderr); err != nil {
+		t.Fatalf("copy: %v", err)
+	}
+	if *file != "GIF89a-first" || stderr.String() != "copied First\n" {
+		t.Fatalf("file %q, stderr %q", *file, stderr.String())
+	}
+}
+
+func TestSearchCopyFlagValidates(t *testing.T) {
+	cli := &CLI{}
+	parser, err := newParser(cli)
+	if err != nil {
+		t.Fatalf("parser: %v", err)
+	}
+	if _, err := parser.Parse([]string{"search", "--copy", "clipboard", "cats"}); err == nil {
+		t.Fatalf("expected enum error")
+	}
+	if _, err := parser.Parse([]string{"search", "--copy", "url", "cats"}); err != nil || cli.Search.Copy != "url" {
+		t.Fatalf("parse: %v %q", err, cli.Search.Copy)
+	}
+}
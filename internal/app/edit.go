@@ -0,0 +1,76 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/steipete/gifgrep/gifdecode"
+	"github.com/steipete/gifgrep/internal/gifedit"
+	"github.com/steipete/gifgrep/internal/model"
+)
+
+// maxGIFDelay is the longest delay a GIF can store (65535 centiseconds).
+const maxGIFDelay = 65535 * 10 * time.Millisecond
+
+func runEdit(stderr io.Writer, opts model.Options) error {
+	if opts.GifInput == "" {
+		return errors.New("missing GIF input")
+	}
+	if opts.EditEnd > 0 && opts.EditEnd < opts.EditStart {
+		return errors.New("bad args: --end must be >= --start")
+	}
+	if opts.EditWidth < 0 || opts.EditHeight < 0 {
+		return errors.New("bad args: --width/--height must be >= 0")
+	}
+	if opts.EditColors != 0 && (opts.EditColors < 2 || opts.EditColors > 256) {
+		return errors.New("bad args: --colors must be between 2 and 256")
+	}
+
+	data, err := readInput(opts.GifInput)
+	if err != nil {
+		return err
+	}
+	decodeOpts := gifdecode.DefaultOptions()
+	decodeOpts.MaxFrames = -1 // every frame; 0 would mean the 60-frame preview default
+	decodeOpts.MaxDelay = maxGIFDelay
+	decoded, err := gifdecode.Decode(data, decodeOpts)
+	if err != nil {
+		return err
+	}
+	loopCount := 0
+	if info, err := gifdecode.Inspect(data); err == nil {
+		loopCount = info.LoopCount
+	}
+
+	output, err := gifedit.Encode(decoded, gifedit.Options{
+		Start:     opts.EditStart,
+		End:       opts.EditEnd,
+		Crop:      opts.EditCrop,
+		Width:     opts.EditWidth,
+		Height:    opts.EditHeight,
+		Colors:    opts.EditColors,
+		LoopCount: loopCount,
+		NoDither:  opts.EditNoDither,
+	})
+	if err != nil {
+		return err
+	}
+
+	outPath := resolveEditOutPath(opts)
+	if err := writeOutput(outPath, output); err != nil {
+		return err
+	}
+	if opts.Verbose > 0 && !opts.Quiet {
+		_, _ = fmt.Fprintf(stderr, "wrote %s (%s, was %s)\n", outPath, formatByteSize(int64(len(output))), formatByteSize(int64(len(data))))
+	}
+	return nil
+}
+
+func resolveEditOutPath(opts model.Options) string {
+	if opts.OutPath == "" {
+		return "edited.gif"
+	}
+	return opts.OutPath
+}
@@ -0,0 +1,103 @@
+package app
+
+import (
+	"bytes"
+	"image"
+	"image/gif"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/steipete/gifgrep/internal/model"
+	"github.com/steipete/gifgrep/internal/testutil"
+)
+
+func TestRunEdit(t *testing.T) {
+	data := testutil.MakeTestGIF()
+	inPath := filepath.Join(t.TempDir(), "in.gif")
+	if err := os.WriteFile(inPath, data, 0o644); err != nil {
+		t.Fatalf("write input: %v", err)
+	}
+	outPath := filepath.Join(t.TempDir(), "out.gif")
+
+	err := runEdit(&bytes.Buffer{}, model.Options{
+		GifInput:  inPath,
+		EditStart: 0,
+		EditEnd:   0,
+		EditCrop:  image.Rect(0, 0, 1, 2),
+		OutPath:   outPath,
+	})
+	if err != nil {
+		t.Fatalf("runEdit failed: %v", err)
+	}
+	raw, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatalf("read output: %v", err)
+	}
+	g, err := gif.DecodeAll(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatalf("gif decode: %v", err)
+	}
+	if len(g.Image) != 2 || g.Config.Width != 1 || g.Config.Height != 2 {
+		t.Fatalf("unexpected output: %d frames %dx%d", len(g.Image), g.Config.Width, g.Config.Height)
+	}
+}
+
+func TestRunEditBadArgs(t *testing.T) {
+	cases := []model.Options{
+		{},
+		{GifInput: "x.gif", EditStart: 2, EditEnd: 1},
+		{GifInput: "x.gif", EditWidth: -1},
+		{GifInput: "x.gif", EditColors: 1},
+	}
+	for _, opts := range cases {
+		if err := runEdit(&bytes.Buffer{}, opts); err == nil {
+			t.Fatalf("expected error for %+v", opts)
+		}
+	}
+}
+
+func TestCropValue(t *testing.T) {
+	var c CropValue
+	if err := c.UnmarshalText([]byte("320x240+10+20")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if image.Rectangle(c) != image.Rect(10, 20, 330, 260) {
+		t.Fatalf("unexpected rect %v", image.Rectangle(c))
+	}
+	if err := c.UnmarshalText([]byte("16x9")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if image.Rectangle(c) != image.Rect(0, 0, 16, 9) {
+		t.Fatalf("unexpected rect %v", image.Rectangle(c))
+	}
+	for _, bad := range []string{"nope", "0x10", "10x10+1", "-1x2+0+0"} {
+		if err := c.UnmarshalText([]byte(bad)); err == nil {
+			t.Fatalf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestRunEditKeepsFramesPastPreviewLimit(t *testing.T) {
+	inPath := filepath.Join(t.TempDir(), "long.gif")
+	if err := os.WriteFile(inPath, testutil.MakeTestGIFFrames(70), 0o644); err != nil {
+		t.Fatalf("write input: %v", err)
+	}
+	outPath := filepath.Join(t.TempDir(), "out.gif")
+	// 20ms frames: 1.3s ends on frame 66.
+	if err := runEdit(&bytes.Buffer{}, model.Options{GifInput: inPath, EditEnd: 1300 * time.Millisecond, OutPath: outPath}); err != nil {
+		t.Fatalf("runEdit failed: %v", err)
+	}
+	raw, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatalf("read output: %v", err)
+	}
+	g, err := gif.DecodeAll(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatalf("gif decode: %v", err)
+	}
+	if len(g.Image) != 66 {
+		t.Fatalf("got %d frames, want 66", len(g.Image))
+	}
+}
@@ -0,0 +1,212 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"github.com/alecthomas/kong"
+	"github.com/steipete/gifgrep/internal/favorites"
+	"github.com/steipete/gifgrep/internal/model"
+)
+
+// favStdin is where `fav add -` reads results from.
+var favStdin io.Reader = os.Stdin
+
+type FavCmd struct {
+	Add FavAddCmd `cmd:"" help:"Save GIF URLs, or search --json/ndjson results from stdin ('-'), to a collection."`
+	Rm  FavRmCmd  `cmd:"" help:"Remove GIFs from a collection by ID or URL."`
+	Ls  FavLsCmd  `cmd:"" help:"List collections, or the GIFs in one."`
+}
+
+type FavAddCmd struct {
+	Collection string   `help:"Collection name." short:"c" default:"favorites"`
+	Title      string   `help:"Title for GIFs given as URLs."`
+	Provider   string   `help:"Provider the GIFs came from (recorded with them)." enum:",tenor,giphy,heypster" default:""`
+	URLs       []string `arg:"" name:"url" help:"GIF URLs, or '-' to read results from stdin."`
+}
+
+func (c *FavAddCmd) Run(ctx *kong.Context, cli *CLI) error {
+	var results []model.Result
+	for _, arg := range c.URLs {
+		if arg == "-" {
+			read, err := readResults(favStdin)
+			if err != nil {
+				return err
+			}
+			results = append(results, read...)
+			continue
+		}
+		results = append(results, model.Result{Title: c.Title, URL: arg})
+	}
+	store, err := loadFavorites()
+	if err != nil {
+		return err
+	}
+	added := 0
+	for _, res := range results {
+		ok, err := store.Add(c.Collection, res, c.Provider)
+		if err != nil {
+			return fmt.Errorf("%s: %w", normalizeTitle(res), err)
+		}
+		if ok {
+			added++
+		}
+	}
+	if err := store.Save(); err != nil {
+		return err
+	}
+	if !cli.Globals.Quiet {
+		_, _ = fmt.Fprintf(ctx.Stderr, "added %d to %s (%d already there)\n", added, c.Collection, len(results)-added)
+	}
+	return nil
+}
+
+type FavRmCmd struct {
+	Collection string   `help:"Collection name." short:"c" default:"favorites"`
+	Keys       []string `arg:"" name:"id-or-url" help:"Result IDs or URLs."`
+}
+
+func (c *FavRmCmd) Run(ctx *kong.Context, cli *CLI) error {
+	store, err := loadFavorites()
+	if err != nil {
+		return err
+	}
+	if _, ok := store.Collections[c.Collection]; !ok {
+		return fmt.Errorf("no collection %q", c.Collection)
+	}
+	removed := 0
+	var missing []string
+	for _, key := range c.Keys {
+		n := store.RemoveKey(c.Collection, key)
+		if n == 0 {
+			missing = append(missing, key)
+		}
+		removed += n
+	}
+	if err := store.Save(); err != nil {
+		return err
+	}
+	if !cli.Globals.Quiet {
+		_, _ = fmt.Fprintf(ctx.Stderr, "removed %d from %s\n", removed, c.Collection)
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("not in %s: %s", c.Collection, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+type FavLsCmd struct {
+	JSON       bool   `help:"Emit JSON."`
+	Number     bool   `help:"Prefix lines with 1-based index." short:"n"`
+	Format     string `help:"Output format for a collection's GIFs." enum:"auto,plain,tsv,md,url,comment,json,ndjson,csv" default:"auto"`
+	Template   string `help:"Go text/template per GIF (see search --template)." short:"t"`
+	Collection string `arg:"" optional:"" name:"collection" help:"Collection to list (default: list collections)."`
+}
+
+func (c *FavLsCmd) Run(ctx *kong.Context, cli *CLI) error {
+	store, err := loadFavorites()
+	if err != nil {
+		return err
+	}
+	if c.Collection == "" {
+		return writeCollections(ctx.Stdout, store, c.JSON)
+	}
+	entries, ok := store.Collections[c.Collection]
+	if !ok {
+		return fmt.Errorf("no collection %q (have: %s)", c.Collection, strings.Join(store.Names(), ", "))
+	}
+	if c.JSON {
+		return writeJSONEntries(ctx.Stdout, entries)
+	}
+
+	opts := cli.Globals.toOptions()
+	opts.Number = c.Number
+	opts.Format = c.Format
+	opts.Thumbs = "auto"
+	opts.Template = cli.settings.LookupTemplate(c.Template)
+	var tmpl *template.Template
+	if opts.Template != "" {
+		if tmpl, err = parseResultTemplate(opts.Template); err != nil {
+			return err
+		}
+	}
+	results := make([]model.Result, len(entries))
+	for i, e := range entries {
+		results[i] = e.Result
+	}
+	return writeResults(ctx.Stdout, opts, tmpl, results)
+}
+
+func loadFavorites() (*favorites.Store, error) {
+	path, err := favorites.DefaultPath()
+	if err != nil {
+		return nil, err
+	}
+	return favorites.Load(path)
+}
+
+func writeCollections(stdout io.Writer, store *favorites.Store, asJSON bool) error {
+	names := store.Names()
+	if asJSON {
+		type collection struct {
+			Name  string `json:"name"`
+			Count int    `json:"count"`
+		}
+		list := make([]collection, len(names))
+		for i, name := range names {
+			list[i] = collection{Name: name, Count: len(store.Collections[name])}
+		}
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(list)
+	}
+	w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	for _, name := range names {
+		_, _ = fmt.Fprintf(w, "%s\t%d\n", name, len(store.Collections[name]))
+	}
+	return w.Flush()
+}
+
+func writeJSONEntries(stdout io.Writer, entries []favorites.Entry) error {
+	enc := json.NewEncoder(stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(entries)
+}
+
+// readResults accepts what search --json (an array) or --format ndjson (one
+// object per line) prints.
+func readResults(r io.Reader) ([]model.Result, error) {
+	dec := json.NewDecoder(r)
+	var results []model.Result
+	for {
+		var batch []model.Result
+		raw := json.RawMessage{}
+		if err := dec.Decode(&raw); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("stdin: %w", err)
+		}
+		if len(raw) > 0 && raw[0] == '[' {
+			if err := json.Unmarshal(raw, &batch); err != nil {
+				return nil, fmt.Errorf("stdin: %w", err)
+			}
+		} else {
+			var res model.Result
+			if err := json.Unmarshal(raw, &res); err != nil {
+				return nil, fmt.Errorf("stdin: %w", err)
+			}
+			batch = []model.Result{res}
+		}
+		results = append(results, batch...)
+	}
+	if len(results) == 0 {
+		return nil, errors.New("no results on stdin")
+	}
+	return results, nil
+}
@@ -0,0 +1,77 @@
+package app
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/steipete/gifgrep/internal/favorites"
+)
+
+func TestFavCommands(t *testing.T) {
+	data := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", data)
+	t.Setenv("GIFGREP_CONFIG", filepath.Join(t.TempDir(), "config.toml"))
+	prev := favStdin
+	t.Cleanup(func() { favStdin = prev })
+	favStdin = strings.NewReader(`[{"id":"1","title":"Cat","url":"https://x.test/1.gif"}]` + "\n" + `{"id":"2","title":"Dog","url":"https://x.test/2.gif"}` + "\n")
+
+	if code := Run([]string{"-q", "fav", "add", "--provider", "tenor", "-"}); code != 0 {
+		t.Fatalf("fav add - exit %d", code)
+	}
+	if code := Run([]string{"-q", "fav", "add", "-c", "work", "--title", "Wave", "https://x.test/3.gif"}); code != 0 {
+		t.Fatalf("fav add url exit %d", code)
+	}
+
+	stdout := captureStdout(t, func() {
+		if code := Run([]string{"fav", "ls"}); code != 0 {
+			t.Fatalf("fav ls exit %d", code)
+		}
+	})
+	if stdout != "favorites  2\nwork       1\n" {
+		t.Fatalf("fav ls printed %q", stdout)
+	}
+
+	stdout = captureStdout(t, func() {
+		if code := Run([]string{"fav", "ls", "favorites", "--format", "url"}); code != 0 {
+			t.Fatalf("fav ls favorites exit %d", code)
+		}
+	})
+	if stdout != "https://x.test/1.gif\nhttps://x.test/2.gif\n" {
+		t.Fatalf("fav ls favorites printed %q", stdout)
+	}
+
+	stdout = captureStdout(t, func() {
+		if code := Run([]string{"fav", "ls", "work", "--json"}); code != 0 {
+			t.Fatalf("fav ls --json exit %d", code)
+		}
+	})
+	var entries []favorites.Entry
+	if err := json.Unmarshal([]byte(stdout), &entries); err != nil || len(entries) != 1 || entries[0].Title != "Wave" {
+		t.Fatalf("fav ls --json: %v %q", err, stdout)
+	}
+
+	if code := Run([]string{"-q", "fav", "rm", "1"}); code != 0 {
+		t.Fatalf("fav rm exit %d", code)
+	}
+	if code := Run([]string{"-q", "fav", "rm", "1"}); code != 1 {
+		t.Fatalf("fav rm of a missing GIF should fail")
+	}
+	if code := Run([]string{"fav", "ls", "nope"}); code != 1 {
+		t.Fatalf("fav ls of a missing collection should fail")
+	}
+	store, err := favorites.Load(filepath.Join(data, "gifgrep", "favorites.json"))
+	if err != nil || len(store.Collections["favorites"]) != 1 || store.Collections["favorites"][0].Source != "tenor" {
+		t.Fatalf("store: %+v %v", store, err)
+	}
+}
+
+func TestReadResultsRejectsEmptyInput(t *testing.T) {
+	if _, err := readResults(strings.NewReader("  \n")); err == nil {
+		t.Fatalf("expected error")
+	}
+	if _, err := readResults(strings.NewReader("not json")); err == nil {
+		t.Fatalf("expected error")
+	}
+}
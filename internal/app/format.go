@@ -2,16 +2,20 @@ package app
 
 import (
 	"bufio"
+	"bytes"
 	"encoding/binary"
 	"fmt"
+	"image/png"
 	"io"
 	"os"
 	"strings"
 
 	"github.com/steipete/gifgrep/gifdecode"
+	"github.com/steipete/gifgrep/internal/blocks"
 	"github.com/steipete/gifgrep/internal/iterm"
 	"github.com/steipete/gifgrep/internal/kitty"
 	"github.com/steipete/gifgrep/internal/model"
+	"github.com/steipete/gifgrep/internal/sixel"
 	"github.com/steipete/gifgrep/internal/termcaps"
 	"golang.org/x/term"
 )
@@ -26,6 +30,8 @@ const (
 	formatURL     outputFormat = "url"
 	formatComment outputFormat = "comment"
 	formatJSON    outputFormat = "json"
+	formatNDJSON  outputFormat = "ndjson"
+	formatCSV     outputFormat = "csv"
 )
 
 type thumbsMode string
@@ -51,17 +57,43 @@ var (
 		decodeOpts.MaxFrames = 1
 		return gifdecode.Decode(data, decodeOpts)
 	}
-	sendThumbKitty = func(out *bufio.Writer, id uint32, frame gifdecode.Frame, cols, rows int) {
-		kitty.SendFrame(out, id, frame, cols, rows)
+	thumbKittyOptions = func() kitty.Options {
+		return kitty.DetectOptions(os.Getenv)
+	}
+	sendThumbKitty = func(out *bufio.Writer, id uint32, frame gifdecode.Frame, cols, rows int, opts kitty.Options) {
+		kitty.SendFrame(out, id, frame, cols, rows, opts)
 	}
 	sendThumbIterm = func(out *bufio.Writer, data []byte, cols, rows int) {
-		iterm.SendInlineFile(out, iterm.File{
+		iterm.SendFile(out, iterm.File{
 			Name:        thumbInlineName(data),
 			Data:        data,
 			WidthCells:  cols,
 			HeightCells: rows,
 			Stretch:     true,
-		})
+		}, iterm.MultipartSupported(os.Getenv))
+	}
+	sendThumbSixel = func(out *bufio.Writer, frame gifdecode.Frame, cols, rows int, cell termcaps.CellSize) error {
+		img, err := png.Decode(bytes.NewReader(frame.PNG))
+		if err != nil {
+			return err
+		}
+		if !cell.Valid() {
+			cell = termcaps.CellSize{Width: sixel.DefaultCellWidth, Height: sixel.DefaultCellHeight}
+		}
+		b := img.Bounds()
+		w, h := sixel.FitPixels(b.Dx(), b.Dy(), cols*cell.Width, rows*cell.Height)
+		_, _ = out.Write(sixel.Encode(img, w, h))
+		return nil
+	}
+	sendThumbBlocks = func(out *bufio.Writer, frame gifdecode.Frame, cols, rows int) error {
+		img, err := png.Decode(bytes.NewReader(frame.PNG))
+		if err != nil {
+			return err
+		}
+		for _, line := range blocks.Render(img, cols, rows, blocks.OptionsFromEnv(os.Getenv)) {
+			_, _ = fmt.Fprint(out, "\r"+line+"\n")
+		}
+		return nil
 	}
 )
 
@@ -98,7 +130,11 @@ func thumbsProtocol(opts model.Options, stdout io.Writer, format outputFormat) t
 	case thumbsNever:
 		return termcaps.InlineNone
 	case thumbsAlways:
-		return termcaps.DetectInlineRobust(os.Getenv)
+		// Block art works everywhere, so "always" never ends up without thumbs.
+		if p := termcaps.DetectInlineRobust(os.Getenv); p != termcaps.InlineNone {
+			return p
+		}
+		return termcaps.InlineBlocks
 	case thumbsAuto:
 		return termcaps.DetectInlineRobust(os.Getenv)
 	}
@@ -112,6 +148,7 @@ func renderPlain(
 	thumbs termcaps.InlineProtocol,
 	results []model.Result,
 	termCols int,
+	cell termcaps.CellSize,
 ) {
 	nextID := uint32(1)
 	withThumbs := thumbs != termcaps.InlineNone
@@ -124,10 +161,10 @@ func renderPlain(
 			nPrefix = fmt.Sprintf("%d. ", i+1)
 		}
 
-		if withThumbs && renderThumbBlock(out, thumbs, nextID, res, nPrefix, title, url, useColor, termCols) == nil {
+		if withThumbs && renderThumbBlock(out, thumbs, nextID, res, nPrefix, title, url, useColor, termCols, cell) == nil {
 			nextID++
 			if i < len(results)-1 {
-				if thumbs == termcaps.InlineIterm {
+				if thumbsInTextGrid(thumbs) {
 					_, _ = fmt.Fprint(out, "\r\x1b[K\n")
 				} else {
 					_, _ = fmt.Fprintln(out)
@@ -148,18 +185,18 @@ func renderPlain(
 	}
 }
 
-func renderThumbBlock(out *bufio.Writer, thumbs termcaps.InlineProtocol, id uint32, res model.Result, nPrefix, title, url string, useColor bool, termCols int) error {
+func renderThumbBlock(out *bufio.Writer, thumbs termcaps.InlineProtocol, id uint32, res model.Result, nPrefix, title, url string, useColor bool, termCols int, cell termcaps.CellSize) error {
 	data, src, err := fetchThumbForResult(res)
 	if err != nil {
 		return err
 	}
-	cols, rows := thumbBlockSize(thumbs, data, res)
+	cols, rows := thumbBlockSize(thumbs, data, res, cell)
 
 	data, err = prepareThumbData(thumbs, data, src, res)
 	if err != nil {
 		return err
 	}
-	if err := sendThumb(out, thumbs, id, data, cols, rows); err != nil {
+	if err := sendThumb(out, thumbs, id, data, cols, rows, cell); err != nil {
 		return err
 	}
 
@@ -263,12 +300,16 @@ func fetchThumbForResult(res model.Result) ([]byte, string, error) {
 	return data, src, nil
 }
 
-func thumbBlockSize(thumbs termcaps.InlineProtocol, data []byte, res model.Result) (int, int) {
+func thumbBlockSize(thumbs termcaps.InlineProtocol, data []byte, res model.Result, cell termcaps.CellSize) (int, int) {
 	cols := 16
 	rows := 8
 	if w, h := thumbDims(data, res); w > 0 && h > 0 {
 		if thumbs != termcaps.InlineIterm {
-			rows = clampInt(3, 10, int(float64(cols)*0.5*float64(h)/float64(w)))
+			aspect := 0.5
+			if a := cell.Aspect(); a > 0.1 && a < 2 {
+				aspect = a
+			}
+			rows = clampInt(3, 10, int(float64(cols)*aspect*float64(h)/float64(w)))
 		}
 	}
 	return cols, rows
@@ -291,7 +332,7 @@ func prepareThumbData(thumbs termcaps.InlineProtocol, data []byte, src string, r
 			return nil, fmt.Errorf("unsupported image")
 		}
 		return data, nil
-	case termcaps.InlineKitty:
+	case termcaps.InlineKitty, termcaps.InlineSixel, termcaps.InlineBlocks:
 		if len(data) == 0 {
 			return nil, fmt.Errorf("empty image")
 		}
@@ -301,7 +342,7 @@ func prepareThumbData(thumbs termcaps.InlineProtocol, data []byte, src string, r
 	}
 }
 
-func sendThumb(out *bufio.Writer, thumbs termcaps.InlineProtocol, id uint32, data []byte, cols, rows int) error {
+func sendThumb(out *bufio.Writer, thumbs termcaps.InlineProtocol, id uint32, data []byte, cols, rows int, cell termcaps.CellSize) error {
 	switch thumbs {
 	case termcaps.InlineNone:
 		return fmt.Errorf("inline thumbnails not supported")
@@ -320,17 +361,71 @@ func sendThumb(out *bufio.Writer, thumbs termcaps.InlineProtocol, id uint32, dat
 		if decoded == nil || len(decoded.Frames) == 0 {
 			return fmt.Errorf("no frames")
 		}
-		sendThumbKitty(out, id, decoded.Frames[0], cols, rows)
+		opts := thumbKittyOptions()
+		sendThumbKitty(out, id, decoded.Frames[0], cols, rows, opts)
+		if opts.Placeholders {
+			for r := 0; r < rows; r++ {
+				_, _ = fmt.Fprint(out, "\r"+kitty.PlaceholderRow(id, r, cols)+"\n")
+			}
+			_, _ = fmt.Fprintf(out, "\x1b[%dA", rows)
+		}
+		return nil
+	case termcaps.InlineSixel:
+		decoded, err := decodeThumb(data)
+		if err != nil {
+			return err
+		}
+		if decoded == nil || len(decoded.Frames) == 0 {
+			return fmt.Errorf("no frames")
+		}
+		// Reserve the block's rows first so drawing can't scroll the screen,
+		// then paint from its top-left corner and return there for the text.
+		_, _ = fmt.Fprint(out, "\r"+strings.Repeat("\n", rows))
+		_, _ = fmt.Fprintf(out, "\x1b[%dA\x1b7", rows)
+		err = sendThumbSixel(out, decoded.Frames[0], cols, rows, cell)
+		_, _ = fmt.Fprint(out, "\x1b8")
+		return err
+	case termcaps.InlineBlocks:
+		decoded, err := decodeThumb(data)
+		if err != nil {
+			return err
+		}
+		if decoded == nil || len(decoded.Frames) == 0 {
+			return fmt.Errorf("no frames")
+		}
+		if err := sendThumbBlocks(out, decoded.Frames[0], cols, rows); err != nil {
+			return err
+		}
+		// Back to the block's first row for the title/URL text.
+		_, _ = fmt.Fprintf(out, "\x1b[%dA", rows)
 		return nil
 	default:
 		return fmt.Errorf("inline thumbnails not supported")
 	}
 }
 
+// thumbsInTextGrid reports whether thumbnails are painted into the text grid,
+// where writing spaces over them would erase them.
+func thumbsInTextGrid(thumbs termcaps.InlineProtocol) bool {
+	switch thumbs {
+	case termcaps.InlineIterm, termcaps.InlineSixel, termcaps.InlineBlocks:
+		return true
+	case termcaps.InlineKitty:
+		return thumbKittyOptions().Placeholders
+	case termcaps.InlineNone:
+		return false
+	default:
+		return false
+	}
+}
+
 func thumbIndentCols(thumbs termcaps.InlineProtocol, cols int) int {
 	if thumbs == termcaps.InlineIterm {
 		return cols
 	}
+	if thumbsInTextGrid(thumbs) {
+		return cols + 1
+	}
 	return cols + 2
 }
 
@@ -374,7 +469,7 @@ func writeThumbTextBlock(out *bufio.Writer, thumbs termcaps.InlineProtocol, rows
 			}
 		}
 
-		if thumbs == termcaps.InlineIterm {
+		if thumbsInTextGrid(thumbs) {
 			col := indentCols + 1
 			if col < 1 {
 				col = 1
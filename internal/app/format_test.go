@@ -11,6 +11,7 @@ import (
 	"testing"
 
 	"github.com/steipete/gifgrep/gifdecode"
+	"github.com/steipete/gifgrep/internal/kitty"
 	"github.com/steipete/gifgrep/internal/model"
 	"github.com/steipete/gifgrep/internal/termcaps"
 )
@@ -54,7 +55,7 @@ func TestRenderPlainNoThumbs(t *testing.T) {
 
 	renderPlain(out, model.Options{Number: true}, false, termcaps.InlineNone, []model.Result{
 		{Title: "A dog", URL: "https://example.test/a.gif"},
-	}, 0)
+	}, 0, termcaps.CellSize{})
 	_ = out.Flush()
 
 	text := buf.String()
@@ -70,17 +71,20 @@ func TestRenderPlainThumbsIncludesBlockGap(t *testing.T) {
 	prevFetch := fetchThumb
 	prevDecode := decodeThumb
 	prevSend := sendThumbKitty
+	prevOpts := thumbKittyOptions
 	t.Cleanup(func() {
 		fetchThumb = prevFetch
 		decodeThumb = prevDecode
 		sendThumbKitty = prevSend
+		thumbKittyOptions = prevOpts
 	})
 
 	fetchThumb = func(_ string) ([]byte, error) { return []byte("gif"), nil }
 	decodeThumb = func(_ []byte) (*gifdecode.Frames, error) {
 		return &gifdecode.Frames{Frames: []gifdecode.Frame{{PNG: []byte{1}}}}, nil
 	}
-	sendThumbKitty = func(out *bufio.Writer, id uint32, _ gifdecode.Frame, _, _ int) {
+	thumbKittyOptions = func() kitty.Options { return kitty.Options{} }
+	sendThumbKitty = func(out *bufio.Writer, id uint32, _ gifdecode.Frame, _, _ int, _ kitty.Options) {
 		_, _ = fmt.Fprintf(out, "<IMG%d>", id)
 	}
 
@@ -90,7 +94,7 @@ func TestRenderPlainThumbsIncludesBlockGap(t *testing.T) {
 	renderPlain(out, model.Options{}, false, termcaps.InlineKitty, []model.Result{
 		{Title: "A", URL: "https://example.test/a.gif"},
 		{Title: "B", URL: "https://example.test/b.gif"},
-	}, 0)
+	}, 0, termcaps.CellSize{})
 	_ = out.Flush()
 
 	text := buf.String()
@@ -133,7 +137,7 @@ func TestRenderPlainThumbsItermUsesRawGIF(t *testing.T) {
 
 	renderPlain(out, model.Options{}, false, termcaps.InlineIterm, []model.Result{
 		{Title: "A", URL: "https://example.test/a.gif"},
-	}, 80)
+	}, 80, termcaps.CellSize{})
 	_ = out.Flush()
 
 	text := buf.String()
@@ -165,7 +169,7 @@ func TestRenderPlainThumbsItermWrapsURLToTerminalWidth(t *testing.T) {
 	url := strings.Repeat("a", 30)
 	renderPlain(out, model.Options{}, false, termcaps.InlineIterm, []model.Result{
 		{Title: "T", URL: url},
-	}, termCols)
+	}, termCols, termcaps.CellSize{})
 	_ = out.Flush()
 
 	text := stripItermCursorMoves(strings.TrimSuffix(buf.String(), "\n"))
@@ -182,3 +186,179 @@ func TestRenderPlainThumbsItermWrapsURLToTerminalWidth(t *testing.T) {
 		t.Fatalf("unexpected second url line: %q", got)
 	}
 }
+
+func TestRenderPlainThumbsSixelReservesRows(t *testing.T) {
+	prevFetch := fetchThumb
+	prevDecode := decodeThumb
+	prevSend := sendThumbSixel
+	t.Cleanup(func() {
+		fetchThumb = prevFetch
+		decodeThumb = prevDecode
+		sendThumbSixel = prevSend
+	})
+
+	fetchThumb = func(_ string) ([]byte, error) { return []byte("GIF89a\x02\x00\x01\x00"), nil }
+	decodeThumb = func(_ []byte) (*gifdecode.Frames, error) {
+		return &gifdecode.Frames{Frames: []gifdecode.Frame{{PNG: []byte{1}}}}, nil
+	}
+	sendThumbSixel = func(out *bufio.Writer, _ gifdecode.Frame, cols, rows int, _ termcaps.CellSize) error {
+		_, _ = fmt.Fprintf(out, "<SIXEL %dx%d>", cols, rows)
+		return nil
+	}
+
+	var buf bytes.Buffer
+	out := bufio.NewWriter(&buf)
+
+	renderPlain(out, model.Options{}, false, termcaps.InlineSixel, []model.Result{
+		{Title: "A", URL: "https://example.test/a.gif"},
+	}, 80, termcaps.CellSize{})
+	_ = out.Flush()
+
+	text := buf.String()
+	if !strings.HasPrefix(text, "\r\n\n\n\n\x1b[4A\x1b7<SIXEL 16x4>\x1b8") {
+		t.Fatalf("expected reserved rows around sixel: %q", text)
+	}
+	// Text is positioned with CSI G so it doesn't paint over the image.
+	if !strings.Contains(text, "\x1b[18GA") {
+		t.Fatalf("expected title after thumb column: %q", text)
+	}
+}
+
+func TestRenderPlainThumbsBlocksReturnsToBlockTop(t *testing.T) {
+	prevFetch := fetchThumb
+	prevDecode := decodeThumb
+	prevSend := sendThumbBlocks
+	t.Cleanup(func() {
+		fetchThumb = prevFetch
+		decodeThumb = prevDecode
+		sendThumbBlocks = prevSend
+	})
+
+	fetchThumb = func(_ string) ([]byte, error) { return []byte("GIF89a\x02\x00\x01\x00"), nil }
+	decodeThumb = func(_ []byte) (*gifdecode.Frames, error) {
+		return &gifdecode.Frames{Frames: []gifdecode.Frame{{PNG: []byte{1}}}}, nil
+	}
+	sendThumbBlocks = func(out *bufio.Writer, _ gifdecode.Frame, _, rows int) error {
+		for i := 0; i < rows; i++ {
+			_, _ = fmt.Fprint(out, "\r<ROW>\n")
+		}
+		return nil
+	}
+
+	var buf bytes.Buffer
+	out := bufio.NewWriter(&buf)
+
+	renderPlain(out, model.Options{}, false, termcaps.InlineBlocks, []model.Result{
+		{Title: "A", URL: "https://example.test/a.gif"},
+	}, 80, termcaps.CellSize{})
+	_ = out.Flush()
+
+	text := buf.String()
+	if !strings.HasPrefix(text, strings.Repeat("\r<ROW>\n", 4)+"\x1b[4A\x1b[18GA") {
+		t.Fatalf("expected rows then title beside them: %q", text)
+	}
+}
+
+func TestRenderPlainThumbsKittyPlaceholders(t *testing.T) {
+	prevFetch := fetchThumb
+	prevDecode := decodeThumb
+	prevSend := sendThumbKitty
+	prevOpts := thumbKittyOptions
+	t.Cleanup(func() {
+		fetchThumb = prevFetch
+		decodeThumb = prevDecode
+		sendThumbKitty = prevSend
+		thumbKittyOptions = prevOpts
+	})
+
+	fetchThumb = func(_ string) ([]byte, error) { return []byte("GIF89a\x02\x00\x01\x00"), nil }
+	decodeThumb = func(_ []byte) (*gifdecode.Frames, error) {
+		return &gifdecode.Frames{Frames: []gifdecode.Frame{{PNG: []byte{1}}}}, nil
+	}
+	thumbKittyOptions = func() kitty.Options { return kitty.Options{Placeholders: true} }
+	sendThumbKitty = func(out *bufio.Writer, id uint32, _ gifdecode.Frame, _, _ int, opts kitty.Options) {
+		if !opts.Placeholders {
+			t.Fatalf("expected placeholder options")
+		}
+		_, _ = fmt.Fprintf(out, "<IMG%d>", id)
+	}
+
+	var buf bytes.Buffer
+	out := bufio.NewWriter(&buf)
+
+	renderPlain(out, model.Options{}, false, termcaps.InlineKitty, []model.Result{
+		{Title: "A", URL: "https://example.test/a.gif"},
+	}, 80, termcaps.CellSize{})
+	_ = out.Flush()
+
+	text := buf.String()
+	cols, rows := thumbBlockSize(termcaps.InlineKitty, []byte("GIF89a\x02\x00\x01\x00"), model.Result{}, termcaps.CellSize{})
+	want := "<IMG1>\r" + kitty.PlaceholderRow(1, 0, cols) + "\n"
+	if !strings.HasPrefix(text, want) {
+		t.Fatalf("expected placeholder rows after the image: %q", text)
+	}
+	if !strings.Contains(text, fmt.Sprintf("\x1b[%dA\x1b[%dGA", rows, cols+2)) {
+		t.Fatalf("expected title beside the placeholders: %q", text)
+	}
+}
+
+func TestThumbBlockSizeUsesCellAspect(t *testing.T) {
+	square := []byte("GIF89a\x04\x00\x04\x00")
+	if _, rows := thumbBlockSize(termcaps.InlineSixel, square, model.Result{}, termcaps.CellSize{}); rows != 8 {
+		t.Fatalf("expected 1:2 default cells, got %d rows", rows)
+	}
+	if _, rows := thumbBlockSize(termcaps.InlineSixel, square, model.Result{}, termcaps.CellSize{Width: 10, Height: 25}); rows != 6 {
+		t.Fatalf("expected detected aspect to shorten the block, got %d rows", rows)
+	}
+}
+
+func TestTermCellSizeSkipsIterm(t *testing.T) {
+	prev := detectCellSize
+	t.Cleanup(func() { detectCellSize = prev })
+	calls := 0
+	detectCellSize = func() termcaps.CellSize {
+		calls++
+		return termcaps.CellSize{Width: 9, Height: 18}
+	}
+	if c := termCellSize(termcaps.InlineIterm); c.Valid() {
+		t.Fatalf("expected no probe for iTerm2")
+	}
+	if c := termCellSize(termcaps.InlineSixel); c.Width != 9 || calls != 1 {
+		t.Fatalf("expected probed size, got %+v after %d calls", c, calls)
+	}
+}
+
+func TestWriteSearchResultsCSVQuotes(t *testing.T) {
+	var buf bytes.Buffer
+	out := bufio.NewWriter(&buf)
+	writeSearchResults(out, model.Options{}, true, termcaps.InlineNone, []model.Result{
+		{ID: "7", Title: `Say "hi", friend`, URL: "https://example.test/a.gif", Tags: []string{"a", "b"}},
+	}, 0, termcaps.CellSize{}, formatCSV)
+	_ = out.Flush()
+
+	want := "id,title,url,preview_url,width,height,tags\n" +
+		`7,"Say ""hi"", friend",https://example.test/a.gif,,0,0,a b` + "\n"
+	if buf.String() != want {
+		t.Fatalf("got %q, want %q", buf.String(), want)
+	}
+}
+
+// lineCountWriter records how many lines each write carried.
+type lineCountWriter struct{ writes []int }
+
+func (w *lineCountWriter) Write(p []byte) (int, error) {
+	w.writes = append(w.writes, bytes.Count(p, []byte("\n")))
+	return len(p), nil
+}
+
+func TestWriteSearchResultsNDJSONFlushesPerLine(t *testing.T) {
+	var w lineCountWriter
+	out := bufio.NewWriter(&w)
+	writeSearchResults(out, model.Options{}, false, termcaps.InlineNone, []model.Result{
+		{ID: "1", URL: "a"}, {ID: "2", URL: "b"},
+	}, 0, termcaps.CellSize{}, formatNDJSON)
+
+	if len(w.writes) != 2 || w.writes[0] != 1 || w.writes[1] != 1 {
+		t.Fatalf("expected one flushed line per result, got %v", w.writes)
+	}
+}
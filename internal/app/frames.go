@@ -0,0 +1,138 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/steipete/gifgrep/gifdecode"
+	"github.com/steipete/gifgrep/internal/model"
+	"github.com/steipete/gifgrep/internal/stills"
+)
+
+const framesManifestName = "manifest.json"
+
+type framesManifest struct {
+	Source string          `json:"source"`
+	Width  int             `json:"width"`
+	Height int             `json:"height"`
+	Every  int             `json:"every"`
+	Frames []manifestFrame `json:"frames"`
+}
+
+type manifestFrame struct {
+	File    string `json:"file"`
+	Frame   int    `json:"frame"`
+	StartMS int64  `json:"start_ms"`
+	DelayMS int64  `json:"delay_ms"`
+}
+
+func runFrames(stderr io.Writer, opts model.Options) (string, error) {
+	if opts.GifInput == "" {
+		return "", errors.New("missing GIF input")
+	}
+	if opts.FramesEvery < 1 {
+		return "", errors.New("bad args: --every must be >= 1")
+	}
+	if opts.FramesTo > 0 && opts.FramesTo < opts.FramesFrom {
+		return "", errors.New("bad args: --to must be >= --from")
+	}
+
+	data, err := readInput(opts.GifInput)
+	if err != nil {
+		return "", err
+	}
+	decodeOpts := gifdecode.DefaultOptions()
+	decodeOpts.MaxFrames = -1 // every frame; 0 would mean the 60-frame preview default
+	decoded, err := gifdecode.Decode(data, decodeOpts)
+	if err != nil {
+		return "", err
+	}
+	useFileDelays(data, decoded.Frames)
+
+	first, last, err := stills.FrameRange(decoded.Frames, opts.FramesFrom, opts.FramesTo)
+	if err != nil {
+		return "", err
+	}
+
+	dir := resolveFramesOutDir(opts)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+
+	manifest := framesManifest{
+		Source: opts.GifInput,
+		Width:  decoded.Width,
+		Height: decoded.Height,
+		Every:  opts.FramesEvery,
+	}
+	starts := frameStarts(decoded.Frames)
+	digits := max(4, len(fmt.Sprint((last-first)/opts.FramesEvery+1)))
+	for idx := first; idx <= last; idx += opts.FramesEvery {
+		name := fmt.Sprintf("frame_%0*d.png", digits, len(manifest.Frames)+1)
+		if err := os.WriteFile(filepath.Join(dir, name), decoded.Frames[idx].PNG, 0o644); err != nil {
+			return "", err
+		}
+		// Hold each exported frame until the next one so the sequence keeps
+		// the original timing when frames are skipped.
+		end := min(idx+opts.FramesEvery, last+1)
+		var delay time.Duration
+		for _, f := range decoded.Frames[idx:end] {
+			delay += f.Delay
+		}
+		manifest.Frames = append(manifest.Frames, manifestFrame{
+			File:    name,
+			Frame:   idx,
+			StartMS: starts[idx].Milliseconds(),
+			DelayMS: delay.Milliseconds(),
+		})
+	}
+
+	manifestPath := filepath.Join(dir, framesManifestName)
+	payload, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(manifestPath, append(payload, '\n'), 0o644); err != nil {
+		return "", err
+	}
+	if opts.Verbose > 0 && !opts.Quiet {
+		_, _ = fmt.Fprintf(stderr, "wrote %d frames to %s\n", len(manifest.Frames), dir)
+	}
+	return manifestPath, nil
+}
+
+func resolveFramesOutDir(opts model.Options) string {
+	if opts.OutPath == "" {
+		return "frames"
+	}
+	return opts.OutPath
+}
+
+// useFileDelays replaces the decoded delays with the ones stored in the
+// file. Decoding clamps them for playback (0 becomes 80ms, anything over 1s
+// is capped), which would misreport the timing in the manifest and shift
+// --from/--to.
+func useFileDelays(data []byte, frames []gifdecode.Frame) {
+	info, err := gifdecode.Inspect(data)
+	if err != nil || len(info.Delays) != len(frames) {
+		return
+	}
+	for i := range frames {
+		frames[i].Delay = info.Delays[i]
+	}
+}
+
+func frameStarts(frames []gifdecode.Frame) []time.Duration {
+	starts := make([]time.Duration, len(frames))
+	var elapsed time.Duration
+	for i, f := range frames {
+		starts[i] = elapsed
+		elapsed += f.Delay
+	}
+	return starts
+}
@@ -0,0 +1,167 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/steipete/gifgrep/internal/model"
+	"github.com/steipete/gifgrep/internal/testutil"
+)
+
+func TestRunFramesWritesSequenceAndManifest(t *testing.T) {
+	data := testutil.MakeTestGIF()
+	inPath := filepath.Join(t.TempDir(), "in.gif")
+	if err := os.WriteFile(inPath, data, 0o644); err != nil {
+		t.Fatalf("write input: %v", err)
+	}
+	outDir := filepath.Join(t.TempDir(), "frames")
+
+	manifestPath, err := runFrames(&bytes.Buffer{}, model.Options{
+		GifInput:    inPath,
+		FramesEvery: 1,
+		OutPath:     outDir,
+	})
+	if err != nil {
+		t.Fatalf("runFrames failed: %v", err)
+	}
+	if manifestPath != filepath.Join(outDir, "manifest.json") {
+		t.Fatalf("unexpected manifest path %q", manifestPath)
+	}
+
+	for _, name := range []string{"frame_0001.png", "frame_0002.png"} {
+		raw, err := os.ReadFile(filepath.Join(outDir, name))
+		if err != nil {
+			t.Fatalf("read %s: %v", name, err)
+		}
+		if _, err := png.Decode(bytes.NewReader(raw)); err != nil {
+			t.Fatalf("decode %s: %v", name, err)
+		}
+	}
+
+	raw, err := os.ReadFile(manifestPath)
+	if err != nil {
+		t.Fatalf("read manifest: %v", err)
+	}
+	var manifest framesManifest
+	if err := json.Unmarshal(raw, &manifest); err != nil {
+		t.Fatalf("manifest json: %v", err)
+	}
+	if len(manifest.Frames) != 2 || manifest.Width != 2 || manifest.Height != 2 {
+		t.Fatalf("unexpected manifest: %+v", manifest)
+	}
+	if manifest.Frames[0].DelayMS != 50 || manifest.Frames[1].StartMS != 50 || manifest.Frames[1].DelayMS != 70 {
+		t.Fatalf("unexpected timing: %+v", manifest.Frames)
+	}
+}
+
+func TestRunFramesEveryAndRange(t *testing.T) {
+	data := testutil.MakeTestGIF()
+	inPath := filepath.Join(t.TempDir(), "in.gif")
+	if err := os.WriteFile(inPath, data, 0o644); err != nil {
+		t.Fatalf("write input: %v", err)
+	}
+	outDir := t.TempDir()
+
+	manifestPath, err := runFrames(&bytes.Buffer{}, model.Options{
+		GifInput:    inPath,
+		FramesEvery: 2,
+		OutPath:     outDir,
+	})
+	if err != nil {
+		t.Fatalf("runFrames failed: %v", err)
+	}
+	raw, err := os.ReadFile(manifestPath)
+	if err != nil {
+		t.Fatalf("read manifest: %v", err)
+	}
+	var manifest framesManifest
+	if err := json.Unmarshal(raw, &manifest); err != nil {
+		t.Fatalf("manifest json: %v", err)
+	}
+	if len(manifest.Frames) != 1 || manifest.Frames[0].DelayMS != 120 {
+		t.Fatalf("expected one frame holding both delays: %+v", manifest.Frames)
+	}
+
+	outDir = t.TempDir()
+	manifestPath, err = runFrames(&bytes.Buffer{}, model.Options{
+		GifInput:    inPath,
+		FramesEvery: 1,
+		FramesFrom:  60 * time.Millisecond,
+		OutPath:     outDir,
+	})
+	if err != nil {
+		t.Fatalf("runFrames failed: %v", err)
+	}
+	raw, err = os.ReadFile(manifestPath)
+	if err != nil {
+		t.Fatalf("read manifest: %v", err)
+	}
+	manifest = framesManifest{}
+	if err := json.Unmarshal(raw, &manifest); err != nil {
+		t.Fatalf("manifest json: %v", err)
+	}
+	if len(manifest.Frames) != 1 || manifest.Frames[0].Frame != 1 {
+		t.Fatalf("expected only the second frame: %+v", manifest.Frames)
+	}
+}
+
+func TestRunFramesBadArgs(t *testing.T) {
+	if _, err := runFrames(&bytes.Buffer{}, model.Options{FramesEvery: 1}); err == nil {
+		t.Fatalf("expected missing input error")
+	}
+	if _, err := runFrames(&bytes.Buffer{}, model.Options{GifInput: "x.gif", FramesEvery: 0}); err == nil {
+		t.Fatalf("expected --every error")
+	}
+	opts := model.Options{GifInput: "x.gif", FramesEvery: 1, FramesFrom: time.Second, FramesTo: time.Millisecond}
+	if _, err := runFrames(&bytes.Buffer{}, opts); err == nil {
+		t.Fatalf("expected --to error")
+	}
+}
+
+func TestRunFramesExportsPastPreviewFrameLimit(t *testing.T) {
+	inPath := filepath.Join(t.TempDir(), "long.gif")
+	if err := os.WriteFile(inPath, testutil.MakeTestGIFFrames(70), 0o644); err != nil {
+		t.Fatalf("write input: %v", err)
+	}
+	outDir := filepath.Join(t.TempDir(), "frames")
+	if _, err := runFrames(&bytes.Buffer{}, model.Options{GifInput: inPath, FramesEvery: 1, OutPath: outDir}); err != nil {
+		t.Fatalf("runFrames failed: %v", err)
+	}
+	pngs, err := filepath.Glob(filepath.Join(outDir, "frame_*.png"))
+	if err != nil || len(pngs) != 70 {
+		t.Fatalf("exported %d frames, want 70 (%v)", len(pngs), err)
+	}
+}
+
+func TestRunFramesReportsFileDelays(t *testing.T) {
+	inPath := filepath.Join(t.TempDir(), "slow.gif")
+	if err := os.WriteFile(inPath, testutil.MakeTestGIFDelays(200, 0, 5), 0o644); err != nil {
+		t.Fatalf("write input: %v", err)
+	}
+	manifestPath, err := runFrames(&bytes.Buffer{}, model.Options{GifInput: inPath, FramesEvery: 1, OutPath: t.TempDir()})
+	if err != nil {
+		t.Fatalf("runFrames failed: %v", err)
+	}
+	raw, err := os.ReadFile(manifestPath)
+	if err != nil {
+		t.Fatalf("read manifest: %v", err)
+	}
+	var manifest framesManifest
+	if err := json.Unmarshal(raw, &manifest); err != nil {
+		t.Fatalf("manifest json: %v", err)
+	}
+	want := []struct{ start, delay int64 }{{0, 2000}, {2000, 0}, {2000, 50}}
+	if len(manifest.Frames) != len(want) {
+		t.Fatalf("unexpected frames: %+v", manifest.Frames)
+	}
+	for i, w := range want {
+		if f := manifest.Frames[i]; f.StartMS != w.start || f.DelayMS != w.delay {
+			t.Fatalf("frame %d timing = %d/%d, want %d/%d", i, f.StartMS, f.DelayMS, w.start, w.delay)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package app
+
+import (
+	"encoding"
+	"errors"
+	"image"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// CropValue parses ImageMagick-style crop geometry: WxH+X+Y (offset optional).
+type CropValue image.Rectangle
+
+var _ encoding.TextUnmarshaler = (*CropValue)(nil)
+
+var reCropGeometry = regexp.MustCompile(`^(\d+)[xX](\d+)(?:\+(\d+)\+(\d+))?$`)
+
+func (c *CropValue) UnmarshalText(text []byte) error {
+	raw := strings.TrimSpace(string(text))
+	if raw == "" {
+		*c = CropValue{}
+		return nil
+	}
+	m := reCropGeometry.FindStringSubmatch(raw)
+	if m == nil {
+		return errors.New("invalid crop (want WxH+X+Y)")
+	}
+	nums := make([]int, 4)
+	for i, s := range m[1:] {
+		if s == "" {
+			continue
+		}
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return errors.New("invalid crop (want WxH+X+Y)")
+		}
+		nums[i] = n
+	}
+	w, h, x, y := nums[0], nums[1], nums[2], nums[3]
+	if w <= 0 || h <= 0 {
+		return errors.New("invalid crop: width and height must be > 0")
+	}
+	*c = CropValue(image.Rect(x, y, x+w, y+h))
+	return nil
+}
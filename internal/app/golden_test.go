@@ -0,0 +1,136 @@
+package app
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/steipete/gifgrep/internal/kitty"
+	"github.com/steipete/gifgrep/internal/model"
+	"github.com/steipete/gifgrep/internal/termcaps"
+)
+
+var update = flag.Bool("update", false, "rewrite testdata/golden files")
+
+// assertGolden compares got against testdata/golden/<name>.golden, or
+// rewrites the file when the test runs with -update.
+func assertGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", "golden", name+".golden")
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatalf("write golden: %v", err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read golden (run with -update to create it): %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("%s mismatch (run with -update to accept)\n got: %q\nwant: %q", path, got, want)
+	}
+}
+
+var goldenResults = []model.Result{
+	{ID: "1", Title: "Cat  typing\tfast", URL: "https://example.test/cat.gif", PreviewURL: "https://example.test/cat-tiny.gif", Width: 200, Height: 100},
+	{ID: "2", Title: "", URL: "https://example.test/untitled.gif", Tags: []string{"dog", "wave"}},
+	{ID: "3", Title: "Markdown [brackets] & tabs", URL: "https://example.test/md.gif?x=1&y=2", Width: 64, Height: 64},
+}
+
+func TestGoldenFormats(t *testing.T) {
+	formats := []outputFormat{formatPlain, formatTSV, formatMD, formatURL, formatComment, formatNDJSON, formatCSV}
+	for _, format := range formats {
+		for _, number := range []bool{false, true} {
+			for _, color := range []bool{false, true} {
+				name := string(format)
+				if number {
+					name += "-number"
+				}
+				if color {
+					name += "-color"
+				}
+				t.Run(name, func(t *testing.T) {
+					var buf bytes.Buffer
+					out := bufio.NewWriter(&buf)
+					writeSearchResults(out, model.Options{Number: number}, color, termcaps.InlineNone, goldenResults, 80, termcaps.CellSize{}, format)
+					_ = out.Flush()
+					assertGolden(t, "format-"+name, buf.Bytes())
+				})
+			}
+		}
+	}
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := writeJSONResults(&buf, goldenResults); err != nil {
+			t.Fatalf("json: %v", err)
+		}
+		assertGolden(t, "format-json", buf.Bytes())
+	})
+}
+
+func TestGoldenNamedTemplates(t *testing.T) {
+	for name := range namedTemplates {
+		t.Run(name, func(t *testing.T) {
+			tmpl, err := parseResultTemplate(name)
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			var buf bytes.Buffer
+			if err := writeTemplateResults(&buf, tmpl, goldenResults, "tenor"); err != nil {
+				t.Fatalf("execute: %v", err)
+			}
+			assertGolden(t, "template-"+name, buf.Bytes())
+		})
+	}
+}
+
+func TestGoldenThumbStreams(t *testing.T) {
+	fixture, err := os.ReadFile(filepath.Join("..", "..", "gifdecode", "testdata", "animexample2.gif"))
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	prevFetch := fetchThumb
+	prevOpts := thumbKittyOptions
+	t.Cleanup(func() {
+		fetchThumb = prevFetch
+		thumbKittyOptions = prevOpts
+	})
+	fetchThumb = func(_ string) ([]byte, error) { return fixture, nil }
+	t.Setenv("GIFGREP_ITERM_MULTIPART", "0")
+
+	cell := termcaps.CellSize{Width: 10, Height: 20}
+	res := model.Result{Title: "Loading spinner", URL: "https://example.test/spinner.gif"}
+	cases := []struct {
+		name   string
+		thumbs termcaps.InlineProtocol
+		opts   kitty.Options
+	}{
+		{name: "kitty", thumbs: termcaps.InlineKitty},
+		{name: "kitty-placeholders", thumbs: termcaps.InlineKitty, opts: kitty.Options{Placeholders: true}},
+		{name: "iterm", thumbs: termcaps.InlineIterm},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			thumbKittyOptions = func() kitty.Options { return tc.opts }
+			var buf bytes.Buffer
+			out := bufio.NewWriter(&buf)
+			if err := renderThumbBlock(out, tc.thumbs, 1, res, "1. ", res.Title, res.URL, true, 80, cell); err != nil {
+				t.Fatalf("render: %v", err)
+			}
+			_ = out.Flush()
+			if !strings.Contains(buf.String(), "Loading spinner") {
+				t.Fatalf("missing title in %q", buf.String())
+			}
+			assertGolden(t, "thumb-"+tc.name, buf.Bytes())
+		})
+	}
+}
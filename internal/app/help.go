@@ -180,6 +180,18 @@ func helpExtras(ctx *kong.Context) []string {
 		return stillHelpExtras()
 	case "sheet":
 		return sheetHelpExtras()
+	case "frames":
+		return framesHelpExtras()
+	case "edit":
+		return editHelpExtras()
+	case "info":
+		return infoHelpExtras()
+	case "config", "get", "set", "path":
+		return configHelpExtras()
+	case "fav", "add", "rm", "ls":
+		return favHelpExtras()
+	case "history":
+		return historyHelpExtras()
 	default:
 		return rootHelpExtras()
 	}
@@ -193,11 +205,18 @@ func rootHelpExtras() []string {
 		"  gifgrep tui cats",
 		"  gifgrep still cat.gif --at 1.5s -o still.png",
 		"  gifgrep sheet cat.gif --frames 12 --cols 4 -o sheet.png",
+		"  gifgrep frames cat.gif --every 2 -o frames/",
+		"  gifgrep edit cat.gif --start 0.5s --end 2s --width 320 -o clip.gif",
+		"  gifgrep info cat.gif",
+		"  gifgrep fav ls favorites",
+		"  gifgrep --profile work cats",
 		"",
 		"Environment:",
 		"  TENOR_API_KEY    optional (defaults to Tenor demo key)",
 		"  GIPHY_API_KEY    required for --source giphy",
 		"  HEYPSTER_API_KEY required for --source heypster",
+		"  GIFGREP_CONFIG   config file (default ~/.config/gifgrep/config.toml)",
+		"  GIFGREP_PROFILE  config profile (same as --profile)",
 	}
 }
 
@@ -205,13 +224,19 @@ func searchHelpExtras() []string {
 	return []string{
 		"Output:",
 		"  Default (--format auto): plain (TTY), url (pipe).",
-		"  Use --format plain|tsv|md|url|comment|json, or --json.",
+		"  Use --format plain|tsv|md|url|comment|json|ndjson|csv, or --json.",
+		"  Use --template '{{.Title}} <{{.URL}}>' (text/template per result), or a named one: slack, html, org, csv.",
 		"  Use --download to save results to ~/Downloads (combine with --reveal).",
+		"  --out-dir, --filename '{source}-{id}-{title}.{ext}' and --collision suffix|overwrite|skip shape downloads.",
+		"  Use --copy url (all URLs) or --copy file (first GIF) to put results on the clipboard.",
 		"",
 		"Examples:",
 		"  gifgrep cats | head -n 5",
 		"  gifgrep cats --download --max 1 --format url",
+		"  gifgrep cats --max 1 --copy file",
+		"  gifgrep cats --template '{{.Index}}. {{.Title}} {{.Width}}x{{.Height}}'",
 		"  gifgrep search --json cats | jq '.[] | .url'",
+		"  gifgrep cats --format ndjson | jq -c .url",
 		"  gifgrep search --source tenor cats",
 		"  GIPHY_API_KEY=... gifgrep search --source giphy cats",
 		"  HEYPSTER_API_KEY=... gifgrep search --source heypster \"star wars\"",
@@ -222,9 +247,16 @@ func tuiHelpExtras() []string {
 	return []string{
 		"Keys:",
 		"  /      edit search",
-		"  ↑↓     select",
+		"  ↑↓     select (while editing: previous searches)",
+		"  Ctrl-R reverse search through previous searches",
 		"  d      download selection",
 		"  f      reveal last download in file manager",
+		"  o      open the result page (or GIF) in the browser",
+		"  u      show the full GIF URL (toggle)",
+		"  s      star/unstar in the collection (--collection, or the one shown)",
+		"  c      browse collections (next collection); search @name opens one (@@ for a literal @)",
+		"  y      copy URL to the clipboard",
+		"  Y      copy the GIF to the clipboard",
 		"  q      quit",
 		"",
 		"Examples:",
@@ -247,3 +279,85 @@ func sheetHelpExtras() []string {
 		"  gifgrep sheet cat.gif --frames 16 --cols 4 --padding 4 -o sheet.png",
 	}
 }
+
+func framesHelpExtras() []string {
+	return []string{
+		"Output:",
+		"  frame_0001.png, frame_0002.png, ... plus manifest.json with each file's",
+		"  source frame, start time and delay (covering skipped frames with --every).",
+		"",
+		"Examples:",
+		"  gifgrep frames cat.gif -o frames/",
+		"  gifgrep frames cat.gif --every 3 --from 0.5s --to 2s -o frames/",
+	}
+}
+
+func editHelpExtras() []string {
+	return []string{
+		"Notes:",
+		"  --crop is applied first, then --width/--height. All frames share one",
+		"  palette; --colors and --no-dither trade quality for file size.",
+		"",
+		"Examples:",
+		"  gifgrep edit cat.gif --start 0.5s --end 2s -o clip.gif",
+		"  gifgrep edit cat.gif --crop 200x200+40+0 --width 128 -o avatar.gif",
+		"  gifgrep edit cat.gif --colors 64 --no-dither -o - > small.gif",
+	}
+}
+
+func configHelpExtras() []string {
+	return []string{
+		"Keys:",
+		"  source, max, format, thumbs, color, rating, download_dir, filename, collision, template",
+		"  templates.<name>, keys.<action>, env.<VAR>, profile (default profile)",
+		"  profiles.<name>.<key> overrides any of the above for --profile <name>",
+		"",
+		"Examples:",
+		"  gifgrep config set source giphy",
+		"  gifgrep config set profiles.work.rating g",
+		"  gifgrep config set keys.download D",
+		"  gifgrep config get profiles.work",
+		"  $EDITOR \"$(gifgrep config path)\"",
+	}
+}
+
+func favHelpExtras() []string {
+	return []string{
+		"Storage:",
+		"  $XDG_DATA_HOME/gifgrep/favorites.json (default ~/.local/share/gifgrep)",
+		"  In the TUI, s stars the selection and c browses collections.",
+		"",
+		"Examples:",
+		"  gifgrep search --json --max 1 cats | gifgrep fav add -",
+		"  gifgrep fav add -c reactions https://media.tenor.com/x/cat.gif --title 'Cat'",
+		"  gifgrep fav ls",
+		"  gifgrep fav ls reactions --format url",
+		"  gifgrep fav rm -c reactions https://media.tenor.com/x/cat.gif",
+	}
+}
+
+func historyHelpExtras() []string {
+	return []string{
+		"Storage:",
+		"  $XDG_STATE_HOME/gifgrep/history.jsonl (default ~/.local/state/gifgrep)",
+		"  TUI searches and searches printed to a terminal are recorded; piped",
+		"  output is not. The newest 1000 are kept.",
+		"",
+		"Examples:",
+		"  gifgrep history --last 20",
+		"  gifgrep history --json | jq -r '.[].query'",
+		"  gifgrep history --clear",
+	}
+}
+
+func infoHelpExtras() []string {
+	return []string{
+		"Output:",
+		"  size, frame count, total duration, per-frame delays, loop count,",
+		"  palette size, transparency and file size. Use --json for scripts.",
+		"",
+		"Examples:",
+		"  gifgrep info cat.gif",
+		"  gifgrep info https://example.com/cat.gif --json | jq .frames",
+	}
+}
@@ -0,0 +1,86 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/alecthomas/kong"
+	"github.com/steipete/gifgrep/internal/history"
+	"github.com/steipete/gifgrep/internal/model"
+	"github.com/steipete/gifgrep/internal/search"
+)
+
+type HistoryCmd struct {
+	Clear bool `help:"Delete the search history."`
+	JSON  bool `help:"Emit JSON."`
+	Last  int  `help:"Show only the newest N searches (0 = all)." default:"0"`
+}
+
+func (c *HistoryCmd) Run(ctx *kong.Context, cli *CLI) error {
+	path, err := history.DefaultPath()
+	if err != nil {
+		return err
+	}
+	if c.Clear {
+		if err := history.Clear(path); err != nil {
+			return err
+		}
+		if !cli.Globals.Quiet {
+			_, _ = fmt.Fprintln(ctx.Stderr, "cleared "+path)
+		}
+		return nil
+	}
+	entries, err := history.Load(path)
+	if err != nil {
+		return err
+	}
+	if c.Last > 0 && len(entries) > c.Last {
+		entries = entries[len(entries)-c.Last:]
+	}
+	return writeHistory(ctx.Stdout, entries, c.JSON)
+}
+
+func writeHistory(stdout io.Writer, entries []history.Entry, asJSON bool) error {
+	if asJSON {
+		if entries == nil {
+			entries = []history.Entry{}
+		}
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(entries)
+	}
+	w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	for _, e := range entries {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%d\t%s\n", e.Time.Local().Format("2006-01-02 15:04"), e.Source, e.Results, strings.Join(strings.Fields(e.Query), " "))
+	}
+	return w.Flush()
+}
+
+// historyPath is where searches are recorded; empty (no recording) when the
+// home directory is unknown.
+func historyPath() string {
+	path, err := history.DefaultPath()
+	if err != nil {
+		return ""
+	}
+	return path
+}
+
+// recordHistory notes a search. History is a convenience, so failures are
+// only logged with --verbose.
+func recordHistory(stderr io.Writer, opts model.Options, query string, results int) {
+	if opts.HistoryPath == "" {
+		return
+	}
+	err := history.Append(opts.HistoryPath, history.Entry{
+		Query:   strings.TrimSpace(query),
+		Source:  search.ResolveSource(opts.Source),
+		Results: results,
+	})
+	if err != nil && opts.Verbose > 0 && !opts.Quiet {
+		_, _ = fmt.Fprintf(stderr, "history: %v\n", err)
+	}
+}
@@ -0,0 +1,58 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/steipete/gifgrep/internal/history"
+	"github.com/steipete/gifgrep/internal/model"
+)
+
+func TestHistoryCommand(t *testing.T) {
+	state := t.TempDir()
+	t.Setenv("XDG_STATE_HOME", state)
+	t.Setenv("GIFGREP_CONFIG", filepath.Join(t.TempDir(), "config.toml"))
+	path := historyPath()
+	if path != filepath.Join(state, "gifgrep", "history.jsonl") {
+		t.Fatalf("path %q", path)
+	}
+
+	recordHistory(&bytes.Buffer{}, model.Options{Source: "tenor", HistoryPath: path}, "  cats  ", 20)
+	recordHistory(&bytes.Buffer{}, model.Options{Source: "giphy", HistoryPath: path}, "office handshake", 3)
+	recordHistory(&bytes.Buffer{}, model.Options{Source: "giphy"}, "not recorded", 1)
+
+	stdout := captureStdout(t, func() {
+		if code := Run([]string{"history"}); code != 0 {
+			t.Fatalf("history exit %d", code)
+		}
+	})
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	if len(lines) != 2 || !strings.HasSuffix(lines[0], "tenor  20  cats") || !strings.HasSuffix(lines[1], "giphy  3   office handshake") {
+		t.Fatalf("history printed %q", stdout)
+	}
+
+	stdout = captureStdout(t, func() {
+		if code := Run([]string{"history", "--json", "--last", "1"}); code != 0 {
+			t.Fatalf("history --json exit %d", code)
+		}
+	})
+	var entries []history.Entry
+	if err := json.Unmarshal([]byte(stdout), &entries); err != nil || len(entries) != 1 || entries[0].Query != "office handshake" {
+		t.Fatalf("history --json: %v %q", err, stdout)
+	}
+
+	if code := Run([]string{"-q", "history", "--clear"}); code != 0 {
+		t.Fatalf("history --clear exit %d", code)
+	}
+	stdout = captureStdout(t, func() {
+		if code := Run([]string{"history", "--json"}); code != 0 {
+			t.Fatalf("history exit %d", code)
+		}
+	})
+	if strings.TrimSpace(stdout) != "[]" {
+		t.Fatalf("after clear %q", stdout)
+	}
+}
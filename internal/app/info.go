@@ -0,0 +1,182 @@
+package app
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/steipete/gifgrep/gifdecode"
+	"github.com/steipete/gifgrep/internal/download"
+	"github.com/steipete/gifgrep/internal/model"
+)
+
+type gifInfo struct {
+	Source        string   `json:"source"`
+	Format        string   `json:"format"`
+	Version       string   `json:"version,omitempty"`
+	Width         int      `json:"width"`
+	Height        int      `json:"height"`
+	Frames        int      `json:"frames"`
+	DurationMS    int64    `json:"duration_ms"`
+	DelaysMS      []int64  `json:"delays_ms"`
+	LoopCount     int      `json:"loop_count"`
+	PaletteSize   int      `json:"palette_size"`
+	LocalPalettes int      `json:"local_palettes"`
+	Transparent   bool     `json:"transparent"`
+	FileSize      int      `json:"file_size"`
+	Comments      []string `json:"comments,omitempty"`
+	// Origin is the provenance gifgrep embedded when downloading.
+	Origin *download.Provenance `json:"origin,omitempty"`
+}
+
+func runInfo(stdout io.Writer, opts model.Options) error {
+	if opts.GifInput == "" {
+		return errors.New("missing GIF input")
+	}
+	data, err := readInput(opts.GifInput)
+	if err != nil {
+		return err
+	}
+	info, err := gifdecode.Inspect(data)
+	if err != nil {
+		return err
+	}
+	out := newGIFInfo(opts.GifInput, data, info)
+	if origin, ok := download.ReadProvenance(opts.GifInput, info.Comments); ok {
+		out.Origin = &origin
+	}
+
+	w := bufio.NewWriter(stdout)
+	defer func() { _ = w.Flush() }()
+	if opts.JSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(out)
+	}
+	writeGIFInfo(w, out)
+	return nil
+}
+
+func newGIFInfo(source string, data []byte, info *gifdecode.Info) gifInfo {
+	delays := make([]int64, 0, len(info.Delays))
+	for _, d := range info.Delays {
+		delays = append(delays, d.Milliseconds())
+	}
+	return gifInfo{
+		Source:        source,
+		Format:        info.Format,
+		Version:       info.Version,
+		Width:         info.Width,
+		Height:        info.Height,
+		Frames:        info.Frames,
+		DurationMS:    info.Duration.Milliseconds(),
+		DelaysMS:      delays,
+		LoopCount:     info.LoopCount,
+		PaletteSize:   info.GlobalPaletteSize,
+		LocalPalettes: info.LocalPalettes,
+		Transparent:   info.Transparent,
+		FileSize:      len(data),
+		Comments:      info.Comments,
+	}
+}
+
+func writeGIFInfo(out *bufio.Writer, info gifInfo) {
+	format := info.Format
+	if info.Version != "" {
+		format += " (" + info.Version + ")"
+	}
+	rows := [][2]string{
+		{"source", info.Source},
+		{"format", format},
+		{"size", fmt.Sprintf("%dx%d", info.Width, info.Height)},
+		{"frames", fmt.Sprintf("%d", info.Frames)},
+		{"duration", formatMillis(info.DurationMS)},
+		{"delays", formatDelays(info.DelaysMS)},
+		{"loop", formatLoopCount(info.LoopCount, info.Frames)},
+		{"palette", formatPalette(info.PaletteSize, info.LocalPalettes)},
+		{"transparent", yesNo(info.Transparent)},
+		{"file size", formatByteSize(int64(info.FileSize))},
+	}
+	if o := info.Origin; o != nil {
+		rows = append(rows,
+			[2]string{"origin", strings.Trim(o.Source+" "+o.ID, " ")},
+			[2]string{"title", o.Title},
+			[2]string{"tags", strings.Join(o.Tags, ", ")},
+			[2]string{"origin url", o.URL},
+		)
+	}
+	for _, c := range info.Comments {
+		if _, ok := download.ParseProvenance(c); !ok {
+			rows = append(rows, [2]string{"comment", strings.Join(strings.Fields(c), " ")})
+		}
+	}
+	for _, row := range rows {
+		if row[1] == "" {
+			continue
+		}
+		_, _ = fmt.Fprintf(out, "%-12s %s\n", row[0]+":", row[1])
+	}
+}
+
+func formatMillis(ms int64) string {
+	return (time.Duration(ms) * time.Millisecond).String()
+}
+
+func formatDelays(delays []int64) string {
+	if len(delays) == 0 {
+		return ""
+	}
+	parts := make([]string, 0, len(delays))
+	for _, d := range delays {
+		parts = append(parts, formatMillis(d))
+	}
+	return strings.Join(parts, " ")
+}
+
+func formatLoopCount(loop, frames int) string {
+	switch {
+	case frames <= 1:
+		return "n/a"
+	case loop == 0:
+		return "forever"
+	case loop < 0:
+		return "once"
+	default:
+		return fmt.Sprintf("%d repeats", loop)
+	}
+}
+
+func formatPalette(global, local int) string {
+	label := "none"
+	if global > 0 {
+		label = fmt.Sprintf("%d colors", global)
+	}
+	if local > 0 {
+		label += fmt.Sprintf(" (+%d local)", local)
+	}
+	return label
+}
+
+func formatByteSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+func yesNo(v bool) string {
+	if v {
+		return "yes"
+	}
+	return "no"
+}
@@ -0,0 +1,117 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/steipete/gifgrep/gifdecode"
+	"github.com/steipete/gifgrep/internal/model"
+	"github.com/steipete/gifgrep/internal/testutil"
+)
+
+func TestRunInfoPlain(t *testing.T) {
+	data := testutil.MakeTestGIF()
+	inPath := filepath.Join(t.TempDir(), "in.gif")
+	if err := os.WriteFile(inPath, data, 0o644); err != nil {
+		t.Fatalf("write input: %v", err)
+	}
+
+	var stdout bytes.Buffer
+	if err := runInfo(&stdout, model.Options{GifInput: inPath}); err != nil {
+		t.Fatalf("runInfo failed: %v", err)
+	}
+	text := stdout.String()
+	for _, want := range []string{"size:        2x2", "frames:      2", "duration:    120ms", "delays:      50ms 70ms", "loop:        forever"} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("missing %q in output: %q", want, text)
+		}
+	}
+}
+
+func TestRunInfoShowsProvenance(t *testing.T) {
+	data, err := gifdecode.AddComment(testutil.MakeTestGIF(), "gifgrep provenance\nsource: tenor\nid: 42\ntitle: Cat typing\ntags: cat, keyboard\nurl: https://example.test/cat.gif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, err = gifdecode.AddComment(data, "Made with\nGIMP"); err != nil {
+		t.Fatal(err)
+	}
+	inPath := filepath.Join(t.TempDir(), "in.gif")
+	if err := os.WriteFile(inPath, data, 0o644); err != nil {
+		t.Fatalf("write input: %v", err)
+	}
+
+	var stdout bytes.Buffer
+	if err := runInfo(&stdout, model.Options{GifInput: inPath}); err != nil {
+		t.Fatalf("runInfo failed: %v", err)
+	}
+	for _, want := range []string{"origin:      tenor 42\n", "title:       Cat typing\n", "tags:        cat, keyboard\n", "origin url:  https://example.test/cat.gif\n", "comment:     Made with GIMP\n"} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Fatalf("missing %q in output: %q", want, stdout.String())
+		}
+	}
+
+	stdout.Reset()
+	if err := runInfo(&stdout, model.Options{GifInput: inPath, JSON: true}); err != nil {
+		t.Fatalf("runInfo json failed: %v", err)
+	}
+	var got gifInfo
+	if err := json.Unmarshal(stdout.Bytes(), &got); err != nil {
+		t.Fatalf("decode json: %v", err)
+	}
+	if got.Origin == nil || got.Origin.ID != "42" || len(got.Comments) != 2 {
+		t.Fatalf("unexpected json %+v", got)
+	}
+}
+
+func TestRunInfoJSONFromURL(t *testing.T) {
+	data := testutil.MakeTestGIF()
+	testutil.WithTransport(t, &testutil.FakeTransport{GIFData: data}, func() {
+		var stdout bytes.Buffer
+		err := runInfo(&stdout, model.Options{GifInput: "https://example.test/full.gif", JSON: true})
+		if err != nil {
+			t.Fatalf("runInfo failed: %v", err)
+		}
+		var got gifInfo
+		if err := json.Unmarshal(stdout.Bytes(), &got); err != nil {
+			t.Fatalf("json decode: %v", err)
+		}
+		if got.Frames != 2 || got.Width != 2 || got.Height != 2 {
+			t.Fatalf("unexpected info: %+v", got)
+		}
+		if got.FileSize != len(data) || got.DurationMS != 120 || len(got.DelaysMS) != 2 {
+			t.Fatalf("unexpected timing/size: %+v", got)
+		}
+	})
+}
+
+func TestRunInfoErrors(t *testing.T) {
+	if err := runInfo(&bytes.Buffer{}, model.Options{}); err == nil {
+		t.Fatalf("expected missing input error")
+	}
+	inPath := filepath.Join(t.TempDir(), "bad.gif")
+	if err := os.WriteFile(inPath, []byte("nope"), 0o644); err != nil {
+		t.Fatalf("write input: %v", err)
+	}
+	if err := runInfo(&bytes.Buffer{}, model.Options{GifInput: inPath}); err == nil {
+		t.Fatalf("expected decode error")
+	}
+}
+
+func TestFormatLoopCount(t *testing.T) {
+	cases := map[[2]int]string{
+		{0, 3}:  "forever",
+		{-1, 3}: "once",
+		{2, 3}:  "2 repeats",
+		{0, 1}:  "n/a",
+	}
+	for in, want := range cases {
+		if got := formatLoopCount(in[0], in[1]); got != want {
+			t.Fatalf("formatLoopCount(%d, %d) = %q, want %q", in[0], in[1], got, want)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestMain keeps Run away from the developer's own files: an empty config
+// (a real one can rebind keys or fail to parse) and temporary data and state
+// directories for collections and history.
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "gifgrep-app-test-")
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	cfg := filepath.Join(dir, "config.toml")
+	if err := os.WriteFile(cfg, nil, 0o600); err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	_ = os.Setenv("GIFGREP_CONFIG", cfg)
+	_ = os.Unsetenv("GIFGREP_PROFILE")
+	_ = os.Setenv("XDG_DATA_HOME", filepath.Join(dir, "data"))
+	_ = os.Setenv("XDG_STATE_HOME", filepath.Join(dir, "state"))
+	code := m.Run()
+	_ = os.RemoveAll(dir)
+	os.Exit(code)
+}
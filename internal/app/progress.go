@@ -0,0 +1,180 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/steipete/gifgrep/internal/download"
+	"github.com/steipete/gifgrep/internal/model"
+)
+
+// meterInterval throttles redraws caused by byte progress.
+const meterInterval = 100 * time.Millisecond
+
+// downloadMeter reports a download batch on stderr. On a terminal it keeps a
+// live block of one line per active file plus a total line; otherwise it
+// only logs finished files when verbose.
+type downloadMeter struct {
+	mu      sync.Mutex
+	w       io.Writer
+	live    bool
+	verbose bool
+	now     func() time.Time
+
+	total      int
+	finished   int
+	doneBytes  int64
+	active     []*meterFile
+	drawnLines int
+	lastDraw   time.Time
+}
+
+type meterFile struct {
+	index int
+	name  string
+	done  int64
+	size  int64
+}
+
+func newDownloadMeter(w io.Writer, total int, live, verbose bool) *downloadMeter {
+	return &downloadMeter{w: w, total: total, live: live, verbose: verbose, now: time.Now}
+}
+
+func (m *downloadMeter) Start(i int, item model.Result) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.active = append(m.active, &meterFile{index: i, name: normalizeTitle(item), size: -1})
+	m.drawLocked()
+}
+
+func (m *downloadMeter) Progress(i int, done, total int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, f := range m.active {
+		if f.index == i {
+			f.done, f.size = done, total
+		}
+	}
+	if m.now().Sub(m.lastDraw) >= meterInterval {
+		m.drawLocked()
+	}
+}
+
+func (m *downloadMeter) Done(i int, outcome download.Outcome) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for n, f := range m.active {
+		if f.index == i {
+			if outcome.Err == nil {
+				m.doneBytes += f.done
+			}
+			m.active = append(m.active[:n], m.active[n+1:]...)
+			break
+		}
+	}
+	m.finished++
+	if line := savedLine(outcome.Saved); outcome.Err == nil && (m.verbose || outcome.Saved.Duplicate != "") {
+		m.clearLocked()
+		_, _ = fmt.Fprintln(m.w, line)
+	}
+	m.drawLocked()
+}
+
+// finish removes the live block.
+func (m *downloadMeter) finish() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.clearLocked()
+}
+
+func (m *downloadMeter) drawLocked() {
+	if !m.live {
+		return
+	}
+	var b strings.Builder
+	if m.drawnLines > 0 {
+		fmt.Fprintf(&b, "\x1b[%dF\x1b[J", m.drawnLines)
+	}
+	sum := m.doneBytes
+	for _, f := range m.active {
+		sum += f.done
+		fmt.Fprintf(&b, "  %s  %s\n", truncateRunes(f.name, 40), meterSize(f.done, f.size))
+	}
+	fmt.Fprintf(&b, "downloading %d/%d · %s\n", m.finished, m.total, formatByteSize(sum))
+	_, _ = io.WriteString(m.w, b.String())
+	m.drawnLines = len(m.active) + 1
+	m.lastDraw = m.now()
+}
+
+func (m *downloadMeter) clearLocked() {
+	if m.live && m.drawnLines > 0 {
+		_, _ = fmt.Fprintf(m.w, "\x1b[%dF\x1b[J", m.drawnLines)
+		m.drawnLines = 0
+	}
+}
+
+// savedLine describes where a download ended up; duplicates are always
+// worth a line since no new file appeared.
+func savedLine(saved download.Saved) string {
+	switch {
+	case saved.Duplicate != "" && saved.Path != saved.Duplicate:
+		return fmt.Sprintf("already saved at %s (linked %s)", saved.Duplicate, saved.Path)
+	case saved.Duplicate != "":
+		return "already saved at " + saved.Duplicate
+	case saved.Skipped:
+		return "exists " + saved.Path
+	default:
+		return "saved " + saved.Path
+	}
+}
+
+func meterSize(done, size int64) string {
+	if size <= 0 {
+		return formatByteSize(done)
+	}
+	return fmt.Sprintf("%3d%%  %s / %s", done*100/size, formatByteSize(done), formatByteSize(size))
+}
+
+func truncateRunes(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
+
+// writeDownloadSummary prints counts for the batch and one line per failure.
+func writeDownloadSummary(w io.Writer, outcomes []download.Outcome) {
+	var saved, duplicates, skipped, failed int
+	for _, o := range outcomes {
+		switch {
+		case o.Err != nil:
+			failed++
+		case o.Saved.Duplicate != "":
+			duplicates++
+		case o.Saved.Skipped:
+			skipped++
+		default:
+			saved++
+		}
+	}
+	summary := fmt.Sprintf("downloaded %d", saved)
+	if duplicates > 0 {
+		summary += fmt.Sprintf(", duplicates %d", duplicates)
+	}
+	if skipped > 0 {
+		summary += fmt.Sprintf(", skipped %d", skipped)
+	}
+	if failed > 0 {
+		summary += fmt.Sprintf(", failed %d", failed)
+	}
+	_, _ = fmt.Fprintln(w, summary)
+	for _, o := range outcomes {
+		if o.Err != nil {
+			_, _ = fmt.Fprintf(w, "failed %s: %v\n", normalizeTitle(o.Item), o.Err)
+		}
+	}
+}
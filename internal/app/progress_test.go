@@ -0,0 +1,112 @@
+package app
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steipete/gifgrep/internal/download"
+	"github.com/steipete/gifgrep/internal/model"
+)
+
+func TestDownloadMeterLiveBlock(t *testing.T) {
+	var buf bytes.Buffer
+	m := newDownloadMeter(&buf, 2, true, false)
+	clock := time.Unix(0, 0)
+	m.now = func() time.Time { return clock }
+
+	m.Start(0, model.Result{Title: "Cat"})
+	m.Start(1, model.Result{Title: "Dog"})
+	clock = clock.Add(time.Second)
+	m.Progress(0, 512, 2048)
+	if !strings.HasSuffix(buf.String(), "\x1b[3F\x1b[J  Cat   25%  512 B / 2.0 KiB\n  Dog  0 B\ndownloading 0/2 · 512 B\n") {
+		t.Fatalf("unexpected block %q", buf.String())
+	}
+
+	buf.Reset()
+	m.Progress(0, 1024, 2048)
+	if buf.Len() != 0 {
+		t.Fatalf("expected throttled redraw, got %q", buf.String())
+	}
+
+	m.Done(0, download.Outcome{Saved: download.Saved{Path: "/tmp/Cat.gif"}})
+	if !strings.HasSuffix(buf.String(), "  Dog  0 B\ndownloading 1/2 · 1.0 KiB\n") {
+		t.Fatalf("unexpected block after done %q", buf.String())
+	}
+
+	buf.Reset()
+	m.finish()
+	if buf.String() != "\x1b[2F\x1b[J" {
+		t.Fatalf("finish = %q", buf.String())
+	}
+}
+
+func TestDownloadMeterPlainLogsWhenVerbose(t *testing.T) {
+	var buf bytes.Buffer
+	m := newDownloadMeter(&buf, 2, false, true)
+	m.Start(0, model.Result{Title: "Cat"})
+	m.Progress(0, 10, 10)
+	m.Done(0, download.Outcome{Saved: download.Saved{Path: "/tmp/Cat.gif"}})
+	m.Done(1, download.Outcome{Saved: download.Saved{Path: "/tmp/Dog.gif", Skipped: true}})
+	m.finish()
+	if buf.String() != "saved /tmp/Cat.gif\nexists /tmp/Dog.gif\n" {
+		t.Fatalf("unexpected output %q", buf.String())
+	}
+}
+
+func TestDownloadMeterReportsDuplicates(t *testing.T) {
+	var buf bytes.Buffer
+	m := newDownloadMeter(&buf, 3, false, false)
+	m.Done(0, download.Outcome{Saved: download.Saved{Path: "/d/new.gif"}})
+	m.Done(1, download.Outcome{Saved: download.Saved{Path: "/d/old.gif", Skipped: true, Duplicate: "/d/old.gif"}})
+	m.Done(2, download.Outcome{Saved: download.Saved{Path: "/d/link.gif", Duplicate: "/d/old.gif"}})
+	want := "already saved at /d/old.gif\nalready saved at /d/old.gif (linked /d/link.gif)\n"
+	if buf.String() != want {
+		t.Fatalf("output = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestWriteDownloadSummary(t *testing.T) {
+	var buf bytes.Buffer
+	writeDownloadSummary(&buf, []download.Outcome{
+		{Saved: download.Saved{Path: "a"}},
+		{Saved: download.Saved{Path: "b", Skipped: true}},
+		{Saved: download.Saved{Path: "c", Skipped: true, Duplicate: "c"}},
+		{Item: model.Result{Title: "Broken"}, Err: errors.New("http 404")},
+	})
+	want := "downloaded 1, duplicates 1, skipped 1, failed 1\nfailed Broken: http 404\n"
+	if buf.String() != want {
+		t.Fatalf("summary = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestDownloadSearchResultsReportsFailuresWithoutStopping(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ok.gif", func(w http.ResponseWriter, _ *http.Request) { _, _ = w.Write([]byte("GIF89a")) })
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	dir := t.TempDir()
+	results := []model.Result{
+		{ID: "1", Title: "Missing", URL: srv.URL + "/missing.gif"},
+		{ID: "2", Title: "Fine", URL: srv.URL + "/ok.gif"},
+	}
+	opts := model.Options{Download: true, DownloadDir: dir, DownloadJobs: 2}
+	var stderr bytes.Buffer
+	err := downloadSearchResults(results, opts, &stderr)
+	if err == nil || err.Error() != "1 of 2 downloads failed" {
+		t.Fatalf("err = %v", err)
+	}
+	if !strings.Contains(stderr.String(), "downloaded 1, failed 1\nfailed Missing: http 404\n") {
+		t.Fatalf("unexpected stderr %q", stderr.String())
+	}
+	if _, statErr := os.Stat(filepath.Join(dir, "Fine.gif")); statErr != nil {
+		t.Fatalf("Fine.gif not saved: %v", statErr)
+	}
+}
@@ -20,17 +20,8 @@ func Run(args []string) int {
 	}
 
 	cli := &CLI{}
-	parser, err := kong.New(cli,
-		kong.Name(model.AppName),
-		kong.Vars{"version": model.AppName + " " + model.Version},
-		kong.Help(helpPrinter),
-		kong.ConfigureHelp(kong.HelpOptions{
-			WrapUpperBound: 100,
-		}),
-		kong.Exit(func(code int) {
-			panic(exitPanic{code: code})
-		}),
-	)
+	cli.loadConfig()
+	parser, err := newParser(cli)
 	if err != nil {
 		_, _ = fmt.Fprintln(os.Stderr, err.Error())
 		return 1
@@ -49,7 +40,7 @@ func Run(args []string) int {
 	ctx, err := parseWithExit(parser, args)
 	if err != nil {
 		var parseErr *kong.ParseError
-		if errors.As(err, &parseErr) {
+		if errors.As(err, &parseErr) && !isConfigError(err) {
 			_ = parseErr.Context.PrintUsage(true)
 		}
 		_, _ = fmt.Fprintln(os.Stderr, err.Error())
@@ -66,6 +57,21 @@ func Run(args []string) int {
 	return 0
 }
 
+func newParser(cli *CLI) (*kong.Kong, error) {
+	return kong.New(cli,
+		kong.Name(model.AppName),
+		kong.Vars{"version": model.AppName + " " + model.Version},
+		kong.Help(helpPrinter),
+		kong.ConfigureHelp(kong.HelpOptions{
+			WrapUpperBound: 100,
+		}),
+		kong.Exit(func(code int) {
+			panic(exitPanic{code: code})
+		}),
+		kong.Resolvers(configResolver(cli)),
+	)
+}
+
 func parseWithExit(parser *kong.Kong, args []string) (ctx *kong.Context, err error) {
 	defer func() {
 		if r := recover(); r != nil {
@@ -106,6 +112,8 @@ func rewriteCommandAliases(args []string) []string {
 		switch strings.ToLower(strings.TrimSpace(arg)) {
 		case "contact-sheet", "contactsheet", "stills":
 			out[i] = "sheet"
+		case "inspect":
+			out[i] = "info"
 		}
 		return out
 	}
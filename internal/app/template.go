@@ -0,0 +1,100 @@
+package app
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+
+	"github.com/steipete/gifgrep/internal/model"
+)
+
+// namedTemplates are the built-in templates selectable by name via
+// --template <name>.
+var namedTemplates = map[string]string{
+	"slack": `<{{.URL}}|{{.Title}}>`,
+	"html":  `<a href="{{.URL | html}}"><img src="{{.URL | html}}" alt="{{.Title | html}}"{{if .Width}} width="{{.Width}}" height="{{.Height}}"{{end}}></a>`,
+	"org":   `- [[{{.URL}}][{{.Title}}]]`,
+	"csv":   `{{csv .Index .Title .URL .Width .Height .Provider}}`,
+}
+
+// templateResult is what --template sees for each result. Title shadows the
+// raw provider title with the normalized one used by every other format.
+type templateResult struct {
+	model.Result
+	Title    string
+	Index    int
+	Provider string
+}
+
+var templateFuncs = template.FuncMap{
+	"csv":   templateCSV,
+	"json":  templateJSON,
+	"join":  strings.Join,
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+}
+
+// parseResultTemplate resolves a template name (built-in) or parses spec as
+// text/template source.
+func parseResultTemplate(spec string) (*template.Template, error) {
+	name := strings.TrimSpace(spec)
+	if named, ok := namedTemplates[strings.ToLower(name)]; ok {
+		spec = named
+	}
+	tmpl, err := template.New("result").Funcs(templateFuncs).Parse(spec)
+	if err != nil {
+		return nil, fmt.Errorf("template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// writeTemplateResults executes tmpl once per result, one line each.
+func writeTemplateResults(out io.Writer, tmpl *template.Template, results []model.Result, provider string) error {
+	var line bytes.Buffer
+	for i, res := range results {
+		line.Reset()
+		data := templateResult{
+			Result:   res,
+			Title:    normalizeTitle(res),
+			Index:    i + 1,
+			Provider: provider,
+		}
+		if err := tmpl.Execute(&line, data); err != nil {
+			return fmt.Errorf("template: %w", err)
+		}
+		if !bytes.HasSuffix(line.Bytes(), []byte("\n")) {
+			line.WriteByte('\n')
+		}
+		if _, err := out.Write(line.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// templateCSV renders its arguments as one CSV record (no trailing newline).
+func templateCSV(fields ...any) (string, error) {
+	record := make([]string, len(fields))
+	for i, f := range fields {
+		record[i] = fmt.Sprint(f)
+	}
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(record); err != nil {
+		return "", err
+	}
+	w.Flush()
+	return strings.TrimSuffix(buf.String(), "\n"), w.Error()
+}
+
+func templateJSON(v any) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
@@ -0,0 +1,88 @@
+package app
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/steipete/gifgrep/internal/model"
+	"github.com/steipete/gifgrep/internal/testutil"
+)
+
+func TestWriteTemplateResults(t *testing.T) {
+	tmpl, err := parseResultTemplate(`{{.Index}} {{.Title}} <{{.URL}}> {{.Width}}x{{.Height}} {{.Provider}} {{join .Tags ","}}`)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	var buf bytes.Buffer
+	err = writeTemplateResults(&buf, tmpl, []model.Result{
+		{Title: "  Cat\ttyping ", URL: "https://example.test/a.gif", Width: 200, Height: 100, Tags: []string{"cat", "fun"}},
+		{ID: "x2", URL: "https://example.test/b.gif"},
+	}, "giphy")
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	want := "1 Cat typing <https://example.test/a.gif> 200x100 giphy cat,fun\n" +
+		"2 x2 <https://example.test/b.gif> 0x0 giphy \n"
+	if buf.String() != want {
+		t.Fatalf("got %q, want %q", buf.String(), want)
+	}
+}
+
+func TestWriteTemplateResultsKeepsTrailingNewline(t *testing.T) {
+	tmpl, err := parseResultTemplate("{{.URL}}\n")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := writeTemplateResults(&buf, tmpl, []model.Result{{URL: "u"}}, "tenor"); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if buf.String() != "u\n" {
+		t.Fatalf("got %q", buf.String())
+	}
+}
+
+func TestParseResultTemplateErrors(t *testing.T) {
+	if _, err := parseResultTemplate("{{.Title"); err == nil || !strings.HasPrefix(err.Error(), "template:") {
+		t.Fatalf("expected parse error, got %v", err)
+	}
+	tmpl, err := parseResultTemplate("{{.Nope}}")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if err := writeTemplateResults(&bytes.Buffer{}, tmpl, []model.Result{{}}, "tenor"); err == nil {
+		t.Fatalf("expected error for unknown field")
+	}
+}
+
+func TestTemplateCSVQuotes(t *testing.T) {
+	got, err := templateCSV(1, `say "hi", ok`, "plain")
+	if err != nil {
+		t.Fatalf("csv: %v", err)
+	}
+	if got != `1,"say ""hi"", ok",plain` {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestRunSearchTemplate(t *testing.T) {
+	testutil.WithTransport(t, &testutil.FakeTransport{GIFData: testutil.MakeTestGIF()}, func() {
+		var stdout, stderr bytes.Buffer
+		err := runSearch(&stdout, &stderr, model.Options{Template: "org", Limit: 1, Source: "tenor", Format: "json"}, "cats")
+		if err != nil {
+			t.Fatalf("runSearch failed: %v", err)
+		}
+		if got := stdout.String(); got != "- [[https://example.test/full.gif][Cat One]]\n" {
+			t.Fatalf("unexpected output %q", got)
+		}
+	})
+}
+
+func TestRunSearchTemplateParseErrorBeforeSearch(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	err := runSearch(&stdout, &stderr, model.Options{Template: "{{", Source: "tenor"}, "cats")
+	if err == nil || !strings.Contains(err.Error(), "template") {
+		t.Fatalf("expected template error, got %v", err)
+	}
+}
@@ -0,0 +1,353 @@
+package blocks
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"strings"
+
+	"github.com/steipete/gifgrep/internal/termcaps"
+)
+
+type Mode int
+
+const (
+	// ModeHalf draws two pixels per cell with ▀/▄ and fg/bg colors.
+	ModeHalf Mode = iota
+	// ModeQuadrant draws 2×2 pixels per cell with quadrant glyphs, two colors
+	// per cell.
+	ModeQuadrant
+	// ModeBraille draws 2×4 dots per cell in a single color; sharpest shapes,
+	// least color.
+	ModeBraille
+)
+
+func (m Mode) String() string {
+	switch m {
+	case ModeHalf:
+		return "half"
+	case ModeQuadrant:
+		return "quadrant"
+	case ModeBraille:
+		return "braille"
+	default:
+		return "half"
+	}
+}
+
+func ParseMode(s string) (Mode, bool) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "half", "halfblock":
+		return ModeHalf, true
+	case "quad", "quadrant":
+		return ModeQuadrant, true
+	case "braille":
+		return ModeBraille, true
+	default:
+		return ModeHalf, false
+	}
+}
+
+type Options struct {
+	Mode Mode
+	// TrueColor emits 24-bit SGR colors; otherwise the xterm 256-color palette.
+	TrueColor bool
+}
+
+// OptionsFromEnv picks the style from GIFGREP_BLOCKS=half|quadrant|braille
+// and uses 24-bit color when the terminal advertises it.
+func OptionsFromEnv(getenv func(string) string) Options {
+	mode, _ := ParseMode(getenv("GIFGREP_BLOCKS"))
+	return Options{Mode: mode, TrueColor: termcaps.DetectTruecolor(getenv)}
+}
+
+type rgb struct{ r, g, b int }
+
+// px is a sampled pixel; ok is false when it is mostly transparent.
+type px struct {
+	c  rgb
+	ok bool
+}
+
+// Render draws img stretched to cols×rows cells and returns one string per
+// row. Every row ends with an SGR reset, and transparent areas use the
+// terminal's default background.
+func Render(img image.Image, cols, rows int, opts Options) []string {
+	if img == nil || img.Bounds().Empty() || cols <= 0 || rows <= 0 {
+		return nil
+	}
+	sx, sy := 1, 2
+	switch opts.Mode {
+	case ModeQuadrant:
+		sx, sy = 2, 2
+	case ModeBraille:
+		sx, sy = 2, 4
+	case ModeHalf:
+	}
+	w, h := cols*sx, rows*sy
+	grid := sample(img, w, h)
+
+	lines := make([]string, 0, rows)
+	cell := make([]px, sx*sy)
+	for row := 0; row < rows; row++ {
+		var b strings.Builder
+		pen := pen{trueColor: opts.TrueColor}
+		for col := 0; col < cols; col++ {
+			for dy := 0; dy < sy; dy++ {
+				for dx := 0; dx < sx; dx++ {
+					cell[dy*sx+dx] = grid[(row*sy+dy)*w+col*sx+dx]
+				}
+			}
+			switch opts.Mode {
+			case ModeQuadrant:
+				pen.quadrant(&b, cell)
+			case ModeBraille:
+				pen.braille(&b, cell)
+			default:
+				pen.half(&b, cell[0], cell[1])
+			}
+		}
+		b.WriteString("\x1b[0m")
+		lines = append(lines, b.String())
+	}
+	return lines
+}
+
+// sample box-averages img down (or nearest-neighbours it up) to w×h pixels.
+func sample(img image.Image, w, h int) []px {
+	b := img.Bounds()
+	src, ok := img.(*image.RGBA)
+	if !ok {
+		src = image.NewRGBA(b)
+		draw.Draw(src, b, img, b.Min, draw.Src)
+	}
+	out := make([]px, w*h)
+	for y := 0; y < h; y++ {
+		y0 := b.Min.Y + y*b.Dy()/h
+		y1 := max(y0+1, b.Min.Y+(y+1)*b.Dy()/h)
+		for x := 0; x < w; x++ {
+			x0 := b.Min.X + x*b.Dx()/w
+			x1 := max(x0+1, b.Min.X+(x+1)*b.Dx()/w)
+			var r, g, bl, a, n int
+			for yy := y0; yy < y1; yy++ {
+				off := src.PixOffset(x0, yy)
+				for xx := x0; xx < x1; xx++ {
+					r += int(src.Pix[off])
+					g += int(src.Pix[off+1])
+					bl += int(src.Pix[off+2])
+					a += int(src.Pix[off+3])
+					n++
+					off += 4
+				}
+			}
+			if a*2 < n*255 {
+				continue
+			}
+			// Pix is premultiplied; dividing by total alpha unpremultiplies
+			// the average.
+			out[y*w+x] = px{c: rgb{r * 255 / a, g * 255 / a, bl * 255 / a}, ok: true}
+		}
+	}
+	return out
+}
+
+// pen tracks the current SGR colors so runs of equal cells don't repeat
+// escapes.
+type pen struct {
+	trueColor bool
+	fg, bg    string
+}
+
+func (p *pen) set(b *strings.Builder, fg, bg string) {
+	if fg == p.fg && bg == p.bg {
+		return
+	}
+	b.WriteString("\x1b[")
+	if fg == "" || bg == "" {
+		// Going back to a default color needs the reset-style codes.
+		b.WriteString("0")
+		if fg != "" {
+			b.WriteString(";" + fg)
+		}
+		if bg != "" {
+			b.WriteString(";" + bg)
+		}
+	} else {
+		b.WriteString(fg + ";" + bg)
+	}
+	b.WriteString("m")
+	p.fg, p.bg = fg, bg
+}
+
+func (p *pen) color(c rgb, base int) string {
+	if p.trueColor {
+		return fmt.Sprintf("%d;2;%d;%d;%d", base, c.r, c.g, c.b)
+	}
+	return fmt.Sprintf("%d;5;%d", base, xterm256(c))
+}
+
+func (p *pen) half(b *strings.Builder, top, bottom px) {
+	switch {
+	case top.ok && bottom.ok:
+		p.set(b, p.color(top.c, 38), p.color(bottom.c, 48))
+		b.WriteString("▀")
+	case top.ok:
+		p.set(b, p.color(top.c, 38), "")
+		b.WriteString("▀")
+	case bottom.ok:
+		p.set(b, p.color(bottom.c, 38), "")
+		b.WriteString("▄")
+	default:
+		p.set(b, "", "")
+		b.WriteString(" ")
+	}
+}
+
+// quadrantGlyphs is indexed by a mask of lit quarters: TL=1, TR=2, BL=4, BR=8.
+var quadrantGlyphs = []rune(" ▘▝▀▖▌▞▛▗▚▐▜▄▙▟█")
+
+func (p *pen) quadrant(b *strings.Builder, cell []px) {
+	fg, bg, mask, hasBG := split(cell)
+	if mask == 0 {
+		if !hasBG {
+			p.set(b, "", "")
+			b.WriteString(" ")
+			return
+		}
+		mask, fg, hasBG = 15, bg, false
+	}
+	bgCode := ""
+	if hasBG {
+		bgCode = p.color(bg, 48)
+	}
+	p.set(b, p.color(fg, 38), bgCode)
+	b.WriteRune(quadrantGlyphs[mask])
+}
+
+// split partitions the opaque pixels of a cell into a bright (fg) and a dark
+// (bg) group. Transparent pixels always belong to the default background.
+func split(cell []px) (fg, bg rgb, mask int, hasBG bool) {
+	lo, hi := -1, -1
+	opaque := 0
+	for i, p := range cell {
+		if !p.ok {
+			continue
+		}
+		opaque++
+		if lo < 0 || luma(p.c) < luma(cell[lo].c) {
+			lo = i
+		}
+		if hi < 0 || luma(p.c) > luma(cell[hi].c) {
+			hi = i
+		}
+	}
+	if opaque == 0 {
+		return rgb{}, rgb{}, 0, false
+	}
+	if opaque < len(cell) {
+		// The default background takes the transparent pixels, so all opaque
+		// pixels share the foreground.
+		for i, p := range cell {
+			if p.ok {
+				mask |= 1 << i
+			}
+		}
+		return average(cell, mask), rgb{}, mask, false
+	}
+	for i, p := range cell {
+		if dist(p.c, cell[hi].c) <= dist(p.c, cell[lo].c) {
+			mask |= 1 << i
+		}
+	}
+	bgMask := (1<<len(cell) - 1) &^ mask
+	if bgMask == 0 {
+		return average(cell, mask), rgb{}, mask, false
+	}
+	return average(cell, mask), average(cell, bgMask), mask, true
+}
+
+// brailleDots maps 2×4 cell positions (row-major) to braille dot bits.
+var brailleDots = [8]int{0x01, 0x08, 0x02, 0x10, 0x04, 0x20, 0x40, 0x80}
+
+func (p *pen) braille(b *strings.Builder, cell []px) {
+	var sum, n int
+	for _, c := range cell {
+		if c.ok {
+			sum += luma(c.c)
+			n++
+		}
+	}
+	if n == 0 {
+		p.set(b, "", "")
+		b.WriteString(" ")
+		return
+	}
+	mean := sum / n
+	mask, dots := 0, 0
+	for i, c := range cell {
+		if c.ok && luma(c.c) >= mean {
+			mask |= 1 << i
+			dots |= brailleDots[i]
+		}
+	}
+	p.set(b, p.color(average(cell, mask), 38), "")
+	b.WriteRune(rune(0x2800 + dots))
+}
+
+func average(cell []px, mask int) rgb {
+	var r, g, b, n int
+	for i, p := range cell {
+		if mask&(1<<i) == 0 || !p.ok {
+			continue
+		}
+		r += p.c.r
+		g += p.c.g
+		b += p.c.b
+		n++
+	}
+	if n == 0 {
+		return rgb{}
+	}
+	return rgb{r / n, g / n, b / n}
+}
+
+func luma(c rgb) int {
+	return (299*c.r + 587*c.g + 114*c.b) / 1000
+}
+
+func dist(a, b rgb) int {
+	dr, dg, db := a.r-b.r, a.g-b.g, a.b-b.b
+	return dr*dr + dg*dg + db*db
+}
+
+var cubeLevels = [6]int{0, 95, 135, 175, 215, 255}
+
+// xterm256 picks the nearest entry from the 6×6×6 cube (16-231) or the gray
+// ramp (232-255).
+func xterm256(c rgb) int {
+	level := func(v int) int {
+		best := 0
+		for i, l := range cubeLevels {
+			if abs(v-l) < abs(v-cubeLevels[best]) {
+				best = i
+			}
+		}
+		return best
+	}
+	r, g, b := level(c.r), level(c.g), level(c.b)
+	cube := rgb{cubeLevels[r], cubeLevels[g], cubeLevels[b]}
+
+	gray := min(23, max(0, ((c.r+c.g+c.b)/3-8+5)/10))
+	gv := 8 + gray*10
+	if dist(c, rgb{gv, gv, gv}) < dist(c, cube) {
+		return 232 + gray
+	}
+	return 16 + 36*r + 6*g + b
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
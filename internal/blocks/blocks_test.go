@@ -0,0 +1,114 @@
+package blocks
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+func twoTone(top, bottom color.Color) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	img.Set(0, 0, top)
+	img.Set(1, 0, top)
+	img.Set(0, 1, bottom)
+	img.Set(1, 1, bottom)
+	return img
+}
+
+func TestRenderHalfTrueColor(t *testing.T) {
+	red := color.RGBA{R: 0xff, A: 0xff}
+	blue := color.RGBA{B: 0xff, A: 0xff}
+	lines := Render(twoTone(red, blue), 2, 1, Options{TrueColor: true})
+	if len(lines) != 1 {
+		t.Fatalf("expected 1 line, got %d", len(lines))
+	}
+	want := "\x1b[38;2;255;0;0;48;2;0;0;255m▀▀\x1b[0m"
+	if lines[0] != want {
+		t.Fatalf("unexpected line %q", lines[0])
+	}
+}
+
+func TestRenderHalfTransparency(t *testing.T) {
+	green := color.RGBA{G: 0xff, A: 0xff}
+	lines := Render(twoTone(color.Transparent, green), 1, 1, Options{})
+	if lines[0] != "\x1b[0;38;5;46m▄\x1b[0m" {
+		t.Fatalf("unexpected bottom-only cell %q", lines[0])
+	}
+	lines = Render(twoTone(color.Transparent, color.Transparent), 1, 1, Options{})
+	if lines[0] != " \x1b[0m" {
+		t.Fatalf("unexpected empty cell %q", lines[0])
+	}
+}
+
+func TestRenderQuadrant(t *testing.T) {
+	img := twoTone(color.White, color.Black)
+	lines := Render(img, 1, 1, Options{Mode: ModeQuadrant, TrueColor: true})
+	if lines[0] != "\x1b[38;2;255;255;255;48;2;0;0;0m▀\x1b[0m" {
+		t.Fatalf("unexpected quadrant cell %q", lines[0])
+	}
+	img.Set(1, 1, color.Transparent)
+	lines = Render(img, 1, 1, Options{Mode: ModeQuadrant, TrueColor: true})
+	if !strings.HasSuffix(lines[0], "▛\x1b[0m") || strings.Contains(lines[0], "48;2") {
+		t.Fatalf("expected fg-only quadrant over default background: %q", lines[0])
+	}
+}
+
+func TestRenderBraille(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 2, 4))
+	for y := 0; y < 4; y++ {
+		img.Set(0, y, color.White)
+		img.Set(1, y, color.Black)
+	}
+	lines := Render(img, 1, 1, Options{Mode: ModeBraille})
+	// Left column lit: dots 1, 2, 3 and 7.
+	if !strings.Contains(lines[0], string(rune(0x2800+0x01+0x02+0x04+0x40))) {
+		t.Fatalf("unexpected braille cell %q", lines[0])
+	}
+}
+
+func TestRenderEmpty(t *testing.T) {
+	if Render(nil, 2, 2, Options{}) != nil {
+		t.Fatalf("expected nil for nil image")
+	}
+	if Render(twoTone(color.White, color.White), 0, 2, Options{}) != nil {
+		t.Fatalf("expected nil for zero size")
+	}
+}
+
+func TestXterm256(t *testing.T) {
+	cases := []struct {
+		c    rgb
+		want int
+	}{
+		{rgb{0, 0, 0}, 16},
+		{rgb{255, 255, 255}, 231},
+		{rgb{255, 0, 0}, 196},
+		{rgb{128, 128, 128}, 244},
+	}
+	for _, tc := range cases {
+		if got := xterm256(tc.c); got != tc.want {
+			t.Fatalf("xterm256(%v) = %d, want %d", tc.c, got, tc.want)
+		}
+	}
+}
+
+func TestParseMode(t *testing.T) {
+	if m, ok := ParseMode("Quad"); !ok || m != ModeQuadrant {
+		t.Fatalf("expected quadrant")
+	}
+	if m, ok := ParseMode(""); !ok || m != ModeHalf {
+		t.Fatalf("expected half default")
+	}
+	if _, ok := ParseMode("sixel"); ok {
+		t.Fatalf("expected unknown mode")
+	}
+}
+
+func TestOptionsFromEnv(t *testing.T) {
+	env := map[string]string{"GIFGREP_BLOCKS": "braille", "COLORTERM": "truecolor"}
+	opts := OptionsFromEnv(func(k string) string { return env[k] })
+	if opts.Mode != ModeBraille || !opts.TrueColor {
+		t.Fatalf("unexpected options %+v", opts)
+	}
+}
@@ -0,0 +1,128 @@
+// Package clipboard copies text and GIF files to the clipboard: text through
+// the terminal (OSC 52) and the platform's clipboard tool, files through the
+// tool only.
+package clipboard
+
+import (
+	"encoding/base64"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+var (
+	execCommand = exec.Command
+	lookPath    = exec.LookPath
+	getenv      = os.Getenv
+)
+
+// CopyText puts s on the clipboard. It writes an OSC 52 sequence to tty (nil
+// skips it), which also works over SSH, and runs the platform tool when one
+// is installed; it only fails when neither was possible.
+func CopyText(tty io.Writer, s string) error {
+	sent := false
+	if tty != nil {
+		_, err := io.WriteString(tty, OSC52(s))
+		sent = err == nil
+	}
+	cmd, args, err := commandForText(runtime.GOOS)
+	if err == nil {
+		err = run(cmd, args, strings.NewReader(s))
+	}
+	if sent {
+		return nil
+	}
+	return err
+}
+
+// CopyFile puts the GIF at path on the clipboard as image data.
+func CopyFile(path string) error {
+	if path == "" {
+		return errors.New("empty path")
+	}
+	abs, err := filepath.Abs(path)
+	if err == nil {
+		path = abs
+	}
+	cmd, args, stdin, err := commandForFile(runtime.GOOS, path)
+	if err != nil {
+		return err
+	}
+	var in io.Reader
+	if stdin {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = f.Close() }()
+		in = f
+	}
+	return run(cmd, args, in)
+}
+
+// OSC52 is the escape sequence that sets the terminal's clipboard to s.
+func OSC52(s string) string {
+	return "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte(s)) + "\a"
+}
+
+func run(cmd string, args []string, stdin io.Reader) error {
+	c := execCommand(cmd, args...)
+	c.Stdin = stdin
+	// No output pipes: xclip and wl-copy fork to keep serving the selection,
+	// and an inherited pipe would block Run until they exit.
+	c.Stdout = nil
+	c.Stderr = nil
+	return c.Run()
+}
+
+func commandForText(goos string) (string, []string, error) {
+	switch goos {
+	case "darwin":
+		return "pbcopy", nil, nil
+	case "windows":
+		return "clip.exe", nil, nil
+	default:
+		if getenv("WAYLAND_DISPLAY") != "" {
+			if _, err := lookPath("wl-copy"); err == nil {
+				return "wl-copy", nil, nil
+			}
+		}
+		if _, err := lookPath("xclip"); err == nil {
+			return "xclip", []string{"-selection", "clipboard"}, nil
+		}
+		if _, err := lookPath("xsel"); err == nil {
+			return "xsel", []string{"--clipboard", "--input"}, nil
+		}
+		return "", nil, errors.New("no clipboard tool found (need wl-copy, xclip or xsel)")
+	}
+}
+
+// commandForFile returns the command that copies the GIF at path; stdin
+// reports whether it reads the file from standard input.
+func commandForFile(goos, path string) (string, []string, bool, error) {
+	switch goos {
+	case "darwin":
+		script := `set the clipboard to (read (POSIX file "` + appleScriptEscape(path) + `") as «class GIFf»)`
+		return "osascript", []string{"-e", script}, false, nil
+	case "windows":
+		return "powershell.exe", []string{"-NoProfile", "-Command", "Set-Clipboard -Path '" + strings.ReplaceAll(path, "'", "''") + "'"}, false, nil
+	default:
+		if getenv("WAYLAND_DISPLAY") != "" {
+			if _, err := lookPath("wl-copy"); err == nil {
+				return "wl-copy", []string{"--type", "image/gif"}, true, nil
+			}
+		}
+		if _, err := lookPath("xclip"); err == nil {
+			return "xclip", []string{"-selection", "clipboard", "-t", "image/gif", "-i"}, true, nil
+		}
+		return "", nil, false, errors.New("no clipboard tool for images found (need wl-copy or xclip)")
+	}
+}
+
+func appleScriptEscape(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, `\`, `\\`), `"`, `\"`)
+}
@@ -0,0 +1,102 @@
+package clipboard
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func stubTools(t *testing.T, wayland string, installed ...string) {
+	t.Helper()
+	prevLook, prevEnv := lookPath, getenv
+	lookPath = func(name string) (string, error) {
+		for _, have := range installed {
+			if have == name {
+				return "/usr/bin/" + name, nil
+			}
+		}
+		return "", errors.New("not found")
+	}
+	getenv = func(key string) string {
+		if key == "WAYLAND_DISPLAY" {
+			return wayland
+		}
+		return ""
+	}
+	t.Cleanup(func() { lookPath, getenv = prevLook, prevEnv })
+}
+
+func TestOSC52(t *testing.T) {
+	if got := OSC52("https://x.test/a.gif"); got != "\x1b]52;c;aHR0cHM6Ly94LnRlc3QvYS5naWY=\a" {
+		t.Fatalf("OSC52 = %q", got)
+	}
+}
+
+func TestCommandForTextLinux(t *testing.T) {
+	stubTools(t, "wayland-0", "wl-copy", "xclip")
+	if cmd, _, err := commandForText("linux"); err != nil || cmd != "wl-copy" {
+		t.Fatalf("wayland: %q %v", cmd, err)
+	}
+
+	stubTools(t, "", "wl-copy", "xclip")
+	if cmd, args, err := commandForText("linux"); err != nil || cmd != "xclip" || strings.Join(args, " ") != "-selection clipboard" {
+		t.Fatalf("x11: %q %v %v", cmd, args, err)
+	}
+
+	stubTools(t, "", "xsel")
+	if cmd, _, err := commandForText("linux"); err != nil || cmd != "xsel" {
+		t.Fatalf("xsel: %q %v", cmd, err)
+	}
+
+	stubTools(t, "")
+	if _, _, err := commandForText("linux"); err == nil {
+		t.Fatalf("expected missing tool error")
+	}
+}
+
+func TestCommandForTextPlatforms(t *testing.T) {
+	if cmd, _, _ := commandForText("darwin"); cmd != "pbcopy" {
+		t.Fatalf("darwin: %q", cmd)
+	}
+	if cmd, _, _ := commandForText("windows"); cmd != "clip.exe" {
+		t.Fatalf("windows: %q", cmd)
+	}
+}
+
+func TestCommandForFile(t *testing.T) {
+	stubTools(t, "", "xclip", "xsel")
+	cmd, args, stdin, err := commandForFile("linux", "/tmp/a.gif")
+	if err != nil || cmd != "xclip" || !stdin || !strings.Contains(strings.Join(args, " "), "-t image/gif") {
+		t.Fatalf("linux: %q %v %v %v", cmd, args, stdin, err)
+	}
+
+	stubTools(t, "", "xsel")
+	if _, _, _, err := commandForFile("linux", "/tmp/a.gif"); err == nil {
+		t.Fatalf("xsel cannot copy images; expected an error")
+	}
+
+	cmd, args, stdin, err = commandForFile("darwin", `/tmp/say "hi".gif`)
+	if err != nil || cmd != "osascript" || stdin || !strings.Contains(args[1], `POSIX file "/tmp/say \"hi\".gif"`) {
+		t.Fatalf("darwin: %q %v %v %v", cmd, args, stdin, err)
+	}
+
+	cmd, args, _, _ = commandForFile("windows", `C:\it's.gif`)
+	if cmd != "powershell.exe" || !strings.HasSuffix(args[len(args)-1], `'C:\it''s.gif'`) {
+		t.Fatalf("windows: %q %v", cmd, args)
+	}
+}
+
+func TestCopyTextSucceedsWithOSC52Only(t *testing.T) {
+	stubTools(t, "")
+	var tty bytes.Buffer
+	if err := CopyText(&tty, "hi"); err != nil {
+		t.Fatalf("CopyText: %v", err)
+	}
+	if tty.String() != OSC52("hi") {
+		t.Fatalf("tty = %q", tty.String())
+	}
+	if err := CopyText(nil, "hi"); err == nil {
+		t.Fatalf("expected an error without a terminal or tool")
+	}
+}
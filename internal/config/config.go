@@ -0,0 +1,513 @@
+// Package config loads gifgrep's config.toml: defaults for CLI flags plus
+// named profiles that override them.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Settings are the preferences one config layer (the file's top level or a
+// profile) can set. Zero values mean "not set".
+type Settings struct {
+	Source      string
+	Max         int
+	Format      string
+	Thumbs      string
+	Color       string
+	Rating      string
+	DownloadDir string
+	Filename    string
+	Collision   string
+	Dedup       string
+	Metadata    bool
+	Template    string
+	// Templates are named --template bodies.
+	Templates map[string]string
+	// Keys rebinds TUI actions, e.g. download = "D".
+	Keys map[string]string
+	// Env provides environment variables (GIFGREP_*, API keys) that are not
+	// already set.
+	Env map[string]string
+}
+
+// File is a loaded config file.
+type File struct {
+	Path string
+	// Profile is the profile used when --profile is not given.
+	Profile  string
+	Base     Settings
+	Profiles map[string]Settings
+
+	raw map[string]any
+}
+
+type kind int
+
+const (
+	kindString kind = iota
+	kindInt
+	kindBool
+	kindTable
+)
+
+var settingKinds = map[string]kind{
+	"source":       kindString,
+	"max":          kindInt,
+	"format":       kindString,
+	"thumbs":       kindString,
+	"color":        kindString,
+	"rating":       kindString,
+	"download_dir": kindString,
+	"filename":     kindString,
+	"collision":    kindString,
+	"dedup":        kindString,
+	"metadata":     kindBool,
+	"template":     kindString,
+	"templates":    kindTable,
+	"keys":         kindTable,
+	"env":          kindTable,
+}
+
+// settingChoices are the allowed values of string settings that map to enum
+// flags; they must match the flags' enum tags (checked in the app tests).
+var settingChoices = map[string][]string{
+	"source":    {"auto", "tenor", "giphy", "heypster"},
+	"format":    {"auto", "plain", "tsv", "md", "url", "comment", "json", "ndjson", "csv"},
+	"thumbs":    {"auto", "always", "never"},
+	"color":     {"auto", "always", "never"},
+	"rating":    {"auto", "g", "pg", "pg-13", "r"},
+	"collision": {"suffix", "overwrite", "skip"},
+	"dedup":     {"off", "skip", "link"},
+}
+
+// Choices returns the allowed values for a setting, or nil when any string
+// goes.
+func Choices(key string) []string {
+	return settingChoices[key]
+}
+
+// checkChoice rejects a value outside the setting's choices.
+func checkChoice(name, key, value string) error {
+	choices, ok := settingChoices[key]
+	if !ok {
+		return nil
+	}
+	for _, c := range choices {
+		if value == c {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s: %q is not one of %s", name, value, strings.Join(choices, ", "))
+}
+
+// DefaultPath returns $GIFGREP_CONFIG, else config.toml under
+// $XDG_CONFIG_HOME/gifgrep, else ~/.config/gifgrep.
+func DefaultPath() (string, error) {
+	if p := strings.TrimSpace(os.Getenv("GIFGREP_CONFIG")); p != "" {
+		return p, nil
+	}
+	if dir := strings.TrimSpace(os.Getenv("XDG_CONFIG_HOME")); dir != "" {
+		return filepath.Join(dir, "gifgrep", "config.toml"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "gifgrep", "config.toml"), nil
+}
+
+// Load reads and validates the config at path. A missing file is an empty
+// config.
+func Load(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &File{Path: path, raw: map[string]any{}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	f, err := decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	f.Path = path
+	return f, nil
+}
+
+func decode(data []byte) (*File, error) {
+	raw, err := parse(data)
+	if err != nil {
+		return nil, err
+	}
+	f := &File{Profiles: map[string]Settings{}, raw: raw}
+	top := map[string]any{}
+	for key, v := range raw {
+		switch key {
+		case "profile":
+			s, ok := v.(string)
+			if !ok {
+				return nil, fmt.Errorf("profile: expected a string")
+			}
+			f.Profile = s
+		case "profiles":
+			profiles, ok := v.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("profiles: expected a table")
+			}
+			for name, pv := range profiles {
+				table, ok := pv.(map[string]any)
+				if !ok {
+					return nil, fmt.Errorf("profiles.%s: expected a table", name)
+				}
+				s, err := decodeSettings(table, "profiles."+name+".")
+				if err != nil {
+					return nil, err
+				}
+				f.Profiles[name] = s
+			}
+		default:
+			top[key] = v
+		}
+	}
+	if f.Base, err = decodeSettings(top, ""); err != nil {
+		return nil, err
+	}
+	if f.Profile != "" {
+		if _, ok := f.Profiles[f.Profile]; !ok {
+			return nil, fmt.Errorf("profile: unknown profile %q", f.Profile)
+		}
+	}
+	return f, nil
+}
+
+func decodeSettings(table map[string]any, prefix string) (Settings, error) {
+	var s Settings
+	for key, v := range table {
+		k, ok := settingKinds[key]
+		if !ok {
+			return s, fmt.Errorf("%s%s: unknown key", prefix, key)
+		}
+		switch k {
+		case kindInt:
+			n, ok := v.(int64)
+			if !ok || n < 0 {
+				return s, fmt.Errorf("%s%s: expected a non-negative integer", prefix, key)
+			}
+			s.Max = int(n)
+		case kindBool:
+			b, ok := v.(bool)
+			if !ok {
+				return s, fmt.Errorf("%s%s: expected true or false", prefix, key)
+			}
+			s.Metadata = b
+		case kindString:
+			str, ok := v.(string)
+			if !ok {
+				return s, fmt.Errorf("%s%s: expected a string", prefix, key)
+			}
+			if err := checkChoice(prefix+key, key, str); err != nil {
+				return s, err
+			}
+			switch key {
+			case "source":
+				s.Source = str
+			case "format":
+				s.Format = str
+			case "thumbs":
+				s.Thumbs = str
+			case "color":
+				s.Color = str
+			case "rating":
+				s.Rating = str
+			case "download_dir":
+				s.DownloadDir = expandHome(str)
+			case "filename":
+				s.Filename = str
+			case "collision":
+				s.Collision = str
+			case "dedup":
+				s.Dedup = str
+			case "template":
+				s.Template = str
+			}
+		case kindTable:
+			m, err := decodeStringTable(v, prefix+key, key == "env")
+			if err != nil {
+				return s, err
+			}
+			switch key {
+			case "templates":
+				s.Templates = m
+			case "keys":
+				s.Keys = m
+			case "env":
+				s.Env = m
+			}
+		}
+	}
+	return s, nil
+}
+
+// decodeStringTable reads a table of strings; scalars allows numbers and
+// booleans too (formatted), which suits environment values.
+func decodeStringTable(v any, name string, scalars bool) (map[string]string, error) {
+	table, ok := v.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%s: expected a table", name)
+	}
+	out := make(map[string]string, len(table))
+	for key, tv := range table {
+		switch val := tv.(type) {
+		case string:
+			out[key] = val
+		case int64, float64, bool:
+			if !scalars {
+				return nil, fmt.Errorf("%s.%s: expected a string", name, key)
+			}
+			out[key] = formatScalar(val)
+		default:
+			return nil, fmt.Errorf("%s.%s: expected a string", name, key)
+		}
+	}
+	return out, nil
+}
+
+// Resolve layers the named profile (or the file's default profile when name
+// is empty) over the top-level settings.
+func (f *File) Resolve(name string) (Settings, error) {
+	if name == "" {
+		name = f.Profile
+	}
+	s := f.Base
+	s.Templates = mergeMaps(nil, s.Templates)
+	s.Keys = mergeMaps(nil, s.Keys)
+	s.Env = mergeMaps(nil, s.Env)
+	if name == "" {
+		return s, nil
+	}
+	p, ok := f.Profiles[name]
+	if !ok {
+		return Settings{}, fmt.Errorf("unknown profile %q%s", name, f.profileHint())
+	}
+	if p.Source != "" {
+		s.Source = p.Source
+	}
+	if p.Max != 0 {
+		s.Max = p.Max
+	}
+	if p.Format != "" {
+		s.Format = p.Format
+	}
+	if p.Thumbs != "" {
+		s.Thumbs = p.Thumbs
+	}
+	if p.Color != "" {
+		s.Color = p.Color
+	}
+	if p.Rating != "" {
+		s.Rating = p.Rating
+	}
+	if p.DownloadDir != "" {
+		s.DownloadDir = p.DownloadDir
+	}
+	if p.Filename != "" {
+		s.Filename = p.Filename
+	}
+	if p.Collision != "" {
+		s.Collision = p.Collision
+	}
+	if p.Dedup != "" {
+		s.Dedup = p.Dedup
+	}
+	if p.Metadata {
+		s.Metadata = true
+	}
+	if p.Template != "" {
+		s.Template = p.Template
+	}
+	s.Templates = mergeMaps(s.Templates, p.Templates)
+	s.Keys = mergeMaps(s.Keys, p.Keys)
+	s.Env = mergeMaps(s.Env, p.Env)
+	return s, nil
+}
+
+func (f *File) profileHint() string {
+	if len(f.Profiles) == 0 {
+		return " (no profiles in " + f.Path + ")"
+	}
+	names := make([]string, 0, len(f.Profiles))
+	for name := range f.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return " (have: " + strings.Join(names, ", ") + ")"
+}
+
+// LookupTemplate returns the named template body from the config, or name
+// itself.
+func (s Settings) LookupTemplate(name string) string {
+	if body, ok := s.Templates[name]; ok {
+		return body
+	}
+	return name
+}
+
+// Get returns the value at a dotted key such as "source" or
+// "profiles.work.max". Tables print as sorted key = value lines.
+func (f *File) Get(key string) (string, bool) {
+	var v any = f.raw
+	for _, part := range strings.Split(key, ".") {
+		table, ok := v.(map[string]any)
+		if !ok {
+			return "", false
+		}
+		if v, ok = table[part]; !ok {
+			return "", false
+		}
+	}
+	table, ok := v.(map[string]any)
+	if !ok {
+		return formatScalar(v), true
+	}
+	var lines []string
+	keys := make([]string, 0, len(table))
+	for k := range table {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if _, nested := table[k].(map[string]any); nested {
+			lines = append(lines, "["+formatKey([]string{k})+"]")
+			continue
+		}
+		lines = append(lines, formatKey([]string{k})+" = "+encodeScalar(table[k]))
+	}
+	return strings.Join(lines, "\n"), true
+}
+
+// Set writes key = value into the config at path, creating the file if
+// needed and leaving comments and other entries untouched. The result must
+// still be a valid config, so enum settings only take their flag's values.
+func Set(path, key, value string) error {
+	parts := strings.Split(key, ".")
+	k, err := keyKind(parts)
+	if err != nil {
+		return err
+	}
+	encoded := quote(value)
+	if k == kindInt {
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || n < 0 {
+			return fmt.Errorf("%s: expected a non-negative integer", key)
+		}
+		encoded = strconv.Itoa(n)
+	}
+	if k == kindBool {
+		b, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("%s: expected true or false", key)
+		}
+		encoded = strconv.FormatBool(b)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if _, err := decode(data); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	updated := setLine(data, parts, encoded)
+	if _, err := decode(updated); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	// May hold API keys.
+	return os.WriteFile(path, updated, 0o600)
+}
+
+// keyKind validates a dotted key for Set.
+func keyKind(parts []string) (kind, error) {
+	key := strings.Join(parts, ".")
+	for _, p := range parts {
+		if p == "" {
+			return 0, fmt.Errorf("invalid key %q", key)
+		}
+	}
+	if len(parts) == 1 && parts[0] == "profile" {
+		return kindString, nil
+	}
+	if parts[0] == "profiles" {
+		if len(parts) < 3 || parts[2] == "profile" || parts[2] == "profiles" {
+			return 0, fmt.Errorf("invalid key %q (use profiles.<name>.<setting>)", key)
+		}
+		parts = parts[2:]
+	}
+	k, ok := settingKinds[parts[0]]
+	switch {
+	case !ok:
+		return 0, fmt.Errorf("unknown key %q", key)
+	case k == kindTable && len(parts) == 2:
+		return kindString, nil
+	case k == kindTable:
+		return 0, fmt.Errorf("invalid key %q (use %s.<name>)", key, parts[0])
+	case len(parts) != 1:
+		return 0, fmt.Errorf("invalid key %q", key)
+	}
+	return k, nil
+}
+
+func mergeMaps(dst, src map[string]string) map[string]string {
+	if len(src) == 0 {
+		return dst
+	}
+	if dst == nil {
+		dst = make(map[string]string, len(src))
+	}
+	for k, v := range src {
+		dst[k] = v
+	}
+	return dst
+}
+
+func formatScalar(v any) string {
+	switch val := v.(type) {
+	case string:
+		return val
+	case int64:
+		return strconv.FormatInt(val, 10)
+	case float64:
+		return strconv.FormatFloat(val, 'g', -1, 64)
+	case bool:
+		return strconv.FormatBool(val)
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+func encodeScalar(v any) string {
+	if s, ok := v.(string); ok {
+		return quote(s)
+	}
+	return formatScalar(v)
+}
+
+func expandHome(p string) string {
+	if p != "~" && !strings.HasPrefix(p, "~/") {
+		return p
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return p
+	}
+	return filepath.Join(home, strings.TrimPrefix(p, "~"))
+}
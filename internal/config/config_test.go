@@ -0,0 +1,199 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const sample = `profile = "work"
+source = "giphy"
+max = 30
+download_dir = "~/gifs"
+
+[templates]
+chat = "{{.Title}}: {{.URL}}"
+
+[keys]
+download = "D"
+
+[env]
+GIFGREP_CELL_ASPECT = 0.5
+
+[profiles.work]
+source = "tenor"
+rating = "g"
+
+[profiles.work.keys]
+reveal = "R"
+
+[profiles.home]
+max = 5
+`
+
+func writeConfig(t *testing.T, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	return path
+}
+
+func TestLoadAndResolveProfiles(t *testing.T) {
+	t.Setenv("HOME", "/home/tester")
+	f, err := Load(writeConfig(t, sample))
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+
+	s, err := f.Resolve("")
+	if err != nil {
+		t.Fatalf("resolve default: %v", err)
+	}
+	if s.Source != "tenor" || s.Rating != "g" || s.Max != 30 {
+		t.Fatalf("default profile not applied: %+v", s)
+	}
+	if s.DownloadDir != "/home/tester/gifs" {
+		t.Fatalf("download dir: %q", s.DownloadDir)
+	}
+	if s.Keys["download"] != "D" || s.Keys["reveal"] != "R" {
+		t.Fatalf("keys not merged: %v", s.Keys)
+	}
+	if s.Env["GIFGREP_CELL_ASPECT"] != "0.5" {
+		t.Fatalf("env: %v", s.Env)
+	}
+	if s.LookupTemplate("chat") != "{{.Title}}: {{.URL}}" || s.LookupTemplate("slack") != "slack" {
+		t.Fatalf("template lookup")
+	}
+
+	home, err := f.Resolve("home")
+	if err != nil {
+		t.Fatalf("resolve home: %v", err)
+	}
+	if home.Source != "giphy" || home.Max != 5 || home.Keys["reveal"] != "" {
+		t.Fatalf("home profile: %+v", home)
+	}
+	if f.Base.Keys["reveal"] != "" {
+		t.Fatalf("resolve mutated the base keys")
+	}
+
+	if _, err := f.Resolve("nope"); err == nil || !strings.Contains(err.Error(), "have: home, work") {
+		t.Fatalf("expected unknown profile error, got %v", err)
+	}
+}
+
+func TestLoadMissingFileIsEmpty(t *testing.T) {
+	f, err := Load(filepath.Join(t.TempDir(), "missing.toml"))
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	s, err := f.Resolve("")
+	if err != nil || s.Source != "" {
+		t.Fatalf("expected empty settings, got %+v %v", s, err)
+	}
+}
+
+func TestLoadRejectsUnknownKeysAndTypes(t *testing.T) {
+	for _, body := range []string{
+		"sauce = \"tenor\"\n",
+		"max = \"ten\"\n",
+		"[profiles.work]\nbogus = 1\n",
+		"[keys]\ndownload = 1\n",
+		"metadata = \"yes\"\n",
+		"format = \"yaml\"\n",
+		"[profiles.work]\ndedup = \"hardlink\"\n",
+		"profile = \"missing\"\n",
+	} {
+		if _, err := Load(writeConfig(t, body)); err == nil {
+			t.Fatalf("expected error for %q", body)
+		}
+	}
+}
+
+func TestLoadMultiLineTemplate(t *testing.T) {
+	path := writeConfig(t, "template = \"\"\"\n{{.Title}}\n{{.URL}}\"\"\"\n")
+	f, err := Load(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if f.Base.Template != "{{.Title}}\n{{.URL}}" {
+		t.Fatalf("template %q", f.Base.Template)
+	}
+	if err := Set(path, "template", "{{.URL}}"); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "template = \"{{.URL}}\"\n" {
+		t.Fatalf("multi-line value not replaced: %q", data)
+	}
+}
+
+func TestGet(t *testing.T) {
+	f, err := Load(writeConfig(t, sample))
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if v, ok := f.Get("profiles.work.source"); !ok || v != "tenor" {
+		t.Fatalf("get scalar: %q %v", v, ok)
+	}
+	if v, ok := f.Get("max"); !ok || v != "30" {
+		t.Fatalf("get int: %q %v", v, ok)
+	}
+	if v, ok := f.Get("profiles.work"); !ok || v != "[keys]\nrating = \"g\"\nsource = \"tenor\"" {
+		t.Fatalf("get table: %q %v", v, ok)
+	}
+	if _, ok := f.Get("profiles.nope.source"); ok {
+		t.Fatalf("expected missing key")
+	}
+}
+
+func TestSet(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "config.toml")
+	if err := Set(path, "source", "giphy"); err != nil {
+		t.Fatalf("set source: %v", err)
+	}
+	if err := Set(path, "profiles.work.max", "12"); err != nil {
+		t.Fatalf("set profile max: %v", err)
+	}
+	if err := Set(path, "templates.chat", `{{.Title}} "{{.URL}}"`); err != nil {
+		t.Fatalf("set template: %v", err)
+	}
+	if err := Set(path, "templates.source", "{{.Source}}"); err != nil {
+		t.Fatalf("set template named like a setting: %v", err)
+	}
+	if err := Set(path, "metadata", "TRUE"); err != nil {
+		t.Fatalf("set metadata: %v", err)
+	}
+	f, err := Load(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	s, err := f.Resolve("work")
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if s.Source != "giphy" || s.Max != 12 || s.Templates["chat"] != `{{.Title}} "{{.URL}}"` || !s.Metadata {
+		t.Fatalf("unexpected settings %+v", s)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o600 {
+		t.Fatalf("expected 0600 config, got %v %v", info.Mode(), err)
+	}
+
+	for key, value := range map[string]string{
+		"sauce":               "x",
+		"max":                 "ten",
+		"metadata":            "sometimes",
+		"templates":           "x",
+		"profiles.work":       "x",
+		"profiles.w.profile":  "x",
+		"profile":             "missing",
+		"format":              "yaml",
+		"collision":           "rename",
+		"profiles.work.dedup": "hardlink",
+	} {
+		if err := Set(path, key, value); err == nil {
+			t.Fatalf("expected error setting %s=%s", key, value)
+		}
+	}
+}
@@ -0,0 +1,289 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/BurntSushi/toml"
+)
+
+// parse decodes a TOML document into nested maps: tables are map[string]any,
+// integers int64, floats float64. Errors name the line.
+func parse(data []byte) (map[string]any, error) {
+	raw := map[string]any{}
+	if _, err := toml.Decode(string(data), &raw); err != nil {
+		var perr toml.ParseError
+		if errors.As(err, &perr) {
+			return nil, fmt.Errorf("line %d: %s", perr.Position.Line, perr.Message)
+		}
+		return nil, err
+	}
+	return raw, nil
+}
+
+// parseHeader and parseKey read the [table] and key parts of a line for
+// setLine, which edits the file as text to keep comments and layout.
+func parseHeader(line string) ([]string, error) {
+	if strings.HasPrefix(line, "[[") {
+		return nil, fmt.Errorf("arrays of tables are not supported")
+	}
+	path, rest, err := parseKey(line[1:])
+	if err != nil {
+		return nil, err
+	}
+	rest = strings.TrimSpace(rest)
+	if !strings.HasPrefix(rest, "]") {
+		return nil, fmt.Errorf("expected ] after table name")
+	}
+	if err := expectEnd(rest[1:]); err != nil {
+		return nil, err
+	}
+	return path, nil
+}
+
+// parseKey reads a (possibly dotted) key and returns what follows it.
+func parseKey(s string) ([]string, string, error) {
+	var path []string
+	for {
+		s = strings.TrimLeft(s, " \t")
+		var part string
+		var err error
+		switch {
+		case strings.HasPrefix(s, `"`):
+			part, s, err = parseBasicString(s)
+		case strings.HasPrefix(s, "'"):
+			part, s, err = parseLiteralString(s)
+		default:
+			n := 0
+			for n < len(s) && isBareKeyByte(s[n]) {
+				n++
+			}
+			if n == 0 {
+				return nil, "", fmt.Errorf("expected a key")
+			}
+			part, s = s[:n], s[n:]
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		path = append(path, part)
+		s = strings.TrimLeft(s, " \t")
+		if !strings.HasPrefix(s, ".") {
+			return path, s, nil
+		}
+		s = s[1:]
+	}
+}
+
+func isBareKeyByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-'
+}
+
+func parseBasicString(s string) (string, string, error) {
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		switch c {
+		case '"':
+			return b.String(), s[i+1:], nil
+		case '\\':
+			if i+1 >= len(s) {
+				return "", "", fmt.Errorf("unterminated string")
+			}
+			i++
+			switch s[i] {
+			case '"', '\\':
+				b.WriteByte(s[i])
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case 'r':
+				b.WriteByte('\r')
+			case 'b':
+				b.WriteByte('\b')
+			case 'f':
+				b.WriteByte('\f')
+			case 'e':
+				b.WriteByte(0x1b)
+			case 'u', 'U':
+				size := 4
+				if s[i] == 'U' {
+					size = 8
+				}
+				if i+size >= len(s) {
+					return "", "", fmt.Errorf("short unicode escape")
+				}
+				r, err := strconv.ParseUint(s[i+1:i+1+size], 16, 32)
+				if err != nil || !utf8.ValidRune(rune(r)) {
+					return "", "", fmt.Errorf("invalid unicode escape")
+				}
+				b.WriteRune(rune(r))
+				i += size
+			default:
+				return "", "", fmt.Errorf("invalid escape \\%c", s[i])
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", "", fmt.Errorf("unterminated string")
+}
+
+func parseLiteralString(s string) (string, string, error) {
+	end := strings.IndexByte(s[1:], '\'')
+	if end < 0 {
+		return "", "", fmt.Errorf("unterminated string")
+	}
+	return s[1 : end+1], s[end+2:], nil
+}
+
+func expectEnd(rest string) error {
+	rest = strings.TrimSpace(rest)
+	if rest != "" && !strings.HasPrefix(rest, "#") {
+		return fmt.Errorf("unexpected %q", rest)
+	}
+	return nil
+}
+
+// setLine rewrites the key = value line for path in data, keeping every other
+// line (and comment) as is. A missing key is appended to its table, and a
+// missing table is appended to the file.
+func setLine(data []byte, path []string, value string) []byte {
+	table, key := path[:len(path)-1], path[len(path)-1]
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(data) == 0 {
+		lines = nil
+	}
+
+	var current []string
+	insertAt := -1
+	firstHeader := -1
+	skip := -1
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if i <= skip || trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if strings.HasPrefix(trimmed, "[") {
+			p, err := parseHeader(trimmed)
+			if err != nil {
+				continue
+			}
+			if firstHeader < 0 {
+				firstHeader = i
+			}
+			current = p
+			if equalPath(current, table) {
+				insertAt = i + 1
+			}
+			continue
+		}
+		p, _, err := parseKey(trimmed)
+		if err != nil {
+			continue
+		}
+		end := valueEnd(lines, i)
+		full := append(append([]string{}, current...), p...)
+		if equalPath(full, path) {
+			indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+			lines = append(lines[:i], append([]string{indent + formatKey(p) + " = " + value}, lines[end+1:]...)...)
+			return []byte(strings.Join(lines, "\n") + "\n")
+		}
+		if equalPath(current, table) {
+			insertAt = end + 1
+		}
+		skip = end
+	}
+
+	kv := formatKey([]string{key}) + " = " + value
+	switch {
+	case insertAt >= 0:
+		lines = append(lines[:insertAt], append([]string{kv}, lines[insertAt:]...)...)
+	case len(table) == 0 && firstHeader >= 0:
+		lines = append(lines[:firstHeader], append([]string{kv, ""}, lines[firstHeader:]...)...)
+	case len(table) == 0:
+		lines = append(lines, kv)
+	default:
+		if len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) != "" {
+			lines = append(lines, "")
+		}
+		lines = append(lines, "["+formatKey(table)+"]", kv)
+	}
+	return []byte(strings.Join(lines, "\n") + "\n")
+}
+
+// valueEnd returns the last line of the key = value pair starting at line i;
+// multi-line strings, arrays and inline tables span several. Lines inside
+// them are never mistaken for keys or headers.
+func valueEnd(lines []string, i int) int {
+	for j := i; j < len(lines); j++ {
+		var v map[string]any
+		if _, err := toml.Decode(strings.Join(lines[i:j+1], "\n"), &v); err == nil {
+			return j
+		}
+	}
+	return i
+}
+
+func equalPath(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func formatKey(path []string) string {
+	parts := make([]string, len(path))
+	for i, p := range path {
+		bare := p != ""
+		for j := 0; j < len(p); j++ {
+			if !isBareKeyByte(p[j]) {
+				bare = false
+				break
+			}
+		}
+		if bare {
+			parts[i] = p
+		} else {
+			parts[i] = quote(p)
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+// quote encodes s as a TOML basic string.
+func quote(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\r':
+			b.WriteString(`\r`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\u%04X`, r)
+				continue
+			}
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
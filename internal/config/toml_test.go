@@ -0,0 +1,105 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	raw, err := parse([]byte(`# comment
+source = "giphy" # trailing
+max = 1_000
+ratio = 0.5
+on = true
+'quoted key' = 'C:\path'
+a.b = "dotted"
+
+[profiles.work]
+esc = "tab\there \"q\" \u00e9"
+`))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if raw["source"] != "giphy" || raw["max"] != int64(1000) || raw["ratio"] != 0.5 || raw["on"] != true {
+		t.Fatalf("unexpected scalars: %#v", raw)
+	}
+	if raw["quoted key"] != `C:\path` {
+		t.Fatalf("literal string: %#v", raw["quoted key"])
+	}
+	if raw["a"].(map[string]any)["b"] != "dotted" {
+		t.Fatalf("dotted key: %#v", raw["a"])
+	}
+	work := raw["profiles"].(map[string]any)["work"].(map[string]any)
+	if work["esc"] != "tab\there \"q\" é" {
+		t.Fatalf("escapes: %q", work["esc"])
+	}
+
+	raw, err = parse([]byte("chat = \"\"\"\n{{.Title}}\n[{{.URL}}]\n\"\"\"\nlist = [1, 2]\n[keys]\ninline = { a = 1 }\n"))
+	if err != nil {
+		t.Fatalf("parse multi-line: %v", err)
+	}
+	if raw["chat"] != "{{.Title}}\n[{{.URL}}]\n" || len(raw["list"].([]any)) != 2 {
+		t.Fatalf("multi-line values: %#v", raw)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	cases := map[string]string{
+		"bare value":    "source = giphy\n",
+		"duplicate key": "max = 1\nmax = 2\n",
+		"unterminated":  "source = \"giphy\n",
+		"value table":   "max = 1\n[max]\n",
+		"trailing":      "max = 1 2\n",
+	}
+	for name, in := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := parse([]byte(in))
+			if err == nil || !strings.HasPrefix(err.Error(), "line ") {
+				t.Fatalf("expected a line error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestSetLineKeepsCommentsAndPlacement(t *testing.T) {
+	in := "# mine\nsource = \"tenor\" # old\n\n[profiles.work]\n  max = 5\n"
+
+	got := string(setLine([]byte(in), []string{"source"}, `"giphy"`))
+	if got != "# mine\nsource = \"giphy\"\n\n[profiles.work]\n  max = 5\n" {
+		t.Fatalf("replace: %q", got)
+	}
+	got = string(setLine([]byte(in), []string{"rating"}, `"g"`))
+	if got != "# mine\nsource = \"tenor\" # old\nrating = \"g\"\n\n[profiles.work]\n  max = 5\n" {
+		t.Fatalf("insert top-level: %q", got)
+	}
+	got = string(setLine([]byte(in), []string{"profiles", "work", "max"}, "9"))
+	if !strings.Contains(got, "  max = 9\n") {
+		t.Fatalf("replace in table: %q", got)
+	}
+	got = string(setLine([]byte(in), []string{"profiles", "work", "keys", "download"}, `"D"`))
+	if !strings.HasSuffix(got, "  max = 5\n\n[profiles.work.keys]\ndownload = \"D\"\n") {
+		t.Fatalf("append table: %q", got)
+	}
+	multi := "template = \"\"\"\n{{.Title}}\nmax = 1\n[x]\n\"\"\"\nmax = 2\n"
+	got = string(setLine([]byte(multi), []string{"max"}, "3"))
+	if got != "template = \"\"\"\n{{.Title}}\nmax = 1\n[x]\n\"\"\"\nmax = 3\n" {
+		t.Fatalf("lines inside a multi-line string are not keys: %q", got)
+	}
+	got = string(setLine([]byte(multi), []string{"template"}, `"{{.URL}}"`))
+	if got != "template = \"{{.URL}}\"\nmax = 2\n" {
+		t.Fatalf("replace a multi-line value: %q", got)
+	}
+
+	got = string(setLine(nil, []string{"env", "GIFGREP_CELL_ASPECT"}, `"0.5"`))
+	if got != "[env]\nGIFGREP_CELL_ASPECT = \"0.5\"\n" {
+		t.Fatalf("new file: %q", got)
+	}
+}
+
+func TestQuoteRoundTrips(t *testing.T) {
+	in := "a \"b\" \\ c\n\x01é"
+	got, rest, err := parseBasicString(quote(in))
+	if err != nil || rest != "" || got != in {
+		t.Fatalf("round trip: %q %q %v", got, rest, err)
+	}
+}
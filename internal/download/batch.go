@@ -0,0 +1,103 @@
+package download
+
+import (
+	"path/filepath"
+	"sync"
+
+	"github.com/steipete/gifgrep/internal/model"
+)
+
+// Outcome is the result of one item in a SaveAll batch.
+type Outcome struct {
+	Item  model.Result
+	Saved Saved
+	Err   error
+}
+
+// Reporter observes a SaveAll batch. Calls come from several goroutines;
+// i is the item's index.
+type Reporter interface {
+	Start(i int, item model.Result)
+	Progress(i int, done, total int64)
+	Done(i int, outcome Outcome)
+}
+
+// DefaultJobs is the worker count SaveAll uses when jobs is not positive.
+const DefaultJobs = 4
+
+// SaveAll downloads items with up to jobs parallel workers. A failed item
+// does not stop the others; outcomes are returned in item order. r may be
+// nil.
+func SaveAll(items []model.Result, opts Options, jobs int, r Reporter) []Outcome {
+	if jobs <= 0 {
+		jobs = DefaultJobs
+	}
+	if jobs > len(items) {
+		jobs = len(items)
+	}
+	opts.claims = &claims{paths: map[string]bool{}}
+	outcomes := make([]Outcome, len(items))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < jobs; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				item := items[i]
+				itemOpts := opts
+				if r != nil {
+					r.Start(i, item)
+					itemOpts.Progress = func(done, total int64) { r.Progress(i, done, total) }
+				}
+				saved, err := Save(item, itemOpts)
+				outcomes[i] = Outcome{Item: item, Saved: saved, Err: err}
+				if r != nil {
+					r.Done(i, outcomes[i])
+				}
+			}
+		}()
+	}
+	for i := range items {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	return outcomes
+}
+
+// claims keeps parallel saves in one batch from writing the same path.
+// A nil *claims (single Save calls) only looks at the file system.
+type claims struct {
+	mu    sync.Mutex
+	paths map[string]bool
+}
+
+// unique picks a free path like uniqueFilePath and reserves it.
+func (c *claims) unique(dir, filename string) (string, error) {
+	if c == nil {
+		return uniqueFilePath(dir, filename)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	p, err := pickFreePath(dir, filename, c.paths)
+	if err == nil {
+		c.paths[p] = true
+	}
+	return p, err
+}
+
+// claim reserves p and reports whether no other item had it.
+func (c *claims) claim(p string) bool {
+	if c == nil {
+		return true
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	p = filepath.Clean(p)
+	if c.paths[p] {
+		return false
+	}
+	c.paths[p] = true
+	return true
+}
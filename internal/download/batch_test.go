@@ -0,0 +1,265 @@
+package download
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/steipete/gifgrep/internal/model"
+)
+
+func noBackoff(t *testing.T) *[]time.Duration {
+	t.Helper()
+	prev := sleep
+	var slept []time.Duration
+	sleep = func(d time.Duration) { slept = append(slept, d) }
+	t.Cleanup(func() { sleep = prev })
+	return &slept
+}
+
+// serveBytes serves payload as version "v1", honoring Range and If-Range.
+func serveBytes(payload []byte) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		http.ServeContent(w, r, "x.gif", time.Time{}, bytes.NewReader(payload))
+	}
+}
+
+func TestSaveRetriesAndResumesDroppedTransfer(t *testing.T) {
+	slept := noBackoff(t)
+	payload := []byte("GIF89a-0123456789-abcdefghij")
+
+	var ranges, ifRanges []string
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ranges = append(ranges, r.Header.Get("Range"))
+		ifRanges = append(ifRanges, r.Header.Get("If-Range"))
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("ETag", `"v1"`)
+			w.Header().Set("Content-Length", "28")
+			_, _ = w.Write(payload[:10])
+			w.(http.Flusher).Flush()
+			panic(http.ErrAbortHandler)
+		}
+		serveBytes(payload)(w, r)
+	}))
+	t.Cleanup(srv.Close)
+
+	var last int64
+	dir := t.TempDir()
+	saved, err := Save(model.Result{ID: "1", URL: srv.URL}, Options{
+		Dir:      dir,
+		Progress: func(done, _ int64) { last = done },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := os.ReadFile(saved.Path); !bytes.Equal(b, payload) {
+		t.Fatalf("payload = %q", b)
+	}
+	if len(ranges) != 2 || ranges[0] != "" || ranges[1] != "bytes=10-" || ifRanges[1] != `"v1"` {
+		t.Fatalf("ranges = %q, if-range = %q", ranges, ifRanges)
+	}
+	if len(*slept) != 1 || (*slept)[0] != 500*time.Millisecond {
+		t.Fatalf("backoff = %v", *slept)
+	}
+	if last != int64(len(payload)) {
+		t.Fatalf("progress ended at %d", last)
+	}
+	for _, leftover := range []string{saved.Path + ".part", saved.Path + ".part.json"} {
+		if _, err := os.Stat(leftover); !os.IsNotExist(err) {
+			t.Fatalf("%s left behind: %v", filepath.Base(leftover), err)
+		}
+	}
+}
+
+func TestSaveResumesLeftoverPartFile(t *testing.T) {
+	payload := []byte("GIF89a-resumed")
+	var rng string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rng = r.Header.Get("Range")
+		serveBytes(payload)(w, r)
+	}))
+	t.Cleanup(srv.Close)
+
+	for _, tc := range []struct {
+		name    string
+		part    string
+		info    *partInfo
+		wantRng string
+	}{
+		{"same version", "GIF89a", &partInfo{URL: srv.URL, ETag: `"v1"`}, "bytes=6-"},
+		{"changed version", "stale!", &partInfo{URL: srv.URL, ETag: `"v0"`}, "bytes=6-"},
+		{"other url", "stale!", &partInfo{URL: srv.URL + "/other", ETag: `"v1"`}, ""},
+		{"no validator", "stale!", &partInfo{URL: srv.URL}, ""},
+		{"no sidecar", "stale!", nil, ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			part := filepath.Join(dir, "cat.gif.part")
+			if err := os.WriteFile(part, []byte(tc.part), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if tc.info != nil {
+				if err := writePartInfo(part, *tc.info); err != nil {
+					t.Fatal(err)
+				}
+			}
+			saved, err := Save(model.Result{Title: "cat", URL: srv.URL}, Options{Dir: dir})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if rng != tc.wantRng {
+				t.Fatalf("range = %q", rng)
+			}
+			if b, _ := os.ReadFile(saved.Path); !bytes.Equal(b, payload) {
+				t.Fatalf("payload = %q", b)
+			}
+		})
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"503", statusError{http.StatusServiceUnavailable}, true},
+		{"429", statusError{http.StatusTooManyRequests}, true},
+		{"404", statusError{http.StatusNotFound}, false},
+		{"timeout", &net.DNSError{Err: "timeout", IsTimeout: true}, true},
+		{"reset", &net.OpError{Op: "read", Err: os.NewSyscallError("read", syscall.ECONNRESET)}, true},
+		{"cut short", fmt.Errorf("copy: %w", io.ErrUnexpectedEOF), true},
+		{"no such host", &net.OpError{Op: "dial", Err: &net.DNSError{Err: "no such host", IsNotFound: true}}, false},
+		{"refused", &net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}, false},
+	} {
+		if got := retryable(tc.err); got != tc.want {
+			t.Errorf("%s: retryable = %v", tc.name, got)
+		}
+	}
+}
+
+func TestSaveRetriesOnlyTransientStatus(t *testing.T) {
+	slept := noBackoff(t)
+	var calls int32
+	status := http.StatusServiceUnavailable
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+
+	if _, err := Save(model.Result{ID: "1", URL: srv.URL}, Options{Dir: t.TempDir()}); err == nil || err.Error() != "http 503" {
+		t.Fatalf("err = %v", err)
+	}
+	if calls != maxAttempts || len(*slept) != maxAttempts-1 || (*slept)[1] != time.Second {
+		t.Fatalf("calls = %d, backoff = %v", calls, *slept)
+	}
+
+	calls = 0
+	status = http.StatusNotFound
+	if _, err := Save(model.Result{ID: "1", URL: srv.URL}, Options{Dir: t.TempDir()}); err == nil {
+		t.Fatalf("expected 404 error")
+	}
+	if calls != 1 {
+		t.Fatalf("404 retried: %d calls", calls)
+	}
+}
+
+type recordingReporter struct {
+	mu      sync.Mutex
+	started int
+	done    []int
+}
+
+func (r *recordingReporter) Start(int, model.Result) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.started++
+}
+
+func (r *recordingReporter) Progress(int, int64, int64) {}
+
+func (r *recordingReporter) Done(i int, _ Outcome) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.done = append(r.done, i)
+}
+
+func TestSaveAllKeepsGoingAndPicksDistinctNames(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing.gif" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte("GIF89a" + r.URL.Path))
+	}))
+	t.Cleanup(srv.Close)
+
+	items := []model.Result{
+		{Title: "same", URL: srv.URL + "/1.gif"},
+		{Title: "same", URL: srv.URL + "/missing.gif"},
+		{Title: "same", URL: srv.URL + "/2.gif"},
+		{Title: "same", URL: srv.URL + "/3.gif"},
+		{Title: "same", URL: srv.URL + "/4.gif"},
+	}
+	rep := &recordingReporter{}
+	outcomes := SaveAll(items, Options{Dir: t.TempDir()}, 3, rep)
+
+	if len(outcomes) != len(items) || rep.started != len(items) || len(rep.done) != len(items) {
+		t.Fatalf("outcomes %d, started %d, done %d", len(outcomes), rep.started, len(rep.done))
+	}
+	paths := map[string]bool{}
+	for i, o := range outcomes {
+		if o.Item.URL != items[i].URL {
+			t.Fatalf("outcome %d out of order", i)
+		}
+		if i == 1 {
+			if o.Err == nil {
+				t.Fatalf("expected missing.gif to fail")
+			}
+			continue
+		}
+		if o.Err != nil {
+			t.Fatalf("outcome %d: %v", i, o.Err)
+		}
+		paths[o.Saved.Path] = true
+	}
+	if len(paths) != 4 {
+		t.Fatalf("expected 4 distinct files, got %v", paths)
+	}
+}
+
+func TestParseContentRange(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		in          string
+		start, size int64
+		ok          bool
+	}{
+		{"bytes 10-27/28", 10, 28, true},
+		{"bytes 0-9/*", 0, -1, true},
+		{"bytes */28", 0, 0, false},
+		{"items 1-2/3", 0, 0, false},
+	}
+	for _, tc := range cases {
+		start, size, ok := parseContentRange(tc.in)
+		if start != tc.start || size != tc.size || ok != tc.ok {
+			t.Fatalf("%q = %d, %d, %v", tc.in, start, size, ok)
+		}
+	}
+}
@@ -0,0 +1,256 @@
+package download
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Dedup decides what happens when a new download has the same content as a
+// file already in the download directory. It only applies when the download
+// would add a file, not when it replaces one (CollisionOverwrite).
+type Dedup string
+
+const (
+	// DedupSkip keeps the existing file and drops the download.
+	DedupSkip Dedup = "skip"
+	// DedupLink hard-links (or, across devices, symlinks) the existing file
+	// under the new name.
+	DedupLink Dedup = "link"
+	DedupOff  Dedup = "off"
+)
+
+// indexName is the content index kept in the download directory once a
+// dedup policy is used there. It maps paths to size, mtime and SHA-256, so a
+// save only looks at files of the same size and rehashes those whose mtime
+// changed. Sums are of the bytes as downloaded, before any provenance
+// comment. The directory is scanned when the index is first created; files
+// other tools add later are seen once the index is deleted.
+const indexName = ".gifgrep-index.json"
+
+// maxIndexDepth bounds how far below the download directory the first scan
+// looks; filename templates rarely nest deeper.
+const maxIndexDepth = 3
+
+// indexedExts are the file types downloads can produce (see resultExt).
+var indexedExts = map[string]bool{".gif": true, ".webp": true, ".mp4": true, ".png": true}
+
+// indexMu serializes index reads, lookups and renames so parallel saves of
+// identical content in one batch see each other. Hashing and embedding
+// happen outside it.
+var indexMu sync.Mutex
+
+type hashIndex struct {
+	Files map[string]indexEntry `json:"files"`
+}
+
+// indexEntry describes one file; SHA256 is empty until a download of the
+// same size needs it. HashedSize is the size of the hashed bytes when they
+// differ from the file (provenance embedded after hashing).
+type indexEntry struct {
+	Size       int64  `json:"size"`
+	ModTime    int64  `json:"mtime"`
+	SHA256     string `json:"sha256,omitempty"`
+	HashedSize int64  `json:"hashed_size,omitempty"`
+}
+
+// contentSize is the size a download must have to match the entry.
+func (e indexEntry) contentSize() int64 {
+	if e.HashedSize > 0 {
+		return e.HashedSize
+	}
+	return e.Size
+}
+
+// finalize moves a completed part file to target unless dedup finds the same
+// content already under root. embed, when set, edits the part file after it
+// is hashed, so provenance does not defeat dedup.
+func finalize(part, target, root string, policy Dedup, embed func(string) error) (Saved, error) {
+	if policy == "" || policy == DedupOff {
+		if embed != nil {
+			if err := embed(part); err != nil {
+				return Saved{}, err
+			}
+		}
+		if err := os.Rename(part, target); err != nil {
+			return Saved{}, err
+		}
+		return Saved{Path: target}, nil
+	}
+
+	sum, size, err := hashFile(part)
+	if err != nil {
+		return Saved{}, err
+	}
+	if embed != nil {
+		if err := embed(part); err != nil {
+			return Saved{}, err
+		}
+	}
+
+	indexMu.Lock()
+	defer indexMu.Unlock()
+	idx := loadIndex(root)
+	if _, err := os.Stat(target); err != nil {
+		if dup := idx.find(root, sum, size); dup != "" {
+			_ = os.Remove(part)
+			if policy == DedupLink {
+				if err := linkFile(dup, target); err != nil {
+					return Saved{}, err
+				}
+				idx.save(root)
+				return Saved{Path: target, Duplicate: dup}, nil
+			}
+			idx.save(root)
+			return Saved{Path: dup, Skipped: true, Duplicate: dup}, nil
+		}
+	}
+	if err := os.Rename(part, target); err != nil {
+		return Saved{}, err
+	}
+	idx.record(root, target, sum, size)
+	idx.save(root)
+	return Saved{Path: target}, nil
+}
+
+// loadIndex reads the index under root, scanning the directory when there is
+// none yet (or it is unreadable).
+func loadIndex(root string) *hashIndex {
+	idx := &hashIndex{}
+	data, err := os.ReadFile(filepath.Join(root, indexName))
+	if err != nil || json.Unmarshal(data, idx) != nil || idx.Files == nil {
+		idx.Files = map[string]indexEntry{}
+		idx.scan(root)
+	}
+	return idx
+}
+
+// save writes the index; it is only a cache, so errors are ignored.
+func (idx *hashIndex) save(root string) {
+	data, err := json.MarshalIndent(idx, "", "  ")
+	if err != nil {
+		return
+	}
+	tmp, err := os.CreateTemp(root, indexName+".*")
+	if err != nil {
+		return
+	}
+	_, werr := tmp.Write(append(data, '\n'))
+	cerr := tmp.Close()
+	if werr != nil || cerr != nil || os.Rename(tmp.Name(), filepath.Join(root, indexName)) != nil {
+		_ = os.Remove(tmp.Name())
+	}
+}
+
+// scan adds the media files under root by size and mtime, without hashing.
+func (idx *hashIndex) scan(root string) {
+	_ = filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		rel, relErr := filepath.Rel(root, p)
+		if relErr != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if p != root && (strings.HasPrefix(d.Name(), ".") || strings.Count(rel, string(filepath.Separator)) >= maxIndexDepth-1) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || !indexedExts[strings.ToLower(filepath.Ext(p))] {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			idx.Files[rel] = indexEntry{Size: info.Size(), ModTime: info.ModTime().UnixNano()}
+		}
+		return nil
+	})
+}
+
+// find returns an indexed file whose content hashes to sum. Only entries of
+// the same size are checked; one whose file changed is rehashed, one whose
+// file is gone is dropped.
+func (idx *hashIndex) find(root, sum string, size int64) string {
+	var candidates []string
+	for rel, entry := range idx.Files {
+		if entry.contentSize() == size {
+			candidates = append(candidates, rel)
+		}
+	}
+	sort.Strings(candidates)
+	for _, rel := range candidates {
+		p := filepath.Join(root, rel)
+		info, err := os.Lstat(p)
+		if err != nil || !info.Mode().IsRegular() {
+			delete(idx.Files, rel)
+			continue
+		}
+		entry := idx.Files[rel]
+		if entry.SHA256 == "" || entry.Size != info.Size() || entry.ModTime != info.ModTime().UnixNano() {
+			fileSum, _, err := hashFile(p)
+			if err != nil {
+				delete(idx.Files, rel)
+				continue
+			}
+			entry = indexEntry{Size: info.Size(), ModTime: info.ModTime().UnixNano(), SHA256: fileSum}
+			idx.Files[rel] = entry
+		}
+		if entry.contentSize() == size && entry.SHA256 == sum {
+			return p
+		}
+	}
+	return ""
+}
+
+// record adds p, whose content before any embedding hashed to sum over size
+// bytes.
+func (idx *hashIndex) record(root, p, sum string, size int64) {
+	rel, err := filepath.Rel(root, p)
+	if err != nil {
+		return
+	}
+	info, err := os.Stat(p)
+	if err != nil {
+		return
+	}
+	entry := indexEntry{Size: info.Size(), ModTime: info.ModTime().UnixNano(), SHA256: sum}
+	if size != info.Size() {
+		entry.HashedSize = size
+	}
+	idx.Files[rel] = entry
+}
+
+func hashFile(p string) (string, int64, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return "", 0, err
+	}
+	defer func() { _ = f.Close() }()
+	h := sha256.New()
+	n, err := io.Copy(h, f)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(h.Sum(nil)), n, nil
+}
+
+// linkFile makes target refer to existing: a hard link where possible, else
+// a relative symlink.
+func linkFile(existing, target string) error {
+	if err := os.Link(existing, target); err == nil {
+		return nil
+	}
+	rel, err := filepath.Rel(filepath.Dir(target), existing)
+	if err != nil {
+		rel = existing
+	}
+	return os.Symlink(rel, target)
+}
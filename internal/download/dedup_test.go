@@ -0,0 +1,183 @@
+package download
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/steipete/gifgrep/internal/model"
+)
+
+func TestSaveSkipsIdenticalContent(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(httpHandlerString("GIF89a-same"))
+	t.Cleanup(srv.Close)
+
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "giphy"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	existing := filepath.Join(dir, "giphy", "older.gif")
+	if err := os.WriteFile(existing, []byte("GIF89a-same"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// Same size, different bytes: must not match.
+	if err := os.WriteFile(filepath.Join(dir, "a-decoy.gif"), []byte("GIF89a-diff"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	saved, err := Save(model.Result{ID: "1", Title: "cat", URL: srv.URL}, Options{Dir: dir, Dedup: DedupSkip})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Path != existing || !saved.Skipped || saved.Duplicate != existing {
+		t.Fatalf("saved = %+v", saved)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "cat.gif")); !os.IsNotExist(err) {
+		t.Fatalf("duplicate was written: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "cat.gif.part")); !os.IsNotExist(err) {
+		t.Fatalf("part file left behind: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, indexName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var idx hashIndex
+	if err := json.Unmarshal(data, &idx); err != nil {
+		t.Fatal(err)
+	}
+	if len(idx.Files) != 2 || idx.Files[filepath.Join("giphy", "older.gif")].SHA256 == "" {
+		t.Fatalf("index = %+v", idx.Files)
+	}
+}
+
+func TestSaveDedupLinkAndOff(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(httpHandlerString("GIF89a-same"))
+	t.Cleanup(srv.Close)
+
+	dir := t.TempDir()
+	first, err := Save(model.Result{Title: "first", URL: srv.URL}, Options{Dir: dir})
+	if err != nil || first.Duplicate != "" {
+		t.Fatalf("first = %+v, %v", first, err)
+	}
+
+	linked, err := Save(model.Result{Title: "second", URL: srv.URL}, Options{Dir: dir, Dedup: DedupLink})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if linked.Path != filepath.Join(dir, "second.gif") || linked.Duplicate != first.Path || linked.Skipped {
+		t.Fatalf("linked = %+v", linked)
+	}
+	a, _ := os.Stat(first.Path)
+	b, _ := os.Stat(linked.Path)
+	if !os.SameFile(a, b) {
+		t.Fatalf("expected a hard link")
+	}
+
+	off, err := Save(model.Result{Title: "third", URL: srv.URL}, Options{Dir: dir, Dedup: DedupOff})
+	if err != nil || off.Path != filepath.Join(dir, "third.gif") || off.Duplicate != "" {
+		t.Fatalf("off = %+v, %v", off, err)
+	}
+
+	if _, err := Save(model.Result{Title: "x", URL: srv.URL}, Options{Dir: dir, Dedup: "maybe"}); err == nil {
+		t.Fatalf("expected unknown dedup error")
+	}
+}
+
+func TestSaveAllDedupsWithinBatch(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(httpHandlerString("GIF89a-same"))
+	t.Cleanup(srv.Close)
+
+	items := []model.Result{
+		{ID: "1", URL: srv.URL + "/tenor.gif"},
+		{ID: "2", URL: srv.URL + "/giphy.gif"},
+		{ID: "3", URL: srv.URL + "/heypster.gif"},
+	}
+	outcomes := SaveAll(items, Options{Dir: t.TempDir(), Dedup: DedupSkip}, 3, nil)
+	written, dups := 0, 0
+	for _, o := range outcomes {
+		switch {
+		case o.Err != nil:
+			t.Fatal(o.Err)
+		case o.Saved.Duplicate != "":
+			dups++
+		default:
+			written++
+		}
+	}
+	if written != 1 || dups != 2 {
+		t.Fatalf("written %d, duplicates %d", written, dups)
+	}
+}
+
+func TestSaveWithoutDedupWritesNoIndex(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(httpHandlerString("GIF89a-same"))
+	t.Cleanup(srv.Close)
+
+	dir := t.TempDir()
+	opts := OptionsFrom(model.Options{DownloadDir: dir}, "tenor")
+	if opts.Dedup != DedupOff {
+		t.Fatalf("default dedup = %q", opts.Dedup)
+	}
+	for _, title := range []string{"one", "two"} {
+		saved, err := Save(model.Result{Title: title, URL: srv.URL}, opts)
+		if err != nil || saved.Duplicate != "" {
+			t.Fatalf("saved = %+v, %v", saved, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, indexName)); !os.IsNotExist(err) {
+		t.Fatalf("index created without dedup: %v", err)
+	}
+}
+
+func TestDedupIndexRehashesChangedFiles(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(httpHandlerString("GIF89a-same"))
+	t.Cleanup(srv.Close)
+
+	dir := t.TempDir()
+	opts := Options{Dir: dir, Dedup: DedupSkip}
+	first, err := Save(model.Result{Title: "first", URL: srv.URL}, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Same size, new content and mtime: the stale sum must not match.
+	if err := os.WriteFile(first.Path, []byte("GIF89a-diff"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(first.Path, later, later); err != nil {
+		t.Fatal(err)
+	}
+	second, err := Save(model.Result{Title: "second", URL: srv.URL}, opts)
+	if err != nil || second.Duplicate != "" || second.Path != filepath.Join(dir, "second.gif") {
+		t.Fatalf("second = %+v, %v", second, err)
+	}
+
+	// A vanished file drops out of the index.
+	if err := os.Remove(second.Path); err != nil {
+		t.Fatal(err)
+	}
+	third, err := Save(model.Result{Title: "third", URL: srv.URL}, opts)
+	if err != nil || third.Duplicate != "" {
+		t.Fatalf("third = %+v, %v", third, err)
+	}
+	idx := loadIndex(dir)
+	if _, ok := idx.Files["second.gif"]; ok || len(idx.Files) != 2 {
+		t.Fatalf("index = %+v", idx.Files)
+	}
+}
@@ -1,49 +1,241 @@
 package download
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
 	"path"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"syscall"
 	"time"
 	"unicode"
 
 	"github.com/steipete/gifgrep/internal/model"
 )
 
+// Collision decides what happens when the target file already exists.
+type Collision string
+
+const (
+	// CollisionSuffix picks name-1.gif, name-2.gif, ...
+	CollisionSuffix    Collision = "suffix"
+	CollisionOverwrite Collision = "overwrite"
+	// CollisionSkip keeps the existing file and skips the download.
+	CollisionSkip Collision = "skip"
+)
+
+// Options control where and under which name a result is saved.
+type Options struct {
+	// Dir is the destination; DefaultDir when empty.
+	Dir string
+	// Name is a filename template such as "{source}-{id}-{title}.{ext}"
+	// (see ValidateName); empty derives the name from the title.
+	Name string
+	// Collision defaults to CollisionSuffix.
+	Collision Collision
+	// Dedup defaults to DedupOff; the other policies keep an index file in
+	// Dir (see indexName).
+	Dedup Dedup
+	// Metadata embeds the result's Provenance in new files.
+	Metadata bool
+	// Source is the provider name used for {source}.
+	Source string
+	// Progress, when set, is called as bytes arrive; total is -1 when the
+	// server does not send a length.
+	Progress func(done, total int64)
+
+	claims *claims
+}
+
+// OptionsFrom collects the download settings from opts; source is the
+// resolved provider.
+func OptionsFrom(opts model.Options, source string) Options {
+	dedup := Dedup(opts.DownloadDedup)
+	if dedup == "" {
+		// Dedup writes .gifgrep-index.json, so it only runs when asked for.
+		dedup = DedupOff
+	}
+	return Options{
+		Dir:       opts.DownloadDir,
+		Name:      opts.DownloadName,
+		Collision: Collision(opts.DownloadCollision),
+		Dedup:     dedup,
+		Metadata:  opts.DownloadMetadata,
+		Source:    source,
+	}
+}
+
+// Saved describes where a result ended up.
+type Saved struct {
+	Path string
+	// Skipped is set when the file already existed and was kept.
+	Skipped bool
+	// Duplicate is the file already in the download directory with the same
+	// content. Path is that file (DedupSkip) or a link to it (DedupLink).
+	Duplicate string
+}
+
 func ToDownloads(item model.Result) (string, error) {
-	dir, err := DefaultDir()
-	if err != nil {
-		return "", err
+	saved, err := Save(item, Options{})
+	return saved.Path, err
+}
+
+// Save downloads item according to opts.
+func Save(item model.Result, opts Options) (Saved, error) {
+	switch opts.Dedup {
+	case "", DedupSkip, DedupLink, DedupOff:
+	default:
+		return Saved{}, fmt.Errorf("unknown dedup policy %q (use skip, link or off)", opts.Dedup)
 	}
-	if err := os.MkdirAll(dir, 0o755); err != nil {
-		return "", err
+	dir := opts.Dir
+	if dir == "" {
+		var err error
+		if dir, err = DefaultDir(); err != nil {
+			return Saved{}, err
+		}
 	}
 	filename := filenameForResult(item)
-	finalPath, err := uniqueFilePath(dir, filename)
-	if err != nil {
-		return "", err
+	if opts.Name != "" {
+		var err error
+		if filename, err = renderName(opts.Name, item, opts.Source); err != nil {
+			return Saved{}, err
+		}
 	}
+	target := filepath.Join(dir, filename)
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return Saved{}, err
+	}
+
+	switch opts.Collision {
+	case "", CollisionSuffix:
+		var err error
+		if target, err = opts.claims.unique(filepath.Dir(target), filepath.Base(target)); err != nil {
+			return Saved{}, err
+		}
+	case CollisionSkip:
+		if _, err := os.Stat(target); err == nil || !opts.claims.claim(target) {
+			return Saved{Path: target, Skipped: true}, nil
+		}
+	case CollisionOverwrite:
+		// The final rename replaces the old file; within a batch the first
+		// result with this name wins.
+		if !opts.claims.claim(target) {
+			return Saved{Path: target, Skipped: true}, nil
+		}
+	default:
+		return Saved{}, fmt.Errorf("unknown collision policy %q (use suffix, overwrite or skip)", opts.Collision)
+	}
+
+	var embed func(string) error
+	if opts.Metadata {
+		prov := provenanceFor(item, opts.Source)
+		embed = func(p string) error { return embedProvenance(p, prov) }
+	}
+
 	client := &http.Client{Timeout: 20 * time.Second}
-	if err := downloadGIFToFile(client, item.URL, finalPath); err != nil {
-		return "", err
+	part := target + ".part"
+	for attempt := 1; ; attempt++ {
+		err := fetchToPart(client, item.URL, part, opts.Progress)
+		if err == nil {
+			return finalize(part, target, dir, opts.Dedup, embed)
+		}
+		if attempt >= maxAttempts || !retryable(err) {
+			return Saved{}, err
+		}
+		sleep(backoff(attempt))
 	}
-	return finalPath, nil
 }
 
+// maxAttempts bounds tries per file; later tries resume from the .part file.
+const maxAttempts = 3
+
+var (
+	sleep   = time.Sleep
+	backoff = func(attempt int) time.Duration {
+		return time.Duration(1<<(attempt-1)) * 500 * time.Millisecond
+	}
+)
+
+// statusError is a non-2xx HTTP response.
+type statusError struct{ code int }
+
+func (e statusError) Error() string { return fmt.Sprintf("http %d", e.code) }
+
+// retryable reports whether err looks transient: timeouts, connections
+// reset or cut short mid-transfer, and 408/429/5xx responses. A 416 is
+// retried too, since the stale .part file is gone and the next try starts
+// over. DNS failures and refused connections fail right away.
+func retryable(err error) bool {
+	var status statusError
+	if errors.As(err, &status) {
+		return status.code == http.StatusRequestTimeout ||
+			status.code == http.StatusTooManyRequests ||
+			status.code == http.StatusRequestedRangeNotSatisfiable ||
+			status.code >= 500
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNABORTED) ||
+		errors.Is(err, syscall.EPIPE)
+}
+
+// DefaultDir is $XDG_DOWNLOAD_DIR (from the environment or user-dirs.dirs),
+// else ~/Downloads.
 func DefaultDir() (string, error) {
 	home, err := os.UserHomeDir()
 	if err != nil {
 		return "", err
 	}
+	if dir := xdgDownloadDir(home); dir != "" {
+		return dir, nil
+	}
 	return filepath.Join(home, "Downloads"), nil
 }
 
+func xdgDownloadDir(home string) string {
+	dir := strings.TrimSpace(os.Getenv("XDG_DOWNLOAD_DIR"))
+	if dir == "" {
+		configHome := os.Getenv("XDG_CONFIG_HOME")
+		if configHome == "" {
+			configHome = filepath.Join(home, ".config")
+		}
+		dir = userDirsEntry(filepath.Join(configHome, "user-dirs.dirs"), "XDG_DOWNLOAD_DIR")
+	}
+	dir = strings.ReplaceAll(strings.ReplaceAll(dir, "${HOME}", home), "$HOME", home)
+	// xdg-user-dirs points disabled entries at $HOME itself.
+	if dir == "" || !filepath.IsAbs(dir) || filepath.Clean(dir) == filepath.Clean(home) {
+		return ""
+	}
+	return dir
+}
+
+// userDirsEntry reads KEY="value" from an xdg-user-dirs file.
+func userDirsEntry(path, key string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		k, v, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if ok && k == key {
+			return strings.Trim(v, `"`)
+		}
+	}
+	return ""
+}
+
 func filenameForResult(item model.Result) string {
 	name := strings.TrimSpace(item.Title)
 	if name == "" {
@@ -60,6 +252,11 @@ func filenameForResult(item model.Result) string {
 	if !strings.HasSuffix(strings.ToLower(name), ".gif") {
 		name += ".gif"
 	}
+	return truncateName(name)
+}
+
+// truncateName caps a file name at 80 bytes, keeping its extension.
+func truncateName(name string) string {
 	const maxLen = 80
 	if len(name) > maxLen {
 		base := strings.TrimSuffix(name, filepath.Ext(name))
@@ -121,61 +318,212 @@ func sanitizeFilename(name string) string {
 }
 
 func uniqueFilePath(dir, filename string) (string, error) {
-	fullPath := filepath.Join(dir, filename)
-	_, err := os.Stat(fullPath)
-	if err == nil {
-		base := strings.TrimSuffix(filename, filepath.Ext(filename))
-		ext := filepath.Ext(filename)
-		if ext == "" {
-			ext = ".gif"
+	return pickFreePath(dir, filename, nil)
+}
+
+// pickFreePath returns dir/filename, or the first free name-N variant;
+// taken marks paths that are spoken for even though they do not exist yet.
+func pickFreePath(dir, filename string, taken map[string]bool) (string, error) {
+	free := func(p string) (bool, error) {
+		if taken[p] {
+			return false, nil
 		}
-		for i := 1; i < 1000; i++ {
-			candidate := filepath.Join(dir, fmt.Sprintf("%s-%d%s", base, i, ext))
-			if _, err := os.Stat(candidate); errors.Is(err, os.ErrNotExist) {
-				return candidate, nil
-			}
+		_, err := os.Stat(p)
+		if errors.Is(err, os.ErrNotExist) {
+			return true, nil
 		}
-		return "", errors.New("could not pick filename")
+		return false, err
 	}
-	if errors.Is(err, os.ErrNotExist) {
+	fullPath := filepath.Join(dir, filename)
+	ok, err := free(fullPath)
+	if err != nil {
+		return "", err
+	}
+	if ok {
 		return fullPath, nil
 	}
-	return "", err
+	base := strings.TrimSuffix(filename, filepath.Ext(filename))
+	ext := filepath.Ext(filename)
+	if ext == "" {
+		ext = ".gif"
+	}
+	for i := 1; i < 1000; i++ {
+		candidate := filepath.Join(dir, fmt.Sprintf("%s-%d%s", base, i, ext))
+		if ok, err := free(candidate); err != nil {
+			return "", err
+		} else if ok {
+			return candidate, nil
+		}
+	}
+	return "", errors.New("could not pick filename")
 }
 
-func downloadGIFToFile(client *http.Client, gifURL, dest string) error {
+// fetchToPart downloads gifURL into part. A leftover part file is resumed
+// with a Range request only when its sidecar (see partInfo) names the same
+// URL and a validator for If-Range, so a changed file is fetched from the
+// start instead of being spliced onto stale bytes.
+func fetchToPart(client *http.Client, gifURL, part string, progress func(done, total int64)) error {
 	if client == nil {
 		client = http.DefaultClient
 	}
+	var offset int64
+	var ifRange string
+	if info, err := os.Stat(part); err == nil && info.Mode().IsRegular() && info.Size() > 0 {
+		if meta, ok := readPartInfo(part); ok && meta.URL == gifURL && meta.validator() != "" {
+			offset = info.Size()
+			ifRange = meta.validator()
+		}
+	}
+
 	req, err := http.NewRequest(http.MethodGet, gifURL, nil)
 	if err != nil {
 		return err
 	}
 	req.Header.Set("User-Agent", "gifgrep")
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		req.Header.Set("If-Range", ifRange)
+	}
 	resp, err := client.Do(req)
 	if err != nil {
 		return err
 	}
 	defer func() { _ = resp.Body.Close() }()
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("http %d", resp.StatusCode)
+
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	total := resp.ContentLength
+	switch {
+	case resp.StatusCode == http.StatusPartialContent && offset > 0:
+		start, size, ok := parseContentRange(resp.Header.Get("Content-Range"))
+		if !ok || start != offset {
+			return fmt.Errorf("resume %s: unexpected Content-Range %q", filepath.Base(strings.TrimSuffix(part, ".part")), resp.Header.Get("Content-Range"))
+		}
+		flags = os.O_WRONLY | os.O_APPEND
+		total = size
+		if total < 0 && resp.ContentLength >= 0 {
+			total = offset + resp.ContentLength
+		}
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && offset > 0:
+		// The .part file does not match what the server has; start over.
+		removePart(part)
+		return statusError{resp.StatusCode}
+	case resp.StatusCode < 200 || resp.StatusCode >= 300:
+		return statusError{resp.StatusCode}
+	default:
+		// A plain 200: no part file, or If-Range found the file changed.
+		offset = 0
+		_ = writePartInfo(part, partInfo{
+			URL:          gifURL,
+			ETag:         resp.Header.Get("ETag"),
+			LastModified: resp.Header.Get("Last-Modified"),
+		})
 	}
 
-	dir := filepath.Dir(dest)
-	tmp, err := os.CreateTemp(dir, "gifgrep-*.gif")
+	f, err := os.OpenFile(part, flags, 0o644)
 	if err != nil {
 		return err
 	}
-	defer func() {
-		_ = tmp.Close()
-		_ = os.Remove(tmp.Name())
-	}()
+	defer func() { _ = f.Close() }()
 
-	if _, err := io.Copy(tmp, resp.Body); err != nil {
+	var body io.Reader = resp.Body
+	if progress != nil {
+		progress(offset, total)
+		body = &progressReader{r: resp.Body, done: offset, total: total, fn: progress}
+	}
+	n, err := io.Copy(f, body)
+	if err != nil {
 		return err
 	}
-	if err := tmp.Close(); err != nil {
+	if total > 0 && offset+n != total {
+		return io.ErrUnexpectedEOF
+	}
+	if err := f.Close(); err != nil {
 		return err
 	}
-	return os.Rename(tmp.Name(), dest)
+	_ = os.Remove(partInfoPath(part))
+	return nil
+}
+
+// partInfo is kept next to a .part file (as .part.json) while it is
+// incomplete: the URL it came from and the validators to resume it with.
+type partInfo struct {
+	URL          string `json:"url"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+}
+
+func partInfoPath(part string) string { return part + ".json" }
+
+func readPartInfo(part string) (partInfo, bool) {
+	var info partInfo
+	data, err := os.ReadFile(partInfoPath(part))
+	if err != nil || json.Unmarshal(data, &info) != nil {
+		return partInfo{}, false
+	}
+	return info, true
+}
+
+func writePartInfo(part string, info partInfo) error {
+	data, err := json.Marshal(info)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(partInfoPath(part), data, 0o644)
+}
+
+// validator is the If-Range value: a strong ETag, else Last-Modified. Weak
+// ETags cannot be used for ranges.
+func (p partInfo) validator() string {
+	if p.ETag != "" && !strings.HasPrefix(p.ETag, "W/") {
+		return p.ETag
+	}
+	return p.LastModified
+}
+
+func removePart(part string) {
+	_ = os.Remove(part)
+	_ = os.Remove(partInfoPath(part))
+}
+
+// parseContentRange reads "bytes start-end/size"; size is -1 for "*".
+func parseContentRange(v string) (start, size int64, ok bool) {
+	spec, found := strings.CutPrefix(strings.TrimSpace(v), "bytes ")
+	if !found {
+		return 0, 0, false
+	}
+	rng, sz, found := strings.Cut(spec, "/")
+	if !found {
+		return 0, 0, false
+	}
+	first, _, found := strings.Cut(rng, "-")
+	if !found {
+		return 0, 0, false
+	}
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	size = -1
+	if sz != "*" {
+		if size, err = strconv.ParseInt(sz, 10, 64); err != nil {
+			return 0, 0, false
+		}
+	}
+	return start, size, true
+}
+
+type progressReader struct {
+	r     io.Reader
+	done  int64
+	total int64
+	fn    func(done, total int64)
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	if n > 0 {
+		p.done += int64(n)
+		p.fn(p.done, p.total)
+	}
+	return n, err
 }
@@ -64,18 +64,18 @@ func TestUniqueFilePath(t *testing.T) {
 	}
 }
 
-func TestDownloadGIFToFile(t *testing.T) {
+func TestFetchToPart(t *testing.T) {
 	t.Parallel()
 
 	const payload = "GIF89a"
 	srv := httptest.NewServer(httpHandlerString(payload))
 	t.Cleanup(srv.Close)
 
-	dest := filepath.Join(t.TempDir(), "out.gif")
-	if err := downloadGIFToFile(srv.Client(), srv.URL, dest); err != nil {
+	part := filepath.Join(t.TempDir(), "out.gif.part")
+	if err := fetchToPart(srv.Client(), srv.URL, part, nil); err != nil {
 		t.Fatal(err)
 	}
-	b, err := os.ReadFile(dest)
+	b, err := os.ReadFile(part)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -91,6 +91,8 @@ func TestToDownloadsUsesHome(t *testing.T) {
 
 	home := t.TempDir()
 	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("XDG_DOWNLOAD_DIR", "")
 
 	res := model.Result{
 		Title: "a",
@@ -108,3 +110,100 @@ func TestToDownloadsUsesHome(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestSaveCollisionPolicies(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(httpHandlerString("new"))
+	t.Cleanup(srv.Close)
+
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "tenor-1.gif")
+	if err := os.WriteFile(existing, []byte("old"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	item := model.Result{ID: "1", URL: srv.URL + "/cat.gif"}
+	opts := Options{Dir: dir, Name: "{source}-{id}.{ext}", Source: "tenor"}
+
+	saved, err := Save(item, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(saved.Path) != "tenor-1-1.gif" || saved.Skipped {
+		t.Fatalf("suffix: %+v", saved)
+	}
+
+	opts.Collision = CollisionSkip
+	saved, err = Save(item, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Path != existing || !saved.Skipped {
+		t.Fatalf("skip: %+v", saved)
+	}
+	if b, _ := os.ReadFile(existing); string(b) != "old" {
+		t.Fatalf("skip rewrote the file: %q", b)
+	}
+
+	opts.Collision = CollisionOverwrite
+	saved, err = Save(item, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Path != existing {
+		t.Fatalf("overwrite: %+v", saved)
+	}
+	if b, _ := os.ReadFile(existing); string(b) != "new" {
+		t.Fatalf("overwrite kept the old content: %q", b)
+	}
+
+	opts.Collision = "rename"
+	if _, err := Save(item, opts); err == nil {
+		t.Fatalf("expected unknown collision error")
+	}
+}
+
+func TestSaveCreatesTemplateSubdirs(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(httpHandlerString("GIF89a"))
+	t.Cleanup(srv.Close)
+
+	dir := t.TempDir()
+	saved, err := Save(model.Result{ID: "9", URL: srv.URL}, Options{Dir: dir, Name: "{source}/{id}", Source: "giphy"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Path != filepath.Join(dir, "giphy", "9.gif") {
+		t.Fatalf("unexpected path %q", saved.Path)
+	}
+}
+
+func TestDefaultDirUsesXDGDownloadDir(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	t.Setenv("XDG_DOWNLOAD_DIR", "")
+
+	if err := os.MkdirAll(filepath.Join(home, ".config"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	dirs := "# xdg-user-dirs\nXDG_DESKTOP_DIR=\"$HOME/Desktop\"\nXDG_DOWNLOAD_DIR=\"$HOME/Fetched\"\n"
+	if err := os.WriteFile(filepath.Join(home, ".config", "user-dirs.dirs"), []byte(dirs), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := DefaultDir(); got != filepath.Join(home, "Fetched") {
+		t.Fatalf("user-dirs.dirs: got %q", got)
+	}
+
+	t.Setenv("XDG_DOWNLOAD_DIR", filepath.Join(home, "FromEnv"))
+	if got, _ := DefaultDir(); got != filepath.Join(home, "FromEnv") {
+		t.Fatalf("env: got %q", got)
+	}
+
+	// Disabled entries point at $HOME.
+	t.Setenv("XDG_DOWNLOAD_DIR", "$HOME")
+	if got, _ := DefaultDir(); got != filepath.Join(home, "Downloads") {
+		t.Fatalf("disabled: got %q", got)
+	}
+}
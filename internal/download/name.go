@@ -0,0 +1,109 @@
+package download
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/steipete/gifgrep/internal/model"
+)
+
+var namePlaceholder = regexp.MustCompile(`\{([a-z]*)\}`)
+
+// NameFields lists the placeholders a filename template may use.
+var NameFields = []string{"source", "id", "title", "ext", "width", "height"}
+
+// ValidateName checks a filename template: known {placeholders} only, and a
+// relative path that stays inside the download directory ("/" makes
+// subdirectories).
+func ValidateName(tmpl string) error {
+	if strings.TrimSpace(tmpl) == "" {
+		return fmt.Errorf("empty filename template")
+	}
+	for _, m := range namePlaceholder.FindAllStringSubmatch(tmpl, -1) {
+		if !knownNameField(m[1]) {
+			return fmt.Errorf("filename template: unknown placeholder %s (use {%s})", m[0], strings.Join(NameFields, "}, {"))
+		}
+	}
+	if filepath.IsAbs(tmpl) || strings.HasPrefix(tmpl, "/") {
+		return fmt.Errorf("filename template must be relative to the download directory")
+	}
+	for _, part := range strings.Split(tmpl, "/") {
+		if part == ".." {
+			return fmt.Errorf("filename template may not contain ..")
+		}
+	}
+	return nil
+}
+
+func knownNameField(name string) bool {
+	for _, f := range NameFields {
+		if f == name {
+			return true
+		}
+	}
+	return false
+}
+
+// renderName expands tmpl for item. Every value is sanitized like a title,
+// so only the template's own "/" separators create directories.
+func renderName(tmpl string, item model.Result, source string) (string, error) {
+	if err := ValidateName(tmpl); err != nil {
+		return "", err
+	}
+	ext := resultExt(item)
+	title := strings.Join(strings.Fields(item.Title), " ")
+	if title == "" {
+		title = item.ID
+	}
+	values := map[string]string{
+		"source": source,
+		"id":     item.ID,
+		"title":  title,
+		"ext":    ext,
+		"width":  strconv.Itoa(item.Width),
+		"height": strconv.Itoa(item.Height),
+	}
+	name := namePlaceholder.ReplaceAllStringFunc(tmpl, func(m string) string {
+		v := strings.TrimSpace(values[m[1:len(m)-1]])
+		if v == "" {
+			return "unknown"
+		}
+		return sanitizeFilename(v)
+	})
+
+	parts := strings.Split(name, "/")
+	kept := parts[:0]
+	for _, p := range parts {
+		if p != "" && p != "." {
+			kept = append(kept, p)
+		}
+	}
+	if len(kept) == 0 {
+		return "", fmt.Errorf("filename template %q produced an empty name", tmpl)
+	}
+	base := kept[len(kept)-1]
+	if filepath.Ext(base) == "" {
+		base += "." + ext
+	}
+	kept[len(kept)-1] = truncateName(base)
+	return filepath.Join(kept...), nil
+}
+
+// resultExt is the media extension from the result URL, defaulting to gif.
+func resultExt(item model.Result) string {
+	parsed, err := url.Parse(item.URL)
+	if err != nil {
+		return "gif"
+	}
+	switch ext := strings.ToLower(strings.TrimPrefix(path.Ext(parsed.Path), ".")); ext {
+	case "gif", "webp", "mp4", "png":
+		return ext
+	default:
+		return "gif"
+	}
+}
@@ -0,0 +1,59 @@
+package download
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/steipete/gifgrep/internal/model"
+)
+
+func TestRenderName(t *testing.T) {
+	t.Parallel()
+
+	item := model.Result{ID: "abc", Title: "Cat / typing: fast!", URL: "https://example.test/x/cat.WEBP?y=1", Width: 320, Height: 240}
+	cases := []struct{ tmpl, want string }{
+		{"{source}-{id}-{title}.{ext}", "giphy-abc-Cat___typing__fast.webp"},
+		{"{source}/{id}", filepath.Join("giphy", "abc.webp")},
+		{"{id}_{width}x{height}.gif", "abc_320x240.gif"},
+		{"./{title}", "Cat___typing__fast.webp"},
+	}
+	for _, tc := range cases {
+		got, err := renderName(tc.tmpl, item, "giphy")
+		if err != nil {
+			t.Fatalf("%s: %v", tc.tmpl, err)
+		}
+		if got != tc.want {
+			t.Fatalf("%s: got %q, want %q", tc.tmpl, got, tc.want)
+		}
+	}
+
+	got, err := renderName("{source}-{title}", model.Result{URL: "https://example.test/a"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "unknown-unknown.gif" {
+		t.Fatalf("empty values: %q", got)
+	}
+
+	long, err := renderName("{title}.{ext}", model.Result{Title: strings.Repeat("a", 200)}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(long) > 80 || !strings.HasSuffix(long, ".gif") {
+		t.Fatalf("expected a capped .gif name, got %q", long)
+	}
+}
+
+func TestValidateName(t *testing.T) {
+	t.Parallel()
+
+	for _, bad := range []string{"", "{nope}.gif", "/abs/{id}", "../{id}", "a/../../{id}"} {
+		if err := ValidateName(bad); err == nil {
+			t.Fatalf("expected %q to be rejected", bad)
+		}
+	}
+	if err := ValidateName("{source}/{id}-{title}.{ext}"); err != nil {
+		t.Fatalf("valid template rejected: %v", err)
+	}
+}
@@ -0,0 +1,115 @@
+package download
+
+import (
+	"bytes"
+	"os"
+	"strings"
+
+	"github.com/steipete/gifgrep/gifdecode"
+	"github.com/steipete/gifgrep/internal/model"
+)
+
+// Provenance records where a downloaded file came from.
+type Provenance struct {
+	Source string   `json:"source,omitempty"`
+	ID     string   `json:"id,omitempty"`
+	Title  string   `json:"title,omitempty"`
+	Tags   []string `json:"tags,omitempty"`
+	URL    string   `json:"url,omitempty"`
+}
+
+// provenanceHeader starts the GIF comment gifgrep writes; the rest are
+// "key: value" lines.
+const provenanceHeader = "gifgrep provenance"
+
+func provenanceFor(item model.Result, source string) Provenance {
+	return Provenance{Source: source, ID: item.ID, Title: item.Title, Tags: item.Tags, URL: item.URL}
+}
+
+func (p Provenance) comment() string {
+	var b strings.Builder
+	b.WriteString(provenanceHeader)
+	line := func(key, value string) {
+		if value = strings.Join(strings.Fields(value), " "); value != "" {
+			b.WriteString("\n" + key + ": " + value)
+		}
+	}
+	line("source", p.Source)
+	line("id", p.ID)
+	line("title", p.Title)
+	line("tags", strings.Join(p.Tags, ", "))
+	line("url", p.URL)
+	return b.String()
+}
+
+// ReadProvenance finds gifgrep's provenance in a file's GIF comments, falling
+// back to the user.xdg.origin.url attribute of the local file at path (which
+// may be empty or a URL).
+func ReadProvenance(path string, comments []string) (Provenance, bool) {
+	for _, c := range comments {
+		if p, ok := ParseProvenance(c); ok {
+			return p, true
+		}
+	}
+	if path != "" {
+		if u := originURL(path); u != "" {
+			return Provenance{URL: u}, true
+		}
+	}
+	return Provenance{}, false
+}
+
+// ParseProvenance reads a comment written by embedProvenance.
+func ParseProvenance(comment string) (Provenance, bool) {
+	lines := strings.Split(comment, "\n")
+	if strings.TrimSpace(lines[0]) != provenanceHeader {
+		return Provenance{}, false
+	}
+	var p Provenance
+	for _, line := range lines[1:] {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch strings.TrimSpace(key) {
+		case "source":
+			p.Source = value
+		case "id":
+			p.ID = value
+		case "title":
+			p.Title = value
+		case "tags":
+			for _, tag := range strings.Split(value, ",") {
+				if tag = strings.TrimSpace(tag); tag != "" {
+					p.Tags = append(p.Tags, tag)
+				}
+			}
+		case "url":
+			p.URL = value
+		}
+	}
+	return p, true
+}
+
+// embedProvenance adds a provenance comment to the GIF at path (other formats
+// are left alone) and sets its origin URL attribute where supported.
+func embedProvenance(path string, p Provenance) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if bytes.HasPrefix(data, []byte("GIF8")) {
+		tagged, err := gifdecode.AddComment(data, p.comment())
+		if err == nil {
+			if err := os.WriteFile(path, tagged, 0o644); err != nil {
+				return err
+			}
+		}
+	}
+	if p.URL != "" {
+		// Not every file system takes user xattrs.
+		_ = setOriginURL(path, p.URL)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package download
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/steipete/gifgrep/gifdecode"
+	"github.com/steipete/gifgrep/internal/model"
+	"github.com/steipete/gifgrep/internal/testutil"
+)
+
+func TestSaveEmbedsProvenance(t *testing.T) {
+	t.Parallel()
+
+	gifData := testutil.MakeTestGIF()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write(gifData)
+	}))
+	t.Cleanup(srv.Close)
+
+	dir := t.TempDir()
+	item := model.Result{ID: "42", Title: "Cat\ntyping", Tags: []string{"cat", "keyboard"}, URL: srv.URL + "/cat.gif"}
+	saved, err := Save(item, Options{Dir: dir, Source: "tenor", Metadata: true, Dedup: DedupSkip})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(saved.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := gifdecode.Inspect(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, ok := ReadProvenance("", info.Comments)
+	want := Provenance{Source: "tenor", ID: "42", Title: "Cat typing", Tags: []string{"cat", "keyboard"}, URL: item.URL}
+	if !ok || !reflect.DeepEqual(got, want) {
+		t.Fatalf("provenance = %+v, want %+v", got, want)
+	}
+	if u := originURL(saved.Path); u != "" && u != item.URL {
+		t.Fatalf("origin xattr = %q", u)
+	}
+
+	// The comment must not hide that a second provider serves the same bytes.
+	again, err := Save(model.Result{ID: "g1", URL: srv.URL + "/other.gif"}, Options{Dir: dir, Source: "giphy", Metadata: true, Dedup: DedupSkip})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Duplicate != saved.Path {
+		t.Fatalf("expected duplicate of %s, got %+v", saved.Path, again)
+	}
+}
+
+func TestEmbedProvenanceLeavesOtherFormats(t *testing.T) {
+	t.Parallel()
+
+	p := filepath.Join(t.TempDir(), "clip.mp4")
+	if err := os.WriteFile(p, []byte("not a gif"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := embedProvenance(p, Provenance{ID: "1"}); err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := os.ReadFile(p); string(b) != "not a gif" {
+		t.Fatalf("file changed: %q", b)
+	}
+}
+
+func TestParseProvenanceIgnoresOtherComments(t *testing.T) {
+	t.Parallel()
+
+	if _, ok := ParseProvenance("Made with GIMP"); ok {
+		t.Fatalf("expected foreign comment to be ignored")
+	}
+	p, ok := ParseProvenance(Provenance{Source: "giphy", URL: "https://x.test/a.gif"}.comment())
+	if !ok || p.Source != "giphy" || p.URL != "https://x.test/a.gif" || p.ID != "" {
+		t.Fatalf("parsed %+v %v", p, ok)
+	}
+}
@@ -0,0 +1,21 @@
+//go:build linux
+
+package download
+
+import "golang.org/x/sys/unix"
+
+// originAttr is the freedesktop.org attribute browsers set on downloads.
+const originAttr = "user.xdg.origin.url"
+
+func setOriginURL(path, url string) error {
+	return unix.Setxattr(path, originAttr, []byte(url), 0)
+}
+
+func originURL(path string) string {
+	buf := make([]byte, 4096)
+	n, err := unix.Getxattr(path, originAttr, buf)
+	if err != nil || n <= 0 {
+		return ""
+	}
+	return string(buf[:n])
+}
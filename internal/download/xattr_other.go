@@ -0,0 +1,8 @@
+//go:build !linux
+
+package download
+
+// Extended attributes are only written on Linux.
+func setOriginURL(_, _ string) error { return nil }
+
+func originURL(_ string) string { return "" }
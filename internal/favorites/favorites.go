@@ -0,0 +1,183 @@
+// Package favorites keeps named collections of search results in a JSON file
+// under the XDG data directory.
+package favorites
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/steipete/gifgrep/internal/model"
+)
+
+// DefaultCollection is used when no collection is named.
+const DefaultCollection = "favorites"
+
+// Entry is a saved result and the provider it came from.
+type Entry struct {
+	model.Result
+	Source string    `json:"source,omitempty"`
+	Added  time.Time `json:"added"`
+}
+
+// Store is the collections file. Load it, change it, then Save it; every
+// command reloads so concurrent gifgrep processes only race on a write.
+type Store struct {
+	Path        string             `json:"-"`
+	Collections map[string][]Entry `json:"collections"`
+}
+
+var nowFn = time.Now
+
+// DefaultPath returns favorites.json under $XDG_DATA_HOME/gifgrep, else
+// ~/.local/share/gifgrep.
+func DefaultPath() (string, error) {
+	if dir := strings.TrimSpace(os.Getenv("XDG_DATA_HOME")); dir != "" {
+		return filepath.Join(dir, "gifgrep", "favorites.json"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "share", "gifgrep", "favorites.json"), nil
+}
+
+// Load reads the store at path. A missing file is an empty store.
+func Load(path string) (*Store, error) {
+	s := &Store{Path: path, Collections: map[string][]Entry{}}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if s.Collections == nil {
+		s.Collections = map[string][]Entry{}
+	}
+	return s, nil
+}
+
+// Save writes the store atomically, creating its directory.
+func (s *Store) Save() error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(s.Path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, filepath.Base(s.Path)+".*")
+	if err != nil {
+		return err
+	}
+	_, werr := tmp.Write(append(data, '\n'))
+	cerr := tmp.Close()
+	if werr == nil {
+		werr = cerr
+	}
+	if werr == nil {
+		werr = os.Rename(tmp.Name(), s.Path)
+	}
+	if werr != nil {
+		_ = os.Remove(tmp.Name())
+	}
+	return werr
+}
+
+// ValidateName rejects empty names and names with control characters.
+func ValidateName(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return errors.New("empty collection name")
+	}
+	for _, r := range name {
+		if unicode.IsControl(r) {
+			return fmt.Errorf("collection name %q contains a control character", name)
+		}
+	}
+	return nil
+}
+
+// Names returns the collection names, sorted.
+func (s *Store) Names() []string {
+	names := make([]string, 0, len(s.Collections))
+	for name := range s.Collections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Add appends res to collection unless it is already there and reports
+// whether it did.
+func (s *Store) Add(collection string, res model.Result, source string) (bool, error) {
+	if err := ValidateName(collection); err != nil {
+		return false, err
+	}
+	if res.URL == "" {
+		return false, errors.New("result has no URL")
+	}
+	if s.Contains(collection, res, source) {
+		return false, nil
+	}
+	s.Collections[collection] = append(s.Collections[collection], Entry{Result: res, Source: source, Added: nowFn().UTC()})
+	return true, nil
+}
+
+// Contains reports whether collection holds res: the same URL, or the same
+// ID from the same provider.
+func (s *Store) Contains(collection string, res model.Result, source string) bool {
+	for _, e := range s.Collections[collection] {
+		if e.matches(res, source) {
+			return true
+		}
+	}
+	return false
+}
+
+// Remove drops res from collection and reports whether it was there. An
+// emptied collection is deleted.
+func (s *Store) Remove(collection string, res model.Result, source string) bool {
+	return s.removeWhere(collection, func(e Entry) bool { return e.matches(res, source) }) > 0
+}
+
+// RemoveKey drops the entries whose ID or URL is key and returns how many
+// there were.
+func (s *Store) RemoveKey(collection, key string) int {
+	return s.removeWhere(collection, func(e Entry) bool { return e.ID == key || e.URL == key })
+}
+
+func (s *Store) removeWhere(collection string, drop func(Entry) bool) int {
+	entries := s.Collections[collection]
+	kept := entries[:0]
+	for _, e := range entries {
+		if !drop(e) {
+			kept = append(kept, e)
+		}
+	}
+	removed := len(entries) - len(kept)
+	if len(kept) == 0 {
+		delete(s.Collections, collection)
+	} else {
+		s.Collections[collection] = kept
+	}
+	return removed
+}
+
+func (e Entry) matches(res model.Result, source string) bool {
+	if res.URL != "" && e.URL == res.URL {
+		return true
+	}
+	return res.ID != "" && e.ID == res.ID && e.Source == source
+}
@@ -0,0 +1,85 @@
+package favorites
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/steipete/gifgrep/internal/model"
+)
+
+func TestDefaultPath(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", "/data")
+	if p, _ := DefaultPath(); p != filepath.Join("/data", "gifgrep", "favorites.json") {
+		t.Fatalf("xdg: %q", p)
+	}
+	t.Setenv("XDG_DATA_HOME", "")
+	t.Setenv("HOME", "/home/tester")
+	if p, _ := DefaultPath(); p != filepath.Join("/home/tester", ".local", "share", "gifgrep", "favorites.json") {
+		t.Fatalf("home: %q", p)
+	}
+}
+
+func TestAddRemoveRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sub", "favorites.json")
+	s, err := Load(path)
+	if err != nil || len(s.Names()) != 0 {
+		t.Fatalf("load missing: %v %v", s, err)
+	}
+
+	cat := model.Result{ID: "1", Title: "Cat", URL: "https://x.test/1.gif", Tags: []string{"cat"}}
+	if added, err := s.Add(DefaultCollection, cat, "tenor"); !added || err != nil {
+		t.Fatalf("add: %v %v", added, err)
+	}
+	if added, _ := s.Add(DefaultCollection, model.Result{ID: "1", URL: "https://x.test/other.gif"}, "tenor"); added {
+		t.Fatalf("same id and source should not be added twice")
+	}
+	if added, _ := s.Add(DefaultCollection, model.Result{ID: "1", URL: "https://y.test/1.gif"}, "giphy"); !added {
+		t.Fatalf("same id from another provider is a different GIF")
+	}
+	if _, err := s.Add("work", cat, "tenor"); err != nil {
+		t.Fatalf("add work: %v", err)
+	}
+	if _, err := s.Add("", cat, "tenor"); err == nil {
+		t.Fatalf("expected empty name error")
+	}
+	if _, err := s.Add("x", model.Result{ID: "2"}, "tenor"); err == nil {
+		t.Fatalf("expected missing URL error")
+	}
+	if err := s.Save(); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if !reflect.DeepEqual(loaded.Names(), []string{"favorites", "work"}) {
+		t.Fatalf("names: %v", loaded.Names())
+	}
+	got := loaded.Collections[DefaultCollection][0]
+	if !reflect.DeepEqual(got.Result, cat) || got.Source != "tenor" || got.Added.IsZero() {
+		t.Fatalf("entry: %+v", got)
+	}
+
+	if n := loaded.RemoveKey(DefaultCollection, "1"); n != 2 {
+		t.Fatalf("RemoveKey removed %d", n)
+	}
+	if _, ok := loaded.Collections[DefaultCollection]; ok {
+		t.Fatalf("emptied collection should be deleted")
+	}
+	if !loaded.Remove("work", model.Result{URL: cat.URL}, "") || loaded.Remove("work", cat, "tenor") {
+		t.Fatalf("Remove by URL")
+	}
+}
+
+func TestLoadRejectsBadJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "favorites.json")
+	if err := os.WriteFile(path, []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Fatalf("expected parse error")
+	}
+}
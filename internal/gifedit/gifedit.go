@@ -0,0 +1,188 @@
+package gifedit
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/draw"
+	"image/gif"
+	"image/png"
+	"time"
+
+	"github.com/steipete/gifgrep/gifdecode"
+	"github.com/steipete/gifgrep/internal/stills"
+)
+
+var (
+	ErrNoFrames    = errors.New("no frames")
+	ErrInvalidCrop = errors.New("crop is outside the image")
+	ErrInvalidSize = errors.New("invalid output size")
+)
+
+const maxOutputSide = 4096
+
+type Options struct {
+	Start time.Duration
+	// End is inclusive; zero keeps everything through the last frame.
+	End time.Duration
+	// Crop is applied before resizing; an empty rectangle keeps the canvas.
+	Crop image.Rectangle
+	// Width and Height set the output size. When only one is set the other
+	// follows the aspect ratio.
+	Width  int
+	Height int
+	// Colors caps the palette size (2-256); zero means 256.
+	Colors    int
+	LoopCount int
+	NoDither  bool
+}
+
+// Encode trims, crops and resizes decoded frames and re-encodes them as a GIF
+// with a shared quantised palette.
+func Encode(decoded *gifdecode.Frames, opts Options) ([]byte, error) {
+	if decoded == nil || len(decoded.Frames) == 0 {
+		return nil, ErrNoFrames
+	}
+	first, last, err := stills.FrameRange(decoded.Frames, opts.Start, opts.End)
+	if err != nil {
+		return nil, err
+	}
+
+	canvas := image.Rect(0, 0, decoded.Width, decoded.Height)
+	crop := canvas
+	if !opts.Crop.Empty() {
+		crop = opts.Crop
+		if !crop.In(canvas) {
+			return nil, ErrInvalidCrop
+		}
+	}
+	outW, outH, err := outputSize(crop.Dx(), crop.Dy(), opts.Width, opts.Height)
+	if err != nil {
+		return nil, err
+	}
+
+	frames := make([]*image.RGBA, 0, last-first+1)
+	delays := make([]time.Duration, 0, last-first+1)
+	for i := first; i <= last; i++ {
+		img, err := png.Decode(bytes.NewReader(decoded.Frames[i].PNG))
+		if err != nil {
+			return nil, err
+		}
+		frames = append(frames, scale(img, crop, outW, outH))
+		delays = append(delays, decoded.Frames[i].Delay)
+	}
+
+	pal := buildPalette(frames, opts.Colors)
+	transparent := hasTransparency(frames)
+
+	out := &gif.GIF{
+		Image:     make([]*image.Paletted, 0, len(frames)),
+		Delay:     make([]int, 0, len(frames)),
+		Disposal:  make([]byte, 0, len(frames)),
+		LoopCount: opts.LoopCount,
+		Config: image.Config{
+			Width:      outW,
+			Height:     outH,
+			ColorModel: pal,
+		},
+	}
+	// Every frame covers the whole canvas. With transparency the previous
+	// frame must be cleared, otherwise it would show through.
+	disposal := byte(gif.DisposalNone)
+	if transparent {
+		disposal = gif.DisposalBackground
+	}
+	drawer := draw.Drawer(draw.FloydSteinberg)
+	if opts.NoDither {
+		drawer = draw.Src
+	}
+	for i, frame := range frames {
+		dst := image.NewPaletted(frame.Bounds(), pal)
+		drawer.Draw(dst, dst.Bounds(), frame, image.Point{})
+		out.Image = append(out.Image, dst)
+		out.Delay = append(out.Delay, delayCentiseconds(delays[i]))
+		out.Disposal = append(out.Disposal, disposal)
+	}
+
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, out); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func outputSize(srcW, srcH, width, height int) (int, int, error) {
+	if srcW <= 0 || srcH <= 0 || width < 0 || height < 0 {
+		return 0, 0, ErrInvalidSize
+	}
+	switch {
+	case width == 0 && height == 0:
+		width, height = srcW, srcH
+	case height == 0:
+		height = max(1, (srcH*width+srcW/2)/srcW)
+	case width == 0:
+		width = max(1, (srcW*height+srcH/2)/srcH)
+	}
+	if width > maxOutputSide || height > maxOutputSide {
+		return 0, 0, ErrInvalidSize
+	}
+	return width, height, nil
+}
+
+func delayCentiseconds(d time.Duration) int {
+	cs := int((d + 5*time.Millisecond) / (10 * time.Millisecond))
+	if cs < 1 {
+		return 1
+	}
+	return cs
+}
+
+func hasTransparency(frames []*image.RGBA) bool {
+	for _, f := range frames {
+		for i := 3; i < len(f.Pix); i += 4 {
+			if f.Pix[i] < 0x80 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// scale crops src to rect and resamples it to w×h with a box filter when
+// shrinking and nearest-neighbour when enlarging.
+func scale(src image.Image, rect image.Rectangle, w, h int) *image.RGBA {
+	rgba := image.NewRGBA(image.Rect(0, 0, rect.Dx(), rect.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), src, rect.Min, draw.Src)
+	if w == rect.Dx() && h == rect.Dy() {
+		return rgba
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	sw, sh := rect.Dx(), rect.Dy()
+	for y := 0; y < h; y++ {
+		y0 := y * sh / h
+		y1 := max(y0+1, (y+1)*sh/h)
+		for x := 0; x < w; x++ {
+			x0 := x * sw / w
+			x1 := max(x0+1, (x+1)*sw/w)
+			var r, g, b, a, n uint32
+			for sy := y0; sy < y1; sy++ {
+				off := sy*rgba.Stride + x0*4
+				for sx := x0; sx < x1; sx++ {
+					r += uint32(rgba.Pix[off])
+					g += uint32(rgba.Pix[off+1])
+					b += uint32(rgba.Pix[off+2])
+					a += uint32(rgba.Pix[off+3])
+					n++
+					off += 4
+				}
+			}
+			i := y*dst.Stride + x*4
+			dst.Pix[i] = uint8(r / n)
+			dst.Pix[i+1] = uint8(g / n)
+			dst.Pix[i+2] = uint8(b / n)
+			dst.Pix[i+3] = uint8(a / n)
+		}
+	}
+	return dst
+}
@@ -0,0 +1,142 @@
+package gifedit
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"image/png"
+	"testing"
+	"time"
+
+	"github.com/steipete/gifgrep/gifdecode"
+)
+
+func makeFrames(t *testing.T, colors []color.Color, w, h int, delay time.Duration) *gifdecode.Frames {
+	t.Helper()
+	out := &gifdecode.Frames{Width: w, Height: h}
+	for _, c := range colors {
+		img := image.NewRGBA(image.Rect(0, 0, w, h))
+		draw.Draw(img, img.Bounds(), &image.Uniform{C: c}, image.Point{}, draw.Src)
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, img); err != nil {
+			t.Fatalf("png encode: %v", err)
+		}
+		out.Frames = append(out.Frames, gifdecode.Frame{PNG: buf.Bytes(), Delay: delay})
+	}
+	return out
+}
+
+func decodeGIF(t *testing.T, data []byte) *gif.GIF {
+	t.Helper()
+	g, err := gif.DecodeAll(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("gif decode: %v", err)
+	}
+	return g
+}
+
+func TestEncodeTrimCropResize(t *testing.T) {
+	red := color.RGBA{R: 0xff, A: 0xff}
+	green := color.RGBA{G: 0xff, A: 0xff}
+	blue := color.RGBA{B: 0xff, A: 0xff}
+	decoded := makeFrames(t, []color.Color{red, green, blue}, 8, 4, 100*time.Millisecond)
+
+	out, err := Encode(decoded, Options{
+		Start:     100 * time.Millisecond,
+		End:       200 * time.Millisecond,
+		Crop:      image.Rect(2, 0, 6, 4),
+		Width:     2,
+		LoopCount: 0,
+	})
+	if err != nil {
+		t.Fatalf("encode failed: %v", err)
+	}
+	g := decodeGIF(t, out)
+	if len(g.Image) != 2 {
+		t.Fatalf("expected 2 frames, got %d", len(g.Image))
+	}
+	if g.Config.Width != 2 || g.Config.Height != 2 {
+		t.Fatalf("unexpected size %dx%d", g.Config.Width, g.Config.Height)
+	}
+	if g.Delay[0] != 10 || g.Delay[1] != 10 {
+		t.Fatalf("unexpected delays %v", g.Delay)
+	}
+	if r, gg, b, _ := g.Image[0].At(0, 0).RGBA(); r != 0 || gg != 0xffff || b != 0 {
+		t.Fatalf("expected first kept frame to be green, got %d %d %d", r, gg, b)
+	}
+	if r, gg, b, _ := g.Image[1].At(1, 1).RGBA(); r != 0 || gg != 0 || b != 0xffff {
+		t.Fatalf("expected second kept frame to be blue, got %d %d %d", r, gg, b)
+	}
+	if g.Disposal[0] != gif.DisposalNone {
+		t.Fatalf("expected DisposalNone for opaque frames, got %d", g.Disposal[0])
+	}
+}
+
+func TestEncodeTransparencyUsesBackgroundDisposal(t *testing.T) {
+	decoded := makeFrames(t, []color.Color{color.Transparent, color.White}, 2, 2, 50*time.Millisecond)
+	out, err := Encode(decoded, Options{LoopCount: 2})
+	if err != nil {
+		t.Fatalf("encode failed: %v", err)
+	}
+	g := decodeGIF(t, out)
+	if g.LoopCount != 2 {
+		t.Fatalf("expected loop count 2, got %d", g.LoopCount)
+	}
+	if g.Disposal[0] != gif.DisposalBackground {
+		t.Fatalf("expected DisposalBackground, got %d", g.Disposal[0])
+	}
+	if _, _, _, a := g.Image[0].At(0, 0).RGBA(); a != 0 {
+		t.Fatalf("expected transparent pixel, got alpha %d", a)
+	}
+}
+
+func TestEncodePaletteLimit(t *testing.T) {
+	colors := make([]color.Color, 0, 16)
+	for i := 0; i < 16; i++ {
+		colors = append(colors, color.RGBA{R: uint8(i * 16), G: uint8(255 - i*16), B: uint8(i * 8), A: 0xff})
+	}
+	decoded := makeFrames(t, colors, 2, 2, 20*time.Millisecond)
+	out, err := Encode(decoded, Options{Colors: 4, NoDither: true})
+	if err != nil {
+		t.Fatalf("encode failed: %v", err)
+	}
+	g := decodeGIF(t, out)
+	pal, ok := g.Config.ColorModel.(color.Palette)
+	if !ok {
+		t.Fatalf("expected global palette")
+	}
+	if len(pal) > 4 {
+		t.Fatalf("expected at most 4 colors, got %d", len(pal))
+	}
+}
+
+func TestEncodeErrors(t *testing.T) {
+	if _, err := Encode(nil, Options{}); !errors.Is(err, ErrNoFrames) {
+		t.Fatalf("expected ErrNoFrames, got %v", err)
+	}
+	decoded := makeFrames(t, []color.Color{color.White}, 4, 4, 10*time.Millisecond)
+	if _, err := Encode(decoded, Options{Crop: image.Rect(2, 2, 8, 8)}); !errors.Is(err, ErrInvalidCrop) {
+		t.Fatalf("expected ErrInvalidCrop, got %v", err)
+	}
+	if _, err := Encode(decoded, Options{Width: maxOutputSide + 1}); !errors.Is(err, ErrInvalidSize) {
+		t.Fatalf("expected ErrInvalidSize, got %v", err)
+	}
+}
+
+func TestOutputSizeKeepsAspect(t *testing.T) {
+	w, h, err := outputSize(400, 200, 100, 0)
+	if err != nil || w != 100 || h != 50 {
+		t.Fatalf("unexpected size %dx%d (%v)", w, h, err)
+	}
+	w, h, err = outputSize(400, 200, 0, 20)
+	if err != nil || w != 40 || h != 20 {
+		t.Fatalf("unexpected size %dx%d (%v)", w, h, err)
+	}
+	w, h, err = outputSize(400, 200, 0, 0)
+	if err != nil || w != 400 || h != 200 {
+		t.Fatalf("unexpected size %dx%d (%v)", w, h, err)
+	}
+}
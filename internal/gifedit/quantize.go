@@ -0,0 +1,174 @@
+package gifedit
+
+import (
+	"image"
+	"image/color"
+	"sort"
+)
+
+// colorBox is a median-cut bucket of histogram entries.
+type colorBox struct {
+	entries []histEntry
+}
+
+type histEntry struct {
+	r, g, b uint8
+	count   int
+}
+
+// buildPalette picks up to maxColors colors for all frames with median cut.
+// Fully transparent pixels are skipped; when any exist, one slot is reserved
+// for color.Transparent.
+func buildPalette(frames []*image.RGBA, maxColors int) color.Palette {
+	if maxColors <= 0 || maxColors > 256 {
+		maxColors = 256
+	}
+	if maxColors < 2 {
+		maxColors = 2
+	}
+
+	// Histogram on 5 bits per channel keeps the map small for large inputs.
+	hist := map[uint16]*histEntry{}
+	transparent := false
+	for _, f := range frames {
+		for i := 0; i+3 < len(f.Pix); i += 4 {
+			a := f.Pix[i+3]
+			if a < 0x80 {
+				transparent = true
+				continue
+			}
+			r, g, b := unpremultiply(f.Pix[i], a), unpremultiply(f.Pix[i+1], a), unpremultiply(f.Pix[i+2], a)
+			key := uint16(r>>3)<<10 | uint16(g>>3)<<5 | uint16(b>>3)
+			e := hist[key]
+			if e == nil {
+				e = &histEntry{r: r, g: g, b: b}
+				hist[key] = e
+			}
+			e.count++
+		}
+	}
+
+	slots := maxColors
+	if transparent {
+		slots--
+	}
+	entries := make([]histEntry, 0, len(hist))
+	for _, e := range hist {
+		entries = append(entries, *e)
+	}
+	// Map iteration order is random; sort so output is deterministic.
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.r != b.r {
+			return a.r < b.r
+		}
+		if a.g != b.g {
+			return a.g < b.g
+		}
+		return a.b < b.b
+	})
+
+	pal := color.Palette{}
+	if transparent {
+		pal = append(pal, color.Transparent)
+	}
+	if len(entries) == 0 {
+		return append(pal, color.Black)
+	}
+	for _, box := range medianCut(entries, slots) {
+		pal = append(pal, box.average())
+	}
+	return pal
+}
+
+func medianCut(entries []histEntry, slots int) []colorBox {
+	boxes := []colorBox{{entries: entries}}
+	for len(boxes) < slots {
+		idx := -1
+		widest := 0
+		for i, box := range boxes {
+			if len(box.entries) < 2 {
+				continue
+			}
+			if _, w := box.widestChannel(); w > widest {
+				idx, widest = i, w
+			}
+		}
+		if idx < 0 {
+			break
+		}
+		a, b := boxes[idx].split()
+		boxes[idx] = a
+		boxes = append(boxes, b)
+	}
+	return boxes
+}
+
+func (b colorBox) widestChannel() (int, int) {
+	lo := [3]uint8{255, 255, 255}
+	hi := [3]uint8{}
+	for _, e := range b.entries {
+		for c, v := range [3]uint8{e.r, e.g, e.b} {
+			lo[c] = min(lo[c], v)
+			hi[c] = max(hi[c], v)
+		}
+	}
+	channel, width := 0, 0
+	for c := 0; c < 3; c++ {
+		if w := int(hi[c]) - int(lo[c]); w > width {
+			channel, width = c, w
+		}
+	}
+	return channel, width
+}
+
+// split sorts along the widest channel and cuts at the weighted median.
+func (b colorBox) split() (colorBox, colorBox) {
+	channel, _ := b.widestChannel()
+	value := func(e histEntry) uint8 {
+		switch channel {
+		case 0:
+			return e.r
+		case 1:
+			return e.g
+		default:
+			return e.b
+		}
+	}
+	sort.SliceStable(b.entries, func(i, j int) bool { return value(b.entries[i]) < value(b.entries[j]) })
+
+	total := 0
+	for _, e := range b.entries {
+		total += e.count
+	}
+	cut, seen := 1, 0
+	for i, e := range b.entries[:len(b.entries)-1] {
+		seen += e.count
+		if seen*2 >= total {
+			cut = i + 1
+			break
+		}
+	}
+	return colorBox{entries: b.entries[:cut]}, colorBox{entries: b.entries[cut:]}
+}
+
+func (b colorBox) average() color.RGBA {
+	var r, g, bl, n int
+	for _, e := range b.entries {
+		r += int(e.r) * e.count
+		g += int(e.g) * e.count
+		bl += int(e.b) * e.count
+		n += e.count
+	}
+	if n == 0 {
+		return color.RGBA{A: 0xff}
+	}
+	return color.RGBA{R: uint8(r / n), G: uint8(g / n), B: uint8(bl / n), A: 0xff}
+}
+
+func unpremultiply(v, a uint8) uint8 {
+	if a == 0xff || a == 0 {
+		return v
+	}
+	return uint8(min(255, int(v)*255/int(a)))
+}
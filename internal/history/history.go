@@ -0,0 +1,153 @@
+// Package history records past searches, one JSON object per line, under the
+// XDG state directory.
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// MaxEntries bounds the file; older entries are dropped on append.
+const MaxEntries = 1000
+
+// Entry is one search.
+type Entry struct {
+	Query   string    `json:"query"`
+	Source  string    `json:"source,omitempty"`
+	Results int       `json:"results"`
+	Time    time.Time `json:"time"`
+}
+
+// DefaultPath returns history.jsonl under $XDG_STATE_HOME/gifgrep, else
+// ~/.local/state/gifgrep.
+func DefaultPath() (string, error) {
+	if dir := strings.TrimSpace(os.Getenv("XDG_STATE_HOME")); dir != "" {
+		return filepath.Join(dir, "gifgrep", "history.jsonl"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "state", "gifgrep", "history.jsonl"), nil
+}
+
+// Load returns the entries at path, oldest first. A missing file is empty;
+// unreadable lines (say, from a write cut short) are skipped.
+func Load(path string) ([]Entry, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	var entries []Entry
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for sc.Scan() {
+		var e Entry
+		if json.Unmarshal(sc.Bytes(), &e) != nil || strings.TrimSpace(e.Query) == "" {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	return entries, sc.Err()
+}
+
+// Append adds e to the file at path, creating it (0600) and its directory.
+// Once the file holds more than MaxEntries it is rewritten with the newest.
+func Append(path string, e Entry) error {
+	if strings.TrimSpace(e.Query) == "" {
+		return nil
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	_, werr := f.Write(append(line, '\n'))
+	if cerr := f.Close(); werr == nil {
+		werr = cerr
+	}
+	if werr != nil {
+		return werr
+	}
+
+	entries, err := Load(path)
+	if err != nil || len(entries) <= MaxEntries {
+		return err
+	}
+	return rewrite(path, entries[len(entries)-MaxEntries:])
+}
+
+// Clear deletes the history file.
+func Clear(path string) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// Queries returns the distinct queries, oldest first, each at the position
+// of its latest use.
+func Queries(entries []Entry) []string {
+	seen := map[string]bool{}
+	var rev []string
+	for i := len(entries) - 1; i >= 0; i-- {
+		q := entries[i].Query
+		if seen[q] {
+			continue
+		}
+		seen[q] = true
+		rev = append(rev, q)
+	}
+	out := make([]string, len(rev))
+	for i, q := range rev {
+		out[len(rev)-1-i] = q
+	}
+	return out
+}
+
+func rewrite(path string, entries []Entry) error {
+	var b strings.Builder
+	for _, e := range entries {
+		line, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		b.Write(line)
+		b.WriteByte('\n')
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	_, werr := tmp.WriteString(b.String())
+	if cerr := tmp.Close(); werr == nil {
+		werr = cerr
+	}
+	if werr == nil {
+		werr = os.Rename(tmp.Name(), path)
+	}
+	if werr != nil {
+		_ = os.Remove(tmp.Name())
+	}
+	return werr
+}
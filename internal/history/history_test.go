@@ -0,0 +1,78 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestDefaultPath(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", "/state")
+	if p, _ := DefaultPath(); p != filepath.Join("/state", "gifgrep", "history.jsonl") {
+		t.Fatalf("xdg: %q", p)
+	}
+	t.Setenv("XDG_STATE_HOME", "")
+	t.Setenv("HOME", "/home/tester")
+	if p, _ := DefaultPath(); p != filepath.Join("/home/tester", ".local", "state", "gifgrep", "history.jsonl") {
+		t.Fatalf("home: %q", p)
+	}
+}
+
+func TestAppendLoadClear(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "history.jsonl")
+	if entries, err := Load(path); err != nil || entries != nil {
+		t.Fatalf("missing file: %v %v", entries, err)
+	}
+	for _, e := range []Entry{{Query: "cats", Source: "tenor", Results: 20}, {Query: "  "}, {Query: "dogs", Source: "giphy", Results: 3}} {
+		if err := Append(path, e); err != nil {
+			t.Fatalf("append: %v", err)
+		}
+	}
+	// A torn final line is skipped, not fatal.
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	_, _ = f.WriteString(`{"query":"ca`)
+	_ = f.Close()
+
+	entries, err := Load(path)
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("load: %v %+v", err, entries)
+	}
+	if entries[0].Query != "cats" || entries[0].Results != 20 || entries[1].Source != "giphy" || entries[0].Time.IsZero() {
+		t.Fatalf("entries: %+v", entries)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0o600 {
+		t.Fatalf("mode %v", info.Mode().Perm())
+	}
+
+	if err := Clear(path); err != nil {
+		t.Fatalf("clear: %v", err)
+	}
+	if err := Clear(path); err != nil {
+		t.Fatalf("clear missing: %v", err)
+	}
+	if entries, _ := Load(path); len(entries) != 0 {
+		t.Fatalf("not cleared: %+v", entries)
+	}
+}
+
+func TestAppendTrimsToMaxEntries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	for i := 0; i < MaxEntries+5; i++ {
+		if err := Append(path, Entry{Query: "q" + strconv.Itoa(i)}); err != nil {
+			t.Fatalf("append: %v", err)
+		}
+	}
+	entries, _ := Load(path)
+	if len(entries) != MaxEntries || entries[0].Query != "q5" {
+		t.Fatalf("got %d entries, first %q", len(entries), entries[0].Query)
+	}
+}
+
+func TestQueriesDedupesKeepingLatest(t *testing.T) {
+	got := Queries([]Entry{{Query: "cats"}, {Query: "dogs"}, {Query: "cats"}, {Query: "owls"}})
+	if !reflect.DeepEqual(got, []string{"dogs", "cats", "owls"}) {
+		t.Fatalf("queries %v", got)
+	}
+}
@@ -4,10 +4,21 @@ import (
 	"bufio"
 	"encoding/base64"
 	"fmt"
+	"os"
 	"path"
+	"strconv"
 	"strings"
 )
 
+const (
+	// MultipartThreshold is the payload size above which SendFile splits the
+	// transfer; iTerm2 stalls while parsing one multi-megabyte escape.
+	MultipartThreshold = 256 << 10
+	// partSize is the raw bytes per FilePart; a multiple of 3 so every part
+	// is valid base64 on its own.
+	partSize = 48 << 10
+)
+
 type File struct {
 	Name        string
 	Data        []byte
@@ -16,12 +27,42 @@ type File struct {
 	Stretch     bool
 }
 
+// SendFile sends f in parts when multipart is supported and the payload is
+// large, and as a single sequence otherwise.
+func SendFile(out *bufio.Writer, f File, multipart bool) {
+	if multipart && len(f.Data) > MultipartThreshold {
+		SendMultipartFile(out, f)
+		return
+	}
+	SendInlineFile(out, f)
+}
+
 // SendInlineFile emits iTerm2's OSC 1337 inline file sequence.
 // Unitless width/height are in character cells.
 func SendInlineFile(out *bufio.Writer, f File) {
 	if out == nil || len(f.Data) == 0 {
 		return
 	}
+	encoded := base64.StdEncoding.EncodeToString(f.Data)
+	_, _ = fmt.Fprintf(out, "\x1b]1337;File=%s:%s\x1b\\", fileArgs(f), encoded)
+}
+
+// SendMultipartFile emits the iTerm2 3.5+ chunked form: MultipartFile with
+// the arguments, one FilePart per chunk, then FileEnd.
+func SendMultipartFile(out *bufio.Writer, f File) {
+	if out == nil || len(f.Data) == 0 {
+		return
+	}
+	_, _ = fmt.Fprintf(out, "\x1b]1337;MultipartFile=%s\x1b\\", fileArgs(f))
+	for data := f.Data; len(data) > 0; {
+		n := min(len(data), partSize)
+		_, _ = fmt.Fprintf(out, "\x1b]1337;FilePart=%s\x1b\\", base64.StdEncoding.EncodeToString(data[:n]))
+		data = data[n:]
+	}
+	_, _ = fmt.Fprint(out, "\x1b]1337;FileEnd\x1b\\")
+}
+
+func fileArgs(f File) string {
 	name := strings.TrimSpace(f.Name)
 	if name == "" {
 		name = "gifgrep.bin"
@@ -44,7 +85,49 @@ func SendInlineFile(out *bufio.Writer, f File) {
 	if f.HeightCells > 0 {
 		args = append(args, fmt.Sprintf("height=%d", f.HeightCells))
 	}
+	return strings.Join(args, ";")
+}
 
-	encoded := base64.StdEncoding.EncodeToString(f.Data)
-	_, _ = fmt.Fprintf(out, "\x1b]1337;File=%s:%s\x1b\\", strings.Join(args, ";"), encoded)
+// MultipartSupported reports whether the terminal is iTerm2 3.5 or newer,
+// seen directly or through tmux (LC_TERMINAL). GIFGREP_ITERM_MULTIPART=0|1
+// overrides.
+func MultipartSupported(getenv func(string) string) bool {
+	if getenv == nil {
+		getenv = os.Getenv
+	}
+	switch strings.ToLower(strings.TrimSpace(getenv("GIFGREP_ITERM_MULTIPART"))) {
+	case "1", "true", "yes":
+		return true
+	case "0", "false", "no":
+		return false
+	}
+	version := ""
+	switch {
+	case getenv("TERM_PROGRAM") == "iTerm.app":
+		version = getenv("TERM_PROGRAM_VERSION")
+	case strings.EqualFold(getenv("LC_TERMINAL"), "iTerm2"):
+		version = getenv("LC_TERMINAL_VERSION")
+	}
+	return versionAtLeast(version, 3, 5)
+}
+
+func versionAtLeast(version string, major, minor int) bool {
+	parts := strings.SplitN(strings.TrimSpace(version), ".", 3)
+	if len(parts) < 2 {
+		return false
+	}
+	maj, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return false
+	}
+	// Minor may carry a suffix like "5beta1".
+	digits := parts[1]
+	if i := strings.IndexFunc(digits, func(r rune) bool { return r < '0' || r > '9' }); i >= 0 {
+		digits = digits[:i]
+	}
+	mnr, err := strconv.Atoi(digits)
+	if err != nil {
+		return false
+	}
+	return maj > major || (maj == major && mnr >= minor)
 }
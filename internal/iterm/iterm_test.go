@@ -3,6 +3,7 @@ package iterm
 import (
 	"bufio"
 	"bytes"
+	"encoding/base64"
 	"strings"
 	"testing"
 )
@@ -31,3 +32,84 @@ func TestSendInlineFile(t *testing.T) {
 		t.Fatalf("expected ST terminator")
 	}
 }
+
+func TestSendMultipartFile(t *testing.T) {
+	data := make([]byte, partSize*2+10)
+	for i := range data {
+		data[i] = byte(i % 251)
+	}
+	var buf bytes.Buffer
+	out := bufio.NewWriter(&buf)
+	SendMultipartFile(out, File{Name: "big.gif", Data: data, WidthCells: 4})
+	_ = out.Flush()
+
+	seqs := strings.Split(strings.TrimSuffix(buf.String(), "\x1b\\"), "\x1b\\")
+	if len(seqs) != 5 {
+		t.Fatalf("expected header, 3 parts and end, got %d sequences", len(seqs))
+	}
+	if !strings.HasPrefix(seqs[0], "\x1b]1337;MultipartFile=name=") || !strings.Contains(seqs[0], "size=98314") || !strings.Contains(seqs[0], "width=4") {
+		t.Fatalf("unexpected header %q", seqs[0])
+	}
+	var got []byte
+	for _, part := range seqs[1:4] {
+		payload, ok := strings.CutPrefix(part, "\x1b]1337;FilePart=")
+		if !ok {
+			t.Fatalf("unexpected part %q", part[:min(len(part), 40)])
+		}
+		chunk, err := base64.StdEncoding.DecodeString(payload)
+		if err != nil {
+			t.Fatalf("part is not standalone base64: %v", err)
+		}
+		got = append(got, chunk...)
+	}
+	if !bytes.Equal(got, data) {
+		t.Fatalf("parts don't reassemble the payload")
+	}
+	if seqs[4] != "\x1b]1337;FileEnd" {
+		t.Fatalf("unexpected end %q", seqs[4])
+	}
+}
+
+func TestSendFilePicksBySize(t *testing.T) {
+	var buf bytes.Buffer
+	out := bufio.NewWriter(&buf)
+	SendFile(out, File{Data: []byte{1, 2, 3}}, true)
+	_ = out.Flush()
+	if !strings.HasPrefix(buf.String(), "\x1b]1337;File=") {
+		t.Fatalf("small payloads should stay single: %q", buf.String())
+	}
+
+	big := make([]byte, MultipartThreshold+1)
+	buf.Reset()
+	SendFile(out, File{Data: big}, true)
+	_ = out.Flush()
+	if !strings.HasPrefix(buf.String(), "\x1b]1337;MultipartFile=") {
+		t.Fatalf("large payloads should use multipart")
+	}
+	buf.Reset()
+	SendFile(out, File{Data: big}, false)
+	_ = out.Flush()
+	if !strings.HasPrefix(buf.String(), "\x1b]1337;File=") {
+		t.Fatalf("unsupported terminals should get a single sequence")
+	}
+}
+
+func TestMultipartSupported(t *testing.T) {
+	cases := []struct {
+		env  map[string]string
+		want bool
+	}{
+		{map[string]string{"TERM_PROGRAM": "iTerm.app", "TERM_PROGRAM_VERSION": "3.5.0"}, true},
+		{map[string]string{"TERM_PROGRAM": "iTerm.app", "TERM_PROGRAM_VERSION": "3.6beta2"}, true},
+		{map[string]string{"TERM_PROGRAM": "iTerm.app", "TERM_PROGRAM_VERSION": "3.4.23"}, false},
+		{map[string]string{"TERM_PROGRAM": "tmux", "LC_TERMINAL": "iTerm2", "LC_TERMINAL_VERSION": "3.5.4"}, true},
+		{map[string]string{"TERM_PROGRAM": "WezTerm", "TERM_PROGRAM_VERSION": "20240203"}, false},
+		{map[string]string{"TERM_PROGRAM": "WezTerm", "GIFGREP_ITERM_MULTIPART": "1"}, true},
+		{map[string]string{"TERM_PROGRAM": "iTerm.app", "TERM_PROGRAM_VERSION": "3.5.0", "GIFGREP_ITERM_MULTIPART": "0"}, false},
+	}
+	for _, tc := range cases {
+		if got := MultipartSupported(func(k string) string { return tc.env[k] }); got != tc.want {
+			t.Fatalf("MultipartSupported(%v) = %v, want %v", tc.env, got, tc.want)
+		}
+	}
+}
@@ -19,9 +19,11 @@ type kittyData struct {
 	PlacementID int
 	Delay       time.Duration
 	NoCursor    bool
+	Medium      Medium
+	Virtual     bool
 }
 
-func SendAnimation(out *bufio.Writer, id uint32, frames []gifdecode.Frame, cols, rows int) {
+func SendAnimation(out *bufio.Writer, id uint32, frames []gifdecode.Frame, cols, rows int, opts Options) {
 	if len(frames) == 0 {
 		return
 	}
@@ -34,6 +36,8 @@ func SendAnimation(out *bufio.Writer, id uint32, frames []gifdecode.Frame, cols,
 		Rows:        rows,
 		PlacementID: 1,
 		NoCursor:    true,
+		Medium:      opts.Medium,
+		Virtual:     opts.Placeholders,
 	})
 	for i := 1; i < len(frames); i++ {
 		frame := frames[i]
@@ -42,13 +46,14 @@ func SendAnimation(out *bufio.Writer, id uint32, frames []gifdecode.Frame, cols,
 			ID:     id,
 			Data:   frame.PNG,
 			Delay:  frame.Delay,
+			Medium: opts.Medium,
 		})
 	}
 	sendKittyAnimDelay(out, id, delayMS(base.Delay))
 	sendKittyAnimStart(out, id)
 }
 
-func SendFrame(out *bufio.Writer, id uint32, frame gifdecode.Frame, cols, rows int) {
+func SendFrame(out *bufio.Writer, id uint32, frame gifdecode.Frame, cols, rows int, opts Options) {
 	sendKittyData(out, kittyData{
 		Action:      "T",
 		ID:          id,
@@ -57,10 +62,24 @@ func SendFrame(out *bufio.Writer, id uint32, frame gifdecode.Frame, cols, rows i
 		Rows:        rows,
 		PlacementID: 1,
 		NoCursor:    true,
+		Medium:      opts.Medium,
+		Virtual:     opts.Placeholders,
 	})
 }
 
 func sendKittyData(out *bufio.Writer, data kittyData) {
+	if data.Medium != MediumDirect {
+		// One escape carrying a file/shm name instead of the base64 image.
+		if name, err := stage(data.Medium, data.Data); err == nil {
+			params := kittyParams(data, 0)
+			params = append(params, "t="+data.Medium.code())
+			if data.Medium == MediumSharedMemory {
+				params = append(params, fmt.Sprintf("S=%d", len(data.Data)))
+			}
+			_, _ = fmt.Fprintf(out, "\x1b_G%s;%s\x1b\\", strings.Join(params, ","), base64.StdEncoding.EncodeToString([]byte(name)))
+			return
+		}
+	}
 	encoded := base64.StdEncoding.EncodeToString(data.Data)
 	const chunkSize = 4096
 	first := true
@@ -75,29 +94,7 @@ func sendKittyData(out *bufio.Writer, data kittyData) {
 			more = 1
 		}
 		if first {
-			params := []string{
-				fmt.Sprintf("a=%s", data.Action),
-				"f=100",
-				fmt.Sprintf("i=%d", data.ID),
-				fmt.Sprintf("m=%d", more),
-				"q=2",
-			}
-			if data.Cols > 0 {
-				params = append(params, fmt.Sprintf("c=%d", data.Cols))
-			}
-			if data.Rows > 0 {
-				params = append(params, fmt.Sprintf("r=%d", data.Rows))
-			}
-			if data.PlacementID > 0 {
-				params = append(params, fmt.Sprintf("p=%d", data.PlacementID))
-			}
-			if data.NoCursor {
-				params = append(params, "C=1")
-			}
-			if data.Action == "f" && data.Delay > 0 {
-				params = append(params, fmt.Sprintf("z=%d", delayMS(data.Delay)))
-			}
-			_, _ = fmt.Fprintf(out, "\x1b_G%s;", strings.Join(params, ","))
+			_, _ = fmt.Fprintf(out, "\x1b_G%s;", strings.Join(kittyParams(data, more), ","))
 			first = false
 		} else {
 			if data.Action == "f" {
@@ -111,6 +108,35 @@ func sendKittyData(out *bufio.Writer, data kittyData) {
 	}
 }
 
+func kittyParams(data kittyData, more int) []string {
+	params := []string{
+		fmt.Sprintf("a=%s", data.Action),
+		"f=100",
+		fmt.Sprintf("i=%d", data.ID),
+		fmt.Sprintf("m=%d", more),
+		"q=2",
+	}
+	if data.Cols > 0 {
+		params = append(params, fmt.Sprintf("c=%d", data.Cols))
+	}
+	if data.Rows > 0 {
+		params = append(params, fmt.Sprintf("r=%d", data.Rows))
+	}
+	if data.PlacementID > 0 {
+		params = append(params, fmt.Sprintf("p=%d", data.PlacementID))
+	}
+	if data.NoCursor {
+		params = append(params, "C=1")
+	}
+	if data.Virtual {
+		params = append(params, "U=1")
+	}
+	if data.Action == "f" && data.Delay > 0 {
+		params = append(params, fmt.Sprintf("z=%d", delayMS(data.Delay)))
+	}
+	return params
+}
+
 func sendKittyAnimDelay(out *bufio.Writer, id uint32, delayMS int) {
 	if delayMS <= 0 {
 		return
@@ -122,11 +148,15 @@ func sendKittyAnimStart(out *bufio.Writer, id uint32) {
 	_, _ = fmt.Fprintf(out, "\x1b_Ga=a,i=%d,s=3,v=1,q=2\x1b\\", id)
 }
 
-func PlaceImage(out *bufio.Writer, id uint32, cols, rows int) {
+func PlaceImage(out *bufio.Writer, id uint32, cols, rows int, opts Options) {
 	if id == 0 {
 		return
 	}
-	_, _ = fmt.Fprintf(out, "\x1b_Ga=p,i=%d,p=1,c=%d,r=%d,C=1,q=2\x1b\\", id, cols, rows)
+	virtual := ""
+	if opts.Placeholders {
+		virtual = ",U=1"
+	}
+	_, _ = fmt.Fprintf(out, "\x1b_Ga=p,i=%d,p=1,c=%d,r=%d,C=1%s,q=2\x1b\\", id, cols, rows, virtual)
 }
 
 func DeleteImage(out *bufio.Writer, id uint32) {
@@ -24,7 +24,7 @@ func TestKittySequences(t *testing.T) {
 	})
 	sendKittyAnimDelay(out, 7, 80)
 	sendKittyAnimStart(out, 7)
-	PlaceImage(out, 7, 2, 3)
+	PlaceImage(out, 7, 2, 3, Options{})
 	DeleteImage(out, 7)
 	_ = out.Flush()
 
@@ -40,7 +40,7 @@ func TestKittySequences(t *testing.T) {
 	SendAnimation(out, 2, []gifdecode.Frame{
 		{PNG: []byte{1, 2, 3}, Delay: 80 * time.Millisecond},
 		{PNG: []byte{4, 5, 6}, Delay: 90 * time.Millisecond},
-	}, 5, 4)
+	}, 5, 4, Options{})
 	_ = out.Flush()
 	if !strings.Contains(buf.String(), "a=f") {
 		t.Fatalf("expected frame data")
@@ -48,7 +48,7 @@ func TestKittySequences(t *testing.T) {
 
 	buf.Reset()
 	sendKittyAnimDelay(out, 7, 0)
-	PlaceImage(out, 0, 2, 3)
+	PlaceImage(out, 0, 2, 3, Options{})
 	DeleteImage(out, 0)
 	_ = out.Flush()
 	if buf.Len() != 0 {
@@ -0,0 +1,162 @@
+package kitty
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Medium is how image data reaches the terminal (the t= key).
+type Medium int
+
+const (
+	// MediumDirect sends base64 data inline in 4096-byte chunks. Works over SSH.
+	MediumDirect Medium = iota
+	// MediumTempFile writes a temp file the terminal reads and deletes.
+	MediumTempFile
+	// MediumSharedMemory writes a POSIX shared memory object the terminal
+	// reads and unlinks (Linux only; falls back to direct elsewhere).
+	MediumSharedMemory
+)
+
+func (m Medium) String() string {
+	switch m {
+	case MediumDirect:
+		return "direct"
+	case MediumTempFile:
+		return "temp"
+	case MediumSharedMemory:
+		return "shm"
+	default:
+		return "direct"
+	}
+}
+
+func (m Medium) code() string {
+	switch m {
+	case MediumTempFile:
+		return "t"
+	case MediumSharedMemory:
+		return "s"
+	case MediumDirect:
+		return "d"
+	default:
+		return "d"
+	}
+}
+
+// ParseMedium accepts "file" as an alias for temp: gifgrep never wants the
+// terminal to read a file it then has to clean up itself (t=f).
+func ParseMedium(s string) (Medium, bool) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "direct", "d":
+		return MediumDirect, true
+	case "temp", "tempfile", "file", "t", "f":
+		return MediumTempFile, true
+	case "shm", "s":
+		return MediumSharedMemory, true
+	default:
+		return MediumDirect, false
+	}
+}
+
+type Options struct {
+	Medium Medium
+	// Placeholders places images through Unicode placeholder cells (U=1), so
+	// they move with the text: survives scrolling, tmux panes and redraws.
+	Placeholders bool
+}
+
+// DetectOptions sends images directly unless GIFGREP_KITTY_MEDIUM=temp|shm
+// opts in to a local medium (ignored over SSH, where the terminal can't see
+// our files), and uses placeholders inside tmux unless
+// GIFGREP_KITTY_PLACEHOLDERS=0|1 says otherwise.
+func DetectOptions(getenv func(string) string) Options {
+	if getenv == nil {
+		getenv = os.Getenv
+	}
+	var opts Options
+	if m, ok := ParseMedium(getenv("GIFGREP_KITTY_MEDIUM")); ok && !isRemote(getenv) {
+		opts.Medium = m
+	}
+	switch strings.ToLower(strings.TrimSpace(getenv("GIFGREP_KITTY_PLACEHOLDERS"))) {
+	case "1", "true", "yes":
+		opts.Placeholders = true
+	case "0", "false", "no":
+	default:
+		opts.Placeholders = strings.TrimSpace(getenv("TMUX")) != ""
+	}
+	return opts
+}
+
+func isRemote(getenv func(string) string) bool {
+	for _, k := range []string{"SSH_CONNECTION", "SSH_CLIENT", "SSH_TTY"} {
+		if strings.TrimSpace(getenv(k)) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Terminals only delete temp files whose path contains this marker.
+const tempMarker = "tty-graphics-protocol"
+
+// staleAge is how long a staged file may sit unread before a later stage
+// removes it. Terminals that support t=t / t=s delete it once read; ones that
+// don't leave it behind.
+const staleAge = time.Minute
+
+var sweepOnce sync.Once
+
+// stagedPatterns match what stage writes, in the temp dir and /dev/shm.
+func stagedPatterns() []string {
+	return []string{
+		filepath.Join(os.TempDir(), "gifgrep-"+tempMarker+"-*.png"),
+		filepath.Join("/dev/shm", "gifgrep-"+tempMarker+"-*"),
+	}
+}
+
+// sweepStale removes staged files older than staleAge.
+func sweepStale(patterns []string, now time.Time) {
+	for _, pattern := range patterns {
+		matches, _ := filepath.Glob(pattern)
+		for _, p := range matches {
+			if info, err := os.Lstat(p); err == nil && info.Mode().IsRegular() && now.Sub(info.ModTime()) > staleAge {
+				_ = os.Remove(p)
+			}
+		}
+	}
+}
+
+// stage writes data where the terminal can read it and returns the name to
+// send. The first call also clears out files earlier runs staged that no
+// terminal picked up.
+func stage(m Medium, data []byte) (string, error) {
+	sweepOnce.Do(func() { sweepStale(stagedPatterns(), time.Now()) })
+	switch m {
+	case MediumTempFile:
+		f, err := os.CreateTemp("", "gifgrep-"+tempMarker+"-*.png")
+		if err != nil {
+			return "", err
+		}
+		if _, err := f.Write(data); err != nil {
+			_ = f.Close()
+			_ = os.Remove(f.Name())
+			return "", err
+		}
+		if err := f.Close(); err != nil {
+			_ = os.Remove(f.Name())
+			return "", err
+		}
+		return f.Name(), nil
+	case MediumSharedMemory:
+		return writeSharedMemory(data)
+	case MediumDirect:
+		return "", fmt.Errorf("direct medium has nothing to stage")
+	default:
+		return "", fmt.Errorf("unknown medium %d", m)
+	}
+}
@@ -0,0 +1,150 @@
+package kitty
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steipete/gifgrep/gifdecode"
+)
+
+func frameWith(png []byte) gifdecode.Frame {
+	return gifdecode.Frame{PNG: png}
+}
+
+func TestDetectOptions(t *testing.T) {
+	cases := []struct {
+		name string
+		env  map[string]string
+		want Options
+	}{
+		{"local", map[string]string{}, Options{Medium: MediumDirect}},
+		{"tmux", map[string]string{"TMUX": "/tmp/tmux-1/default,1,0"}, Options{Medium: MediumDirect, Placeholders: true}},
+		{"opt in", map[string]string{"GIFGREP_KITTY_MEDIUM": "temp"}, Options{Medium: MediumTempFile}},
+		{"opt in over ssh", map[string]string{
+			"SSH_TTY":                    "/dev/pts/1",
+			"TMUX":                       "x",
+			"GIFGREP_KITTY_MEDIUM":       "shm",
+			"GIFGREP_KITTY_PLACEHOLDERS": "0",
+		}, Options{Medium: MediumDirect}},
+		{"force placeholders", map[string]string{"GIFGREP_KITTY_PLACEHOLDERS": "1", "GIFGREP_KITTY_MEDIUM": "direct"}, Options{Placeholders: true}},
+	}
+	for _, tc := range cases {
+		got := DetectOptions(func(k string) string { return tc.env[k] })
+		if got != tc.want {
+			t.Fatalf("%s: got %+v, want %+v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestParseMedium(t *testing.T) {
+	if m, ok := ParseMedium("File"); !ok || m != MediumTempFile {
+		t.Fatalf("expected file to map to temp")
+	}
+	if _, ok := ParseMedium("pipe"); ok {
+		t.Fatalf("expected unknown medium")
+	}
+	if MediumSharedMemory.String() != "shm" || MediumDirect.code() != "d" {
+		t.Fatalf("unexpected medium names")
+	}
+}
+
+var payloadRE = regexp.MustCompile(`\x1b_G([^;]*);([^\x1b]*)\x1b\\`)
+
+func TestSendFrameTempFile(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	var buf bytes.Buffer
+	out := bufio.NewWriter(&buf)
+	SendFrame(out, 4, frameWith([]byte("png-bytes")), 3, 2, Options{Medium: MediumTempFile, Placeholders: true})
+	_ = out.Flush()
+
+	m := payloadRE.FindAllStringSubmatch(buf.String(), -1)
+	if len(m) != 1 {
+		t.Fatalf("expected a single escape, got %q", buf.String())
+	}
+	if !strings.Contains(m[0][1], "t=t") || !strings.Contains(m[0][1], "U=1") {
+		t.Fatalf("missing medium or placeholder keys: %q", m[0][1])
+	}
+	name, err := base64.StdEncoding.DecodeString(m[0][2])
+	if err != nil {
+		t.Fatalf("payload is not base64: %v", err)
+	}
+	if !strings.Contains(filepath.Base(string(name)), tempMarker) {
+		t.Fatalf("temp file name lacks marker: %q", name)
+	}
+	data, err := os.ReadFile(string(name))
+	if err != nil || string(data) != "png-bytes" {
+		t.Fatalf("unexpected temp file contents %q: %v", data, err)
+	}
+}
+
+func TestSendFrameSharedMemory(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("shared memory transmission is linux-only")
+	}
+	if _, err := os.Stat("/dev/shm"); err != nil {
+		t.Skip("no /dev/shm")
+	}
+	var buf bytes.Buffer
+	out := bufio.NewWriter(&buf)
+	SendFrame(out, 4, frameWith([]byte("shm-bytes")), 3, 2, Options{Medium: MediumSharedMemory})
+	_ = out.Flush()
+
+	m := payloadRE.FindAllStringSubmatch(buf.String(), -1)
+	if len(m) != 1 || !strings.Contains(m[0][1], "t=s") || !strings.Contains(m[0][1], "S=9") {
+		t.Fatalf("unexpected shm escape %q", buf.String())
+	}
+	name, _ := base64.StdEncoding.DecodeString(m[0][2])
+	path := filepath.Join("/dev/shm", string(name))
+	t.Cleanup(func() { _ = os.Remove(path) })
+	if data, err := os.ReadFile(path); err != nil || string(data) != "shm-bytes" {
+		t.Fatalf("unexpected shm contents %q: %v", data, err)
+	}
+}
+
+func TestSendFrameFallsBackToDirect(t *testing.T) {
+	t.Setenv("TMPDIR", filepath.Join(t.TempDir(), "missing"))
+	var buf bytes.Buffer
+	out := bufio.NewWriter(&buf)
+	SendFrame(out, 4, frameWith([]byte{1, 2, 3}), 3, 2, Options{Medium: MediumTempFile})
+	_ = out.Flush()
+	if strings.Contains(buf.String(), "t=t") || !strings.Contains(buf.String(), ";AQID\x1b\\") {
+		t.Fatalf("expected direct transmission: %q", buf.String())
+	}
+}
+
+func TestSweepStaleRemovesOnlyOldStagedFiles(t *testing.T) {
+	dir := t.TempDir()
+	pattern := filepath.Join(dir, "gifgrep-"+tempMarker+"-*.png")
+	old := filepath.Join(dir, "gifgrep-"+tempMarker+"-1.png")
+	fresh := filepath.Join(dir, "gifgrep-"+tempMarker+"-2.png")
+	other := filepath.Join(dir, "other.png")
+	for _, p := range []string{old, fresh, other} {
+		if err := os.WriteFile(p, []byte("png"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	now := time.Now()
+	if err := os.Chtimes(old, now.Add(-2*staleAge), now.Add(-2*staleAge)); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(other, now.Add(-2*staleAge), now.Add(-2*staleAge)); err != nil {
+		t.Fatal(err)
+	}
+	sweepStale([]string{pattern}, now)
+	if _, err := os.Stat(old); !os.IsNotExist(err) {
+		t.Fatalf("stale staged file kept: %v", err)
+	}
+	for _, p := range []string{fresh, other} {
+		if _, err := os.Stat(p); err != nil {
+			t.Fatalf("%s removed: %v", filepath.Base(p), err)
+		}
+	}
+}
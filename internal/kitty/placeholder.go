@@ -0,0 +1,32 @@
+package kitty
+
+import (
+	"fmt"
+	"strings"
+)
+
+// placeholder is the Unicode placeholder character for virtual placements.
+const placeholder = '\U0010EEEE'
+
+// PlaceholderRow returns one row of placeholder cells for image id. The
+// foreground color carries the low 24 bits of the image id and a third
+// diacritic on the first cell the high byte; later cells continue from the
+// first. Ends by resetting the foreground.
+func PlaceholderRow(id uint32, row, cols int) string {
+	if cols <= 0 || row < 0 || row >= len(diacritics) {
+		return ""
+	}
+	var b strings.Builder
+	_, _ = fmt.Fprintf(&b, "\x1b[38;2;%d;%d;%dm", (id>>16)&0xff, (id>>8)&0xff, id&0xff)
+	b.WriteRune(placeholder)
+	b.WriteRune(diacritics[row])
+	b.WriteRune(diacritics[0])
+	if hi := id >> 24; hi != 0 {
+		b.WriteRune(diacritics[hi])
+	}
+	for c := 1; c < cols; c++ {
+		b.WriteRune(placeholder)
+	}
+	b.WriteString("\x1b[39m")
+	return b.String()
+}
@@ -0,0 +1,18 @@
+package kitty
+
+import "testing"
+
+func TestPlaceholderRow(t *testing.T) {
+	got := PlaceholderRow(0x010203, 2, 3)
+	want := "\x1b[38;2;1;2;3m" + "\U0010EEEE\u030E\u0305" + "\U0010EEEE\U0010EEEE" + "\x1b[39m"
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	// The high byte of a 32-bit id goes into a third diacritic.
+	if got := PlaceholderRow(0x02000001, 0, 1); got != "\x1b[38;2;0;0;1m\U0010EEEE\u0305\u0305\u030E\x1b[39m" {
+		t.Fatalf("unexpected high-byte row %q", got)
+	}
+	if PlaceholderRow(1, 0, 0) != "" || PlaceholderRow(1, len(diacritics), 1) != "" {
+		t.Fatalf("expected empty rows for out-of-range input")
+	}
+}
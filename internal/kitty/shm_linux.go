@@ -0,0 +1,27 @@
+//go:build linux
+
+package kitty
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// writeSharedMemory creates a POSIX shm object; on Linux shm_open names are
+// files under /dev/shm.
+func writeSharedMemory(data []byte) (string, error) {
+	f, err := os.CreateTemp("/dev/shm", "gifgrep-"+tempMarker+"-*")
+	if err != nil {
+		return "", err
+	}
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		_ = os.Remove(f.Name())
+		return "", err
+	}
+	return filepath.Base(f.Name()), nil
+}
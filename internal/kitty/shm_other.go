@@ -0,0 +1,11 @@
+//go:build !linux
+
+package kitty
+
+import "errors"
+
+// writeSharedMemory needs shm_open, which Go can't reach without cgo outside
+// Linux; callers fall back to direct transmission.
+func writeSharedMemory(_ []byte) (string, error) {
+	return "", errors.New("shared memory transmission is only supported on linux")
+}
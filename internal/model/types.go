@@ -1,6 +1,9 @@
 package model
 
-import "time"
+import (
+	"image"
+	"time"
+)
 
 const AppName = "gifgrep"
 
@@ -13,24 +16,37 @@ type Result struct {
 	Title      string   `json:"title"`
 	URL        string   `json:"url"`
 	PreviewURL string   `json:"preview_url"`
+	PageURL    string   `json:"page_url,omitempty"`
 	Tags       []string `json:"tags,omitempty"`
 	Width      int      `json:"width,omitempty"`
 	Height     int      `json:"height,omitempty"`
 }
 
 type Options struct {
-	Color    string
-	Verbose  int
-	Quiet    bool
-	Reveal   bool
-	Download bool
-	Format   string
-	Thumbs   string
+	Color             string
+	Verbose           int
+	Quiet             bool
+	Reveal            bool
+	Download          bool
+	DownloadDir       string
+	DownloadName      string
+	DownloadCollision string
+	DownloadJobs      int
+	DownloadDedup     string
+	DownloadMetadata  bool
+	Copy              string
+	Format            string
+	Thumbs            string
+	Template          string
+	Keys              map[string]string
+	Collection        string
+	HistoryPath       string
 
 	JSON   bool
 	Number bool
 	Limit  int
 	Source string
+	Rating string
 
 	GifInput      string
 	StillAt       time.Duration
@@ -39,4 +55,16 @@ type Options struct {
 	StillsCols    int
 	StillsPadding int
 	OutPath       string
+
+	FramesEvery int
+	FramesFrom  time.Duration
+	FramesTo    time.Duration
+
+	EditStart    time.Duration
+	EditEnd      time.Duration
+	EditCrop     image.Rectangle
+	EditWidth    int
+	EditHeight   int
+	EditColors   int
+	EditNoDither bool
 }
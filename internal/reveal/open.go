@@ -0,0 +1,40 @@
+package reveal
+
+import (
+	"errors"
+	"runtime"
+)
+
+// OpenURL opens url in the default browser.
+func OpenURL(url string) error {
+	if url == "" {
+		return errors.New("empty url")
+	}
+	cmd, args, err := commandForOpen(runtime.GOOS, url)
+	if err != nil {
+		return err
+	}
+	c := execCommand(cmd, args...)
+	// No output pipes: a browser started by the opener inherits them and would
+	// keep Run waiting until it exits.
+	c.Stdout = nil
+	c.Stderr = nil
+	return c.Run()
+}
+
+func commandForOpen(goos string, url string) (string, []string, error) {
+	switch goos {
+	case "darwin":
+		return "open", []string{url}, nil
+	case "windows":
+		return "rundll32", []string{"url.dll,FileProtocolHandler", url}, nil
+	default:
+		if _, err := lookPath("xdg-open"); err == nil {
+			return "xdg-open", []string{url}, nil
+		}
+		if _, err := lookPath("gio"); err == nil {
+			return "gio", []string{"open", url}, nil
+		}
+		return "", nil, errors.New("no URL opener found (need xdg-open or gio)")
+	}
+}
@@ -0,0 +1,44 @@
+package reveal
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCommandForOpenPlatforms(t *testing.T) {
+	cmd, args, err := commandForOpen("darwin", "https://tenor.com/view/x")
+	if err != nil || cmd != "open" || len(args) != 1 || args[0] != "https://tenor.com/view/x" {
+		t.Fatalf("darwin: %q %#v %v", cmd, args, err)
+	}
+	cmd, args, err = commandForOpen("windows", "https://tenor.com/view/x?a=1&b=2")
+	if err != nil || cmd != "rundll32" || len(args) != 2 || args[1] != "https://tenor.com/view/x?a=1&b=2" {
+		t.Fatalf("windows: %q %#v %v", cmd, args, err)
+	}
+}
+
+func TestCommandForOpenLinux(t *testing.T) {
+	prev := lookPath
+	t.Cleanup(func() { lookPath = prev })
+
+	lookPath = func(name string) (string, error) {
+		if name == "gio" {
+			return "/usr/bin/gio", nil
+		}
+		return "", errors.New("nope")
+	}
+	cmd, args, err := commandForOpen("linux", "https://x.test")
+	if err != nil || cmd != "gio" || len(args) != 2 || args[0] != "open" {
+		t.Fatalf("gio: %q %#v %v", cmd, args, err)
+	}
+
+	lookPath = func(string) (string, error) { return "", errors.New("nope") }
+	if _, _, err := commandForOpen("linux", "https://x.test"); err == nil {
+		t.Fatalf("expected missing opener error")
+	}
+}
+
+func TestOpenURLRejectsEmpty(t *testing.T) {
+	if err := OpenURL(""); err == nil {
+		t.Fatalf("expected error")
+	}
+}
@@ -17,6 +17,7 @@ type giphySearchResponse struct {
 	Data []struct {
 		ID     string `json:"id"`
 		Title  string `json:"title"`
+		URL    string `json:"url"`
 		Images struct {
 			Original struct {
 				URL    string `json:"url"`
@@ -52,7 +53,7 @@ func fetchGiphyV1(query string, opts model.Options) ([]model.Result, error) {
 	params.Set("q", query)
 	params.Set("api_key", apiKey)
 	params.Set("limit", fmt.Sprintf("%d", limit))
-	params.Set("rating", "g")
+	params.Set("rating", giphyRating(opts.Rating))
 
 	reqURL := "https://api.giphy.com/v1/gifs/search?" + params.Encode()
 	client := &http.Client{Timeout: 10 * time.Second}
@@ -103,6 +104,7 @@ func fetchGiphyV1(query string, opts model.Options) ([]model.Result, error) {
 			Title:      title,
 			URL:        gifURL,
 			PreviewURL: preview,
+			PageURL:    item.URL,
 			Width:      width,
 			Height:     height,
 		})
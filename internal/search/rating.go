@@ -0,0 +1,29 @@
+package search
+
+import "strings"
+
+// giphyRating maps --rating (g, pg, pg-13, r) to Giphy's rating parameter.
+func giphyRating(rating string) string {
+	switch r := strings.ToLower(strings.TrimSpace(rating)); r {
+	case "g", "pg", "pg-13", "r":
+		return r
+	default:
+		return "g"
+	}
+}
+
+// tenorContentFilter maps --rating to Tenor's contentfilter, whose levels are
+// the strictest rating each allows: high = G, medium = PG, low = PG-13,
+// off = R.
+func tenorContentFilter(rating string) string {
+	switch strings.ToLower(strings.TrimSpace(rating)) {
+	case "g":
+		return "high"
+	case "pg":
+		return "medium"
+	case "r":
+		return "off"
+	default:
+		return "low"
+	}
+}
@@ -0,0 +1,61 @@
+package search
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/steipete/gifgrep/internal/model"
+	"github.com/steipete/gifgrep/internal/testutil"
+)
+
+func TestRatingMapping(t *testing.T) {
+	cases := []struct{ rating, giphy, tenor string }{
+		{"", "g", "low"},
+		{"auto", "g", "low"},
+		{"g", "g", "high"},
+		{"PG", "pg", "medium"},
+		{"pg-13", "pg-13", "low"},
+		{"r", "r", "off"},
+	}
+	for _, tc := range cases {
+		if got := giphyRating(tc.rating); got != tc.giphy {
+			t.Fatalf("giphyRating(%q) = %q, want %q", tc.rating, got, tc.giphy)
+		}
+		if got := tenorContentFilter(tc.rating); got != tc.tenor {
+			t.Fatalf("tenorContentFilter(%q) = %q, want %q", tc.rating, got, tc.tenor)
+		}
+	}
+}
+
+type recordingTransport struct {
+	http.RoundTripper
+	queries []url.Values
+}
+
+func (r *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	r.queries = append(r.queries, req.URL.Query())
+	return r.RoundTripper.RoundTrip(req)
+}
+
+func TestSearchSendsRating(t *testing.T) {
+	t.Setenv("GIPHY_API_KEY", "test-key")
+	rt := &recordingTransport{RoundTripper: &testutil.FakeTransport{GIFData: testutil.MakeTestGIF()}}
+	testutil.WithTransport(t, rt, func() {
+		if _, err := Search("cats", model.Options{Source: "giphy", Rating: "pg"}); err != nil {
+			t.Fatalf("giphy: %v", err)
+		}
+		if _, err := Search("cats", model.Options{Source: "tenor", Rating: "r"}); err != nil {
+			t.Fatalf("tenor: %v", err)
+		}
+	})
+	if len(rt.queries) != 2 {
+		t.Fatalf("expected 2 requests, got %v", rt.queries)
+	}
+	if got := rt.queries[0].Get("rating"); got != "pg" {
+		t.Fatalf("giphy rating = %q", got)
+	}
+	if got := rt.queries[1].Get("contentfilter"); got != "off" {
+		t.Fatalf("tenor contentfilter = %q", got)
+	}
+}
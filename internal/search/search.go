@@ -17,6 +17,7 @@ type tenorV1Response struct {
 		ID                 string               `json:"id"`
 		Title              string               `json:"title"`
 		ContentDescription string               `json:"content_description"`
+		ItemURL            string               `json:"itemurl"`
 		Tags               []string             `json:"tags"`
 		Media              []map[string]mediaV1 `json:"media"`
 	} `json:"results"`
@@ -57,7 +58,7 @@ func fetchTenorV1(query string, opts model.Options) ([]model.Result, error) {
 	params.Set("q", query)
 	params.Set("key", apiKey)
 	params.Set("limit", fmt.Sprintf("%d", limit))
-	params.Set("contentfilter", "low")
+	params.Set("contentfilter", tenorContentFilter(opts.Rating))
 
 	reqURL := "https://api.tenor.com/v1/search?" + params.Encode()
 	client := &http.Client{Timeout: 10 * time.Second}
@@ -125,6 +126,7 @@ func fetchTenorV1(query string, opts model.Options) ([]model.Result, error) {
 			Title:      title,
 			URL:        gifURL,
 			PreviewURL: preview,
+			PageURL:    r.ItemURL,
 			Tags:       r.Tags,
 			Width:      width,
 			Height:     height,
@@ -26,6 +26,9 @@ func TestFetchTenorAndGIF(t *testing.T) {
 		if out[0].PreviewURL == "" || out[0].URL == "" {
 			t.Fatalf("missing URLs")
 		}
+		if out[0].PageURL != "https://tenor.com/view/cat-one-1" {
+			t.Fatalf("unexpected page URL %q", out[0].PageURL)
+		}
 	})
 }
 
@@ -43,6 +46,9 @@ func TestFetchGiphy(t *testing.T) {
 		if out[0].PreviewURL == "" || out[0].URL == "" {
 			t.Fatalf("missing URLs")
 		}
+		if out[0].PageURL != "https://giphy.com/gifs/cat-one-g1" {
+			t.Fatalf("unexpected page URL %q", out[0].PageURL)
+		}
 
 		_, err = Search("cats", model.Options{Limit: 1, Source: "giphy"})
 		if err != nil {
@@ -0,0 +1,205 @@
+package sixel
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/draw"
+)
+
+// Fallback cell size for terminals that report neither TIOCGWINSZ pixels nor
+// XTWINOPS replies; undersizing only leaves a margin, oversizing would spill
+// into the next text rows.
+const (
+	DefaultCellWidth  = 8
+	DefaultCellHeight = 16
+)
+
+const (
+	maxSide = 4096
+	// levels per channel of the fixed color cube (6×6×6 = 216 registers).
+	levels      = 6
+	paletteSize = levels * levels * levels
+)
+
+// FitPixels returns the largest size with the image's aspect ratio that fits
+// in maxW×maxH pixels.
+func FitPixels(imgW, imgH, maxW, maxH int) (int, int) {
+	if imgW <= 0 || imgH <= 0 || maxW <= 0 || maxH <= 0 {
+		return 0, 0
+	}
+	w := maxW
+	h := imgH * maxW / imgW
+	if h > maxH {
+		h = maxH
+		w = imgW * maxH / imgH
+	}
+	return max(1, w), max(1, h)
+}
+
+// Encode scales img to width×height pixels and returns it as a DECSIXEL
+// sequence. Pixels below 50% alpha are left transparent.
+func Encode(img image.Image, width, height int) []byte {
+	if img == nil || img.Bounds().Empty() || width <= 0 || height <= 0 {
+		return nil
+	}
+	width = min(width, maxSide)
+	height = min(height, maxSide)
+	idx := quantize(resize(img, width, height))
+
+	var used [paletteSize]bool
+	for _, c := range idx {
+		if c >= 0 {
+			used[c] = true
+		}
+	}
+
+	var buf bytes.Buffer
+	// P2=1: unset pixels keep whatever is on screen (transparency).
+	buf.WriteString("\x1bP0;1;0q")
+	_, _ = fmt.Fprintf(&buf, "\"1;1;%d;%d", width, height)
+	for c, ok := range used {
+		if !ok {
+			continue
+		}
+		r, g, b := cubeColor(c)
+		_, _ = fmt.Fprintf(&buf, "#%d;2;%d;%d;%d", c, percent(r), percent(g), percent(b))
+	}
+
+	var (
+		rows   [paletteSize][]byte
+		inBand [paletteSize]bool
+		band   []int
+	)
+	for y0 := 0; y0 < height; y0 += 6 {
+		band = band[:0]
+		for dy := 0; dy < 6 && y0+dy < height; dy++ {
+			line := idx[(y0+dy)*width : (y0+dy+1)*width]
+			for x, c := range line {
+				if c < 0 {
+					continue
+				}
+				if !inBand[c] {
+					inBand[c] = true
+					band = append(band, int(c))
+					if rows[c] == nil {
+						rows[c] = make([]byte, width)
+					}
+				}
+				rows[c][x] |= 1 << dy
+			}
+		}
+		for i, c := range band {
+			if i > 0 {
+				buf.WriteByte('$')
+			}
+			_, _ = fmt.Fprintf(&buf, "#%d", c)
+			writeRow(&buf, rows[c])
+			clear(rows[c])
+			inBand[c] = false
+		}
+		buf.WriteByte('-')
+	}
+	buf.WriteString("\x1b\\")
+	return buf.Bytes()
+}
+
+// writeRow emits one color's sixels for a band, run-length encoded, with
+// trailing empty sixels dropped.
+func writeRow(buf *bytes.Buffer, row []byte) {
+	end := len(row)
+	for end > 0 && row[end-1] == 0 {
+		end--
+	}
+	for i := 0; i < end; {
+		j := i + 1
+		for j < end && row[j] == row[i] {
+			j++
+		}
+		ch := byte('?' + row[i])
+		if n := j - i; n > 3 {
+			_, _ = fmt.Fprintf(buf, "!%d%c", n, ch)
+		} else {
+			for k := 0; k < n; k++ {
+				buf.WriteByte(ch)
+			}
+		}
+		i = j
+	}
+}
+
+// resize samples img to w×h with nearest-neighbour; frames are redrawn on
+// every animation tick, so speed matters more than filtering here.
+func resize(img image.Image, w, h int) *image.RGBA {
+	b := img.Bounds()
+	src, ok := img.(*image.RGBA)
+	if !ok {
+		src = image.NewRGBA(b)
+		draw.Draw(src, b, img, b.Min, draw.Src)
+	}
+	if b.Dx() == w && b.Dy() == h {
+		return src
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		sy := b.Min.Y + y*b.Dy()/h
+		for x := 0; x < w; x++ {
+			sx := b.Min.X + x*b.Dx()/w
+			si := src.PixOffset(sx, sy)
+			copy(dst.Pix[y*dst.Stride+x*4:y*dst.Stride+x*4+4], src.Pix[si:si+4])
+		}
+	}
+	return dst
+}
+
+// quantize maps every pixel to a color-cube register with Floyd–Steinberg
+// error diffusion; transparent pixels become -1.
+func quantize(img *image.RGBA) []int16 {
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	out := make([]int16, w*h)
+	// Error rows for the current and next line, with one pixel of padding on
+	// either side.
+	cur := make([][3]int32, w+2)
+	next := make([][3]int32, w+2)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			p := img.Pix[y*img.Stride+x*4 : y*img.Stride+x*4+4]
+			a := int32(p[3])
+			if a < 0x80 {
+				out[y*w+x] = -1
+				continue
+			}
+			var want, got [3]int32
+			var c int
+			for ch := 0; ch < 3; ch++ {
+				// Unpremultiply, then add the diffused error.
+				v := int32(p[ch])*255/a + cur[x+1][ch]/16
+				v = max(0, min(255, v))
+				want[ch] = v
+				level := (int(v)*(levels-1) + 127) / 255
+				got[ch] = int32(level * 255 / (levels - 1))
+				c = c*levels + level
+			}
+			out[y*w+x] = int16(c)
+			for ch := 0; ch < 3; ch++ {
+				e := want[ch] - got[ch]
+				cur[x+2][ch] += e * 7
+				next[x][ch] += e * 3
+				next[x+1][ch] += e * 5
+				next[x+2][ch] += e
+			}
+		}
+		cur, next = next, cur
+		clear(next)
+	}
+	return out
+}
+
+func cubeColor(c int) (r, g, b int) {
+	step := 255 / (levels - 1)
+	return c / (levels * levels) * step, c / levels % levels * step, c % levels * step
+}
+
+func percent(v int) int {
+	return (v*100 + 127) / 255
+}
@@ -0,0 +1,94 @@
+package sixel
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"strings"
+	"testing"
+)
+
+func solid(w, h int, c color.Color) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(img, img.Bounds(), &image.Uniform{C: c}, image.Point{}, draw.Src)
+	return img
+}
+
+func TestEncodeSolid(t *testing.T) {
+	out := Encode(solid(10, 12, color.RGBA{R: 0xff, A: 0xff}), 10, 12)
+	s := string(out)
+	if !strings.HasPrefix(s, "\x1bP0;1;0q\"1;1;10;12") {
+		t.Fatalf("unexpected header %q", s)
+	}
+	if !strings.HasSuffix(s, "\x1b\\") {
+		t.Fatalf("missing terminator")
+	}
+	// Pure red is register 5*36 = 180.
+	if !strings.Contains(s, "#180;2;100;0;0") {
+		t.Fatalf("missing red register: %q", s)
+	}
+	// Two full bands of 10 identical sixels.
+	if strings.Count(s, "#180!10~-") != 2 {
+		t.Fatalf("expected run-length encoded bands: %q", s)
+	}
+}
+
+func TestEncodeTransparentPixelsAreSkipped(t *testing.T) {
+	img := solid(4, 6, color.Transparent)
+	img.Set(1, 0, color.White)
+	s := string(Encode(img, 4, 6))
+	// Only the white register, one sixel with the top bit at column 1.
+	if strings.Count(s, "#") != 2 || !strings.Contains(s, "#215?@-") {
+		t.Fatalf("unexpected payload %q", s)
+	}
+}
+
+func TestEncodeScalesAndClamps(t *testing.T) {
+	out := Encode(solid(2, 2, color.Black), 6, 3)
+	if !bytes.Contains(out, []byte("\"1;1;6;3")) {
+		t.Fatalf("expected scaled raster size: %q", out)
+	}
+	if !bytes.Contains(out, []byte("#0!6F-")) {
+		t.Fatalf("expected 3-row band: %q", out)
+	}
+	if Encode(nil, 4, 4) != nil || Encode(solid(1, 1, color.Black), 0, 4) != nil {
+		t.Fatalf("expected nil for empty input")
+	}
+	big := Encode(solid(1, 1, color.Black), maxSide*2, 1)
+	if !bytes.Contains(big, []byte("\"1;1;4096;1")) {
+		t.Fatalf("expected width clamp")
+	}
+}
+
+func TestEncodeDithersGradients(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 64, 6))
+	for x := 0; x < 64; x++ {
+		for y := 0; y < 6; y++ {
+			img.Set(x, y, color.Gray{Y: uint8(x * 4)})
+		}
+	}
+	s := string(Encode(img, 64, 6))
+	// Grays between cube levels need neighbouring registers mixed in.
+	if n := strings.Count(s, ";2;"); n < 6 {
+		t.Fatalf("expected at least 6 registers, got %d", n)
+	}
+}
+
+func TestFitPixels(t *testing.T) {
+	cases := []struct {
+		imgW, imgH, maxW, maxH int
+		w, h                   int
+	}{
+		{200, 100, 80, 80, 80, 40},
+		{100, 200, 80, 80, 40, 80},
+		{10, 10, 80, 40, 40, 40},
+		{0, 10, 80, 40, 0, 0},
+	}
+	for _, tc := range cases {
+		w, h := FitPixels(tc.imgW, tc.imgH, tc.maxW, tc.maxH)
+		if w != tc.w || h != tc.h {
+			t.Fatalf("FitPixels(%d,%d,%d,%d) = %dx%d, want %dx%d", tc.imgW, tc.imgH, tc.maxW, tc.maxH, w, h, tc.w, tc.h)
+		}
+	}
+}
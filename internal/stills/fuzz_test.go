@@ -0,0 +1,78 @@
+package stills
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/steipete/gifgrep/gifdecode"
+	"github.com/steipete/gifgrep/internal/testutil"
+)
+
+func addFixtureSeeds(f *testing.F, add func(data []byte)) {
+	f.Helper()
+	paths, err := filepath.Glob(filepath.Join("..", "..", "gifdecode", "testdata", "*.gif"))
+	if err != nil {
+		f.Fatalf("glob fixtures: %v", err)
+	}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			f.Fatalf("read fixture: %v", err)
+		}
+		add(data)
+	}
+	add(testutil.MakeTestGIF())
+}
+
+func fuzzDecode(data []byte) (*gifdecode.Frames, error) {
+	opts := gifdecode.DefaultOptions()
+	opts.MaxPixels = 1 << 20
+	opts.MaxTotalPixels = 1 << 22
+	opts.MaxFrames = 8
+	return gifdecode.Decode(data, opts)
+}
+
+func FuzzContactSheet(f *testing.F) {
+	addFixtureSeeds(f, func(data []byte) {
+		f.Add(data, 12, 0, 2)
+		f.Add(data, 1, 1, 0)
+	})
+	f.Add(testutil.MakeTestGIF(), 4, 1<<30, 1<<30)
+	f.Add(testutil.MakeTestGIF(), -1, -1, -1)
+	f.Fuzz(func(t *testing.T, data []byte, count, cols, padding int) {
+		decoded, err := fuzzDecode(data)
+		if err != nil {
+			return
+		}
+		out, err := ContactSheet(decoded, SheetOptions{Count: count, Columns: cols, Padding: padding})
+		if err != nil {
+			return
+		}
+		if len(out) == 0 {
+			t.Fatalf("empty sheet")
+		}
+	})
+}
+
+func FuzzFrameAtPNG(f *testing.F) {
+	addFixtureSeeds(f, func(data []byte) {
+		f.Add(data, int64(0))
+		f.Add(data, int64(1500))
+	})
+	f.Add(testutil.MakeTestGIF(), int64(-1))
+	f.Fuzz(func(t *testing.T, data []byte, atMS int64) {
+		decoded, err := fuzzDecode(data)
+		if err != nil {
+			return
+		}
+		pngData, idx, err := FrameAtPNG(decoded, time.Duration(atMS)*time.Millisecond)
+		if err != nil {
+			t.Fatalf("FrameAtPNG failed: %v", err)
+		}
+		if idx < 0 || idx >= len(decoded.Frames) || len(pngData) == 0 {
+			t.Fatalf("unexpected frame %d of %d", idx, len(decoded.Frames))
+		}
+	})
+}
@@ -14,11 +14,14 @@ import (
 )
 
 var (
-	ErrNoFrames     = errors.New("no frames")
-	ErrInvalidCount = errors.New("invalid count")
-	ErrInvalidSheet = errors.New("invalid sheet size")
+	ErrNoFrames      = errors.New("no frames")
+	ErrInvalidCount  = errors.New("invalid count")
+	ErrInvalidSheet  = errors.New("invalid sheet size")
+	ErrSheetTooLarge = errors.New("sheet too large")
 )
 
+const maxSheetPixels = 100_000_000
+
 type SheetOptions struct {
 	Count      int
 	Columns    int
@@ -47,6 +50,23 @@ func FrameIndexAt(frames []gifdecode.Frame, at time.Duration) (int, error) {
 	return len(frames) - 1, nil
 }
 
+// FrameRange maps from/to timestamps to inclusive frame indices.
+// A zero to means "through the last frame".
+func FrameRange(frames []gifdecode.Frame, from, to time.Duration) (int, int, error) {
+	first, err := FrameIndexAt(frames, from)
+	if err != nil {
+		return -1, -1, err
+	}
+	last := len(frames) - 1
+	if to > 0 {
+		last, err = FrameIndexAt(frames, to)
+		if err != nil {
+			return -1, -1, err
+		}
+	}
+	return first, last, nil
+}
+
 func FrameAtPNG(decoded *gifdecode.Frames, at time.Duration) ([]byte, int, error) {
 	if decoded == nil {
 		return nil, -1, ErrNoFrames
@@ -72,6 +92,9 @@ func ContactSheet(decoded *gifdecode.Frames, opts SheetOptions) ([]byte, error)
 	if opts.Columns <= 0 {
 		opts.Columns = int(math.Ceil(math.Sqrt(float64(opts.Count))))
 	}
+	if opts.Columns > opts.Count {
+		opts.Columns = opts.Count
+	}
 	if opts.Padding < 0 {
 		opts.Padding = 0
 	}
@@ -82,12 +105,11 @@ func ContactSheet(decoded *gifdecode.Frames, opts SheetOptions) ([]byte, error)
 	frameWidth := decoded.Width
 	frameHeight := decoded.Height
 	if frameWidth <= 0 || frameHeight <= 0 {
-		img, err := decodePNG(decoded.Frames[0].PNG)
+		cfg, err := png.DecodeConfig(bytes.NewReader(decoded.Frames[0].PNG))
 		if err != nil {
 			return nil, err
 		}
-		b := img.Bounds()
-		frameWidth, frameHeight = b.Dx(), b.Dy()
+		frameWidth, frameHeight = cfg.Width, cfg.Height
 	}
 	if frameWidth <= 0 || frameHeight <= 0 {
 		return nil, ErrInvalidSheet
@@ -95,8 +117,13 @@ func ContactSheet(decoded *gifdecode.Frames, opts SheetOptions) ([]byte, error)
 
 	rows := int(math.Ceil(float64(opts.Count) / float64(opts.Columns)))
 
-	sheetWidth := frameWidth*opts.Columns + opts.Padding*(opts.Columns-1)
-	sheetHeight := frameHeight*rows + opts.Padding*(rows-1)
+	sheetWidth64 := int64(frameWidth)*int64(opts.Columns) + int64(opts.Padding)*int64(opts.Columns-1)
+	sheetHeight64 := int64(frameHeight)*int64(rows) + int64(opts.Padding)*int64(rows-1)
+	if sheetWidth64 > maxSheetPixels || sheetHeight64 > maxSheetPixels || sheetWidth64*sheetHeight64 > maxSheetPixels {
+		return nil, ErrSheetTooLarge
+	}
+	sheetWidth := int(sheetWidth64)
+	sheetHeight := int(sheetHeight64)
 
 	sheet := image.NewRGBA(image.Rect(0, 0, sheetWidth, sheetHeight))
 	draw.Draw(sheet, sheet.Bounds(), &image.Uniform{C: opts.Background}, image.Point{}, draw.Src)
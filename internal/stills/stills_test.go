@@ -2,6 +2,7 @@ package stills
 
 import (
 	"bytes"
+	"errors"
 	"image"
 	"image/color"
 	"image/draw"
@@ -85,6 +86,21 @@ func TestFrameIndexAtBounds(t *testing.T) {
 	}
 }
 
+func TestFrameRange(t *testing.T) {
+	frames := []gifdecode.Frame{{Delay: 10 * time.Millisecond}, {Delay: 20 * time.Millisecond}, {Delay: 30 * time.Millisecond}}
+	first, last, err := FrameRange(frames, 15*time.Millisecond, 0)
+	if err != nil || first != 1 || last != 2 {
+		t.Fatalf("unexpected range %d..%d (%v)", first, last, err)
+	}
+	first, last, err = FrameRange(frames, 0, 10*time.Millisecond)
+	if err != nil || first != 0 || last != 1 {
+		t.Fatalf("unexpected range %d..%d (%v)", first, last, err)
+	}
+	if _, _, err := FrameRange(nil, 0, 0); err == nil {
+		t.Fatalf("expected error for no frames")
+	}
+}
+
 func TestFrameIndexAtErrors(t *testing.T) {
 	if _, err := FrameIndexAt(nil, 0); err == nil {
 		t.Fatalf("expected error on empty frames")
@@ -219,6 +235,32 @@ func TestContactSheetSingleFrame(t *testing.T) {
 	}
 }
 
+func TestContactSheetRejectsHugeLayout(t *testing.T) {
+	pngData := makeSolidPNG(color.White, 1, 1)
+	decoded := &gifdecode.Frames{
+		Frames: []gifdecode.Frame{
+			{PNG: pngData, Delay: 10 * time.Millisecond},
+			{PNG: pngData, Delay: 10 * time.Millisecond},
+		},
+		Width:  1,
+		Height: 1,
+	}
+	if _, err := ContactSheet(decoded, SheetOptions{Count: 2, Columns: 2, Padding: 1 << 30}); !errors.Is(err, ErrSheetTooLarge) {
+		t.Fatalf("expected ErrSheetTooLarge, got %v", err)
+	}
+	out, err := ContactSheet(decoded, SheetOptions{Count: 2, Columns: 1 << 30})
+	if err != nil {
+		t.Fatalf("ContactSheet failed: %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(out))
+	if err != nil {
+		t.Fatalf("png decode failed: %v", err)
+	}
+	if img.Bounds().Dx() != 2 || img.Bounds().Dy() != 1 {
+		t.Fatalf("expected columns clamped to count, got %dx%d", img.Bounds().Dx(), img.Bounds().Dy())
+	}
+}
+
 func makeSolidPNG(c color.Color, w, h int) []byte {
 	img := image.NewRGBA(image.Rect(0, 0, w, h))
 	draw.Draw(img, img.Bounds(), &image.Uniform{C: c}, image.Point{}, draw.Src)
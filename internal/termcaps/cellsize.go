@@ -0,0 +1,127 @@
+package termcaps
+
+import (
+	"os"
+	"time"
+)
+
+// CellSize is the size of one terminal cell in pixels.
+type CellSize struct {
+	Width  int
+	Height int
+}
+
+func (c CellSize) Valid() bool {
+	return c.Width > 0 && c.Height > 0
+}
+
+// Aspect returns width/height, or 0 when the size is unknown.
+func (c CellSize) Aspect() float64 {
+	if !c.Valid() {
+		return 0
+	}
+	return float64(c.Width) / float64(c.Height)
+}
+
+// DetectCellSize asks the controlling terminal for its cell size: the pixel
+// fields of TIOCGWINSZ when the terminal fills them in, otherwise, when they
+// are zero, a CSI 16 t / CSI 14 t query. Returns the zero CellSize when
+// neither answers.
+func DetectCellSize() CellSize {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return CellSize{}
+	}
+	defer func() { _ = tty.Close() }()
+	return cellSizeFor(sysFd(tty), func() CellSize { return probeCellSize(tty, 150*time.Millisecond) })
+}
+
+// cellSizeFor reads the cell size from the window size of fd, running probe
+// only when the window size is known but has no pixel fields.
+func cellSizeFor(fd int, probe func() CellSize) CellSize {
+	if c := WinsizeCellSize(fd); c.Valid() {
+		return c
+	}
+	if _, _, xpix, ypix, ok := winsize(fd); !ok || xpix != 0 || ypix != 0 {
+		return CellSize{}
+	}
+	return probe()
+}
+
+// WinsizeCellSize reads the cell size from the window size of fd alone, with
+// no terminal round trip. Cheap enough to call on every resize.
+func WinsizeCellSize(fd int) CellSize {
+	cols, rows, xpix, ypix, ok := winsize(fd)
+	if !ok || cols <= 0 || rows <= 0 || xpix <= 0 || ypix <= 0 {
+		return CellSize{}
+	}
+	return CellSize{Width: xpix / cols, Height: ypix / rows}
+}
+
+// probeCellSize sends the XTWINOPS size reports followed by DA1, which every
+// terminal answers, so a terminal without XTWINOPS ends the wait early.
+func probeCellSize(tty *os.File, timeout time.Duration) CellSize {
+	if tty == nil {
+		return CellSize{}
+	}
+	defer rawMode(tty)()
+	// 16t: cell size; 14t: text area size; 18t: text area in cells.
+	_, _ = tty.Write([]byte("\x1b[16t\x1b[14t\x1b[18t\x1b[c"))
+
+	return cellSizeFromReports(readUntilDA1(tty, timeout))
+}
+
+// cellSizeFromReports prefers the direct cell size report (CSI 6;h;w t) and
+// otherwise divides the text area (CSI 4;h;w t) by the grid (CSI 8;rows;cols t).
+func cellSizeFromReports(b []byte) CellSize {
+	var area, grid []int
+	for _, r := range windowReports(b) {
+		switch r[0] {
+		case 6:
+			if c := (CellSize{Width: r[2], Height: r[1]}); c.Valid() {
+				return c
+			}
+		case 4:
+			area = r
+		case 8:
+			grid = r
+		}
+	}
+	if area == nil || grid == nil || grid[1] <= 0 || grid[2] <= 0 {
+		return CellSize{}
+	}
+	return CellSize{Width: area[2] / grid[2], Height: area[1] / grid[1]}
+}
+
+// windowReports extracts every three-parameter XTWINOPS reply (ESC [ a;b;c t).
+func windowReports(b []byte) [][]int {
+	var out [][]int
+	for i := 0; i+2 < len(b); i++ {
+		if b[i] != 0x1b || b[i+1] != '[' {
+			continue
+		}
+		var params []int
+		cur, digits := 0, 0
+		for j := i + 2; j < len(b) && j-i < 32; j++ {
+			ch := b[j]
+			if ch >= '0' && ch <= '9' {
+				cur = cur*10 + int(ch-'0')
+				digits++
+				continue
+			}
+			if (ch == ';' || ch == 't') && digits > 0 {
+				params = append(params, cur)
+				cur, digits = 0, 0
+				if ch == 't' {
+					if len(params) == 3 {
+						out = append(out, params)
+					}
+					break
+				}
+				continue
+			}
+			break
+		}
+	}
+	return out
+}
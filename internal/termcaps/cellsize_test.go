@@ -0,0 +1,47 @@
+package termcaps
+
+import "testing"
+
+func TestCellSizeFromReports(t *testing.T) {
+	cases := []struct {
+		name string
+		in   string
+		want CellSize
+	}{
+		{"cell report", "\x1b[6;20;10t\x1b[4;480;800t\x1b[8;24;80t\x1b[?62;4c", CellSize{Width: 10, Height: 20}},
+		{"area over grid", "\x1b[4;432;720t\x1b[8;24;80t\x1b[?1;2c", CellSize{Width: 9, Height: 18}},
+		{"only DA1", "\x1b[?1;2c", CellSize{}},
+		{"area without grid", "\x1b[4;432;720t", CellSize{}},
+		{"zero cell report", "\x1b[6;0;0t", CellSize{}},
+	}
+	for _, tc := range cases {
+		if got := cellSizeFromReports([]byte(tc.in)); got != tc.want {
+			t.Fatalf("%s: got %+v, want %+v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestCellSizeAspect(t *testing.T) {
+	if a := (CellSize{Width: 8, Height: 16}).Aspect(); a != 0.5 {
+		t.Fatalf("unexpected aspect %v", a)
+	}
+	if (CellSize{Width: 8}).Valid() || (CellSize{}).Aspect() != 0 {
+		t.Fatalf("expected invalid zero-height size")
+	}
+}
+
+func TestWinsizeCellSizeNotATerminal(t *testing.T) {
+	if c := WinsizeCellSize(-1); c.Valid() {
+		t.Fatalf("expected no size for a bad fd, got %+v", c)
+	}
+}
+
+func TestCellSizeForSkipsProbeWithoutWindowSize(t *testing.T) {
+	probe := func() CellSize {
+		t.Fatalf("probe should not run when TIOCGWINSZ fails")
+		return CellSize{}
+	}
+	if c := cellSizeFor(-1, probe); c.Valid() {
+		t.Fatalf("expected no size, got %+v", c)
+	}
+}
@@ -12,6 +12,10 @@ const (
 	InlineNone InlineProtocol = iota
 	InlineKitty
 	InlineIterm
+	InlineSixel
+	// InlineBlocks draws images as colored Unicode block characters. It works
+	// in any terminal, so it is only used as a fallback or when forced.
+	InlineBlocks
 )
 
 func (p InlineProtocol) String() string {
@@ -22,49 +26,70 @@ func (p InlineProtocol) String() string {
 		return "kitty"
 	case InlineIterm:
 		return "iterm"
+	case InlineSixel:
+		return "sixel"
+	case InlineBlocks:
+		return "blocks"
 	default:
 		return "none"
 	}
 }
 
 func DetectInline(getenv func(string) string) InlineProtocol {
+	p, _ := detectInlineEnv(getenv)
+	return p
+}
+
+// detectInlineEnv is DetectInline that also reports whether the environment
+// settled the answer (an override or a known terminal, including ones known
+// to have no image protocol) rather than merely finding nothing.
+func detectInlineEnv(getenv func(string) string) (InlineProtocol, bool) {
 	if getenv == nil {
 		getenv = os.Getenv
 	}
 
 	switch strings.ToLower(strings.TrimSpace(getenv("GIFGREP_INLINE"))) {
 	case "kitty":
-		return InlineKitty
+		return InlineKitty, true
 	case "iterm", "iterm2":
-		return InlineIterm
+		return InlineIterm, true
+	case "sixel":
+		return InlineSixel, true
+	case "blocks", "ansi", "text":
+		return InlineBlocks, true
 	case "none", "off", "false", "0":
-		return InlineNone
+		return InlineNone, true
 	case "", "auto":
 	default:
-		return InlineNone
+		return InlineNone, true
 	}
 
 	if strings.TrimSpace(getenv("KITTY_WINDOW_ID")) != "" {
-		return InlineKitty
+		return InlineKitty, true
 	}
 
 	termProgram := strings.ToLower(getenv("TERM_PROGRAM"))
 	if strings.Contains(termProgram, "ghostty") {
-		return InlineKitty
+		return InlineKitty, true
 	}
-	if strings.Contains(termProgram, "iterm") || strings.TrimSpace(getenv("ITERM_SESSION_ID")) != "" {
-		return InlineIterm
+	// LC_TERMINAL survives ssh and tmux, where TERM_PROGRAM doesn't.
+	if strings.Contains(termProgram, "iterm") || strings.TrimSpace(getenv("ITERM_SESSION_ID")) != "" ||
+		strings.EqualFold(strings.TrimSpace(getenv("LC_TERMINAL")), "iTerm2") {
+		return InlineIterm, true
 	}
 	if strings.Contains(termProgram, "apple_terminal") {
-		return InlineNone
+		return InlineNone, true
 	}
 
 	termEnv := strings.ToLower(getenv("TERM"))
 	if strings.Contains(termEnv, "xterm-kitty") || strings.Contains(termEnv, "ghostty") {
-		return InlineKitty
+		return InlineKitty, true
+	}
+	if strings.HasPrefix(termEnv, "foot") || strings.HasPrefix(termEnv, "mlterm") {
+		return InlineSixel, true
 	}
 
-	return InlineNone
+	return InlineNone, false
 }
 
 type kittyProbeResult int
@@ -76,21 +101,47 @@ const (
 )
 
 func DetectInlineRobust(getenv func(string) string) InlineProtocol {
-	return detectInlineRobust(getenv, func() kittyProbeResult {
+	if getenv == nil {
+		getenv = os.Getenv
+	}
+	mux := DetectMultiplexer(getenv)
+	return detectInlineRobust(outerEnv(getenv, mux, tmuxClientTerm), func() kittyProbeResult {
+		if mux != MuxNone {
+			// Multiplexers don't forward graphics replies to the pane, so a
+			// missing answer says nothing; trust the outer terminal's TERM.
+			return kittyProbeUnknown
+		}
 		tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
 		if err != nil {
 			return kittyProbeUnknown
 		}
 		defer func() { _ = tty.Close() }()
 		return probeKittyGraphics(tty, 150*time.Millisecond)
+	}, func() bool {
+		tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+		if err != nil {
+			return false
+		}
+		defer func() { _ = tty.Close() }()
+		return probeSixel(tty, 150*time.Millisecond)
 	})
 }
 
-func detectInlineRobust(getenv func(string) string, probeKitty func() kittyProbeResult) InlineProtocol {
+func detectInlineRobust(getenv func(string) string, probeKitty func() kittyProbeResult, probeSixel func() bool) InlineProtocol {
 	if getenv == nil {
 		getenv = os.Getenv
 	}
-	p := DetectInline(getenv)
+	p, decided := detectInlineEnv(getenv)
+	if !decided {
+		// Sixel terminals (xterm -ti vt340, WezTerm, Windows Terminal, ...) rarely
+		// identify themselves in the environment, but advertise sixel in DA1.
+		// Only probe when the environment said nothing, since each probe is a
+		// round trip whose reply must not leak into later input.
+		if probeSixel() {
+			return InlineSixel
+		}
+		return p
+	}
 	if p != InlineKitty {
 		return p
 	}
@@ -115,3 +166,21 @@ func detectInlineRobust(getenv func(string) string, probeKitty func() kittyProbe
 		return InlineKitty
 	}
 }
+
+// DetectTruecolor reports whether the terminal advertises 24-bit color via
+// COLORTERM (or a known TERM/TERM_PROGRAM).
+func DetectTruecolor(getenv func(string) string) bool {
+	if getenv == nil {
+		getenv = os.Getenv
+	}
+	switch strings.ToLower(strings.TrimSpace(getenv("COLORTERM"))) {
+	case "truecolor", "24bit":
+		return true
+	}
+	termEnv := strings.ToLower(getenv("TERM"))
+	if strings.Contains(termEnv, "direct") || strings.Contains(termEnv, "kitty") || strings.Contains(termEnv, "ghostty") {
+		return true
+	}
+	termProgram := strings.ToLower(getenv("TERM_PROGRAM"))
+	return strings.Contains(termProgram, "iterm") || strings.Contains(termProgram, "wezterm") || strings.Contains(termProgram, "ghostty")
+}
@@ -1,6 +1,10 @@
 package termcaps
 
-import "testing"
+import (
+	"os"
+	"testing"
+	"time"
+)
 
 func TestDetectInlineOverride(t *testing.T) {
 	getenv := func(k string) string {
@@ -55,7 +59,7 @@ func TestDetectInlineRobustProbeNegative(t *testing.T) {
 			return ""
 		}
 	}
-	got := detectInlineRobust(getenv, func() kittyProbeResult { return kittyProbeNotSupported })
+	got := detectInlineRobust(getenv, func() kittyProbeResult { return kittyProbeNotSupported }, noSixel)
 	if got != InlineNone {
 		t.Fatalf("expected none, got %v", got)
 	}
@@ -70,8 +74,139 @@ func TestDetectInlineRobustProbeUnknownKeepsKitty(t *testing.T) {
 			return ""
 		}
 	}
-	got := detectInlineRobust(getenv, func() kittyProbeResult { return kittyProbeUnknown })
+	got := detectInlineRobust(getenv, func() kittyProbeResult { return kittyProbeUnknown }, noSixel)
 	if got != InlineKitty {
 		t.Fatalf("expected kitty, got %v", got)
 	}
 }
+
+func noSixel() bool { return false }
+
+func TestDetectInlineSixelEnv(t *testing.T) {
+	getenv := func(k string) string {
+		switch k {
+		case "TERM":
+			return "foot-extra"
+		default:
+			return ""
+		}
+	}
+	if got := DetectInline(getenv); got != InlineSixel {
+		t.Fatalf("expected sixel, got %v", got)
+	}
+	override := func(k string) string {
+		if k == "GIFGREP_INLINE" {
+			return "sixel"
+		}
+		return ""
+	}
+	if got := DetectInline(override); got != InlineSixel {
+		t.Fatalf("expected sixel override, got %v", got)
+	}
+}
+
+func TestDetectInlineRobustProbesSixel(t *testing.T) {
+	getenv := func(k string) string {
+		switch k {
+		case "TERM":
+			return "xterm-256color"
+		default:
+			return ""
+		}
+	}
+	kittyProbe := func() kittyProbeResult {
+		t.Fatalf("kitty probe should not run")
+		return kittyProbeUnknown
+	}
+	if got := detectInlineRobust(getenv, kittyProbe, func() bool { return true }); got != InlineSixel {
+		t.Fatalf("expected sixel, got %v", got)
+	}
+	if got := detectInlineRobust(getenv, kittyProbe, noSixel); got != InlineNone {
+		t.Fatalf("expected none, got %v", got)
+	}
+}
+
+func TestDetectInlineRobustEnvDecisionSkipsSixelProbe(t *testing.T) {
+	probe := func() bool {
+		t.Fatalf("sixel probe should not run")
+		return true
+	}
+	for _, env := range []map[string]string{
+		{"GIFGREP_INLINE": "none"},
+		{"TERM_PROGRAM": "Apple_Terminal", "TERM": "xterm-256color"},
+		{"TERM": "foot"},
+	} {
+		want, _ := detectInlineEnv(func(k string) string { return env[k] })
+		if got := detectInlineRobust(func(k string) string { return env[k] }, nil, probe); got != want {
+			t.Fatalf("%v: got %v, want %v", env, got, want)
+		}
+	}
+}
+
+func TestReadUntilDA1ConsumesLateReply(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = r.Close(); _ = w.Close() })
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_, _ = w.Write([]byte("\x1b[?62;"))
+		time.Sleep(20 * time.Millisecond)
+		_, _ = w.Write([]byte("4c"))
+	}()
+	acc := readUntilDA1(r, 10*time.Millisecond)
+	if attrs, ok := da1Attributes(acc); !ok || len(attrs) != 2 || attrs[1] != da1Sixel {
+		t.Fatalf("late reply not read: %q", acc)
+	}
+
+	// Nothing of the reply is left for the next reader.
+	_, _ = w.Write([]byte("q"))
+	_ = r.SetReadDeadline(time.Now().Add(time.Second))
+	buf := make([]byte, 16)
+	if n, err := r.Read(buf); err != nil || string(buf[:n]) != "q" {
+		t.Fatalf("next read = %q, %v", buf[:n], err)
+	}
+}
+
+func TestDA1Attributes(t *testing.T) {
+	attrs, ok := da1Attributes([]byte("junk\x1b[?63;1;2;4;6;9;15;22c"))
+	if !ok || len(attrs) != 8 || attrs[3] != 4 {
+		t.Fatalf("unexpected attrs %v (%v)", attrs, ok)
+	}
+	if _, ok := da1Attributes([]byte("\x1b[?62;")); ok {
+		t.Fatalf("expected incomplete response to be rejected")
+	}
+	if !hasDA1Response([]byte("\x1b[?1;2c")) {
+		t.Fatalf("expected DA1 response")
+	}
+}
+
+func TestDetectInlineBlocksOverride(t *testing.T) {
+	getenv := func(k string) string {
+		if k == "GIFGREP_INLINE" {
+			return "blocks"
+		}
+		return ""
+	}
+	if got := DetectInline(getenv); got != InlineBlocks {
+		t.Fatalf("expected blocks, got %v", got)
+	}
+}
+
+func TestDetectTruecolor(t *testing.T) {
+	env := map[string]string{}
+	getenv := func(k string) string { return env[k] }
+	if DetectTruecolor(getenv) {
+		t.Fatalf("expected no truecolor for empty env")
+	}
+	env["COLORTERM"] = "truecolor"
+	if !DetectTruecolor(getenv) {
+		t.Fatalf("expected truecolor from COLORTERM")
+	}
+	env = map[string]string{"TERM": "xterm-direct"}
+	if !DetectTruecolor(getenv) {
+		t.Fatalf("expected truecolor from TERM")
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"os"
 	"time"
+
+	"golang.org/x/term"
 )
 
 // probeKittyGraphics implements the kitty docs recommendation:
@@ -14,42 +16,82 @@ func probeKittyGraphics(tty *os.File, timeout time.Duration) kittyProbeResult {
 		return kittyProbeUnknown
 	}
 
+	defer rawMode(tty)()
+
 	// Example from kitty docs:
 	// <ESC>_Gi=31,s=1,v=1,a=q,t=d,f=24;AAAA<ESC>\<ESC>[c
 	_, _ = tty.Write([]byte("\x1b_Gi=31,s=1,v=1,a=q,t=d,f=24;AAAA\x1b\\\x1b[c"))
 
-	deadline := time.Now().Add(timeout)
+	acc := readUntilDA1(tty, timeout)
+	if bytes.Contains(acc, []byte("\x1b_Gi=31;")) || bytes.Contains(acc, []byte("\x1b_Gi=31,")) {
+		return kittyProbeSupported
+	}
+	if hasDA1Response(acc) {
+		return kittyProbeNotSupported
+	}
+	return kittyProbeUnknown
+}
+
+// drainTimeout is how much longer than its timeout a probe waits for the
+// DA1 reply. A reply left unread would reach whatever reads the tty next,
+// such as the TUI's key input.
+const drainTimeout = time.Second
+
+// readUntilDA1 reads tty until a DA1 reply, which terminals send after
+// answering every query written before it, and returns everything read.
+// Replies usually arrive well within timeout; a slow one (over SSH, say) is
+// still consumed for up to drainTimeout more.
+func readUntilDA1(tty *os.File, timeout time.Duration) []byte {
+	deadline := time.Now().Add(timeout + drainTimeout)
 	_ = tty.SetReadDeadline(deadline)
 
 	var buf [1024]byte
-	var acc []byte
-	acc = make([]byte, 0, 2048)
-	daSeen := false
-
+	acc := make([]byte, 0, 2048)
 	for time.Now().Before(deadline) {
 		n, err := tty.Read(buf[:])
 		if n > 0 {
 			acc = append(acc, buf[:n]...)
-			if bytes.Contains(acc, []byte("\x1b_Gi=31;")) || bytes.Contains(acc, []byte("\x1b_Gi=31,")) {
-				return kittyProbeSupported
-			}
 			if hasDA1Response(acc) {
-				daSeen = true
+				break
 			}
 		}
 		if err != nil {
 			break
 		}
 	}
+	return acc
+}
 
-	if daSeen {
-		return kittyProbeNotSupported
+// sysFd returns tty's descriptor. Unlike Fd it leaves the file in
+// non-blocking mode, which the probes' read deadlines need.
+func sysFd(tty *os.File) int {
+	fd := -1
+	if rc, err := tty.SyscallConn(); err == nil {
+		_ = rc.Control(func(f uintptr) { fd = int(f) })
 	}
-	return kittyProbeUnknown
+	return fd
+}
+
+// rawMode switches tty to raw mode for a probe and returns the restore func.
+// In cooked mode the terminal's reply is echoed and held until Enter, so it
+// never reaches the probe.
+func rawMode(tty *os.File) func() {
+	fd := sysFd(tty)
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return func() {}
+	}
+	return func() { _ = term.Restore(fd, state) }
 }
 
 func hasDA1Response(b []byte) bool {
-	// Typical primary device attributes response: ESC [ ? ... c
+	_, ok := da1Attributes(b)
+	return ok
+}
+
+// da1Attributes finds a primary device attributes response (ESC [ ? ... c)
+// and returns its numeric parameters.
+func da1Attributes(b []byte) ([]int, bool) {
 	for i := 0; i+3 < len(b); i++ {
 		if b[i] != 0x1b || b[i+1] != '[' {
 			continue
@@ -58,17 +100,29 @@ func hasDA1Response(b []byte) bool {
 		if j < len(b) && b[j] == '?' {
 			j++
 		}
+		var attrs []int
+		cur, digits := 0, 0
 		for j < len(b) && j-i < 64 {
 			ch := b[j]
-			if ch == 'c' {
-				return true
+			if ch >= '0' && ch <= '9' {
+				cur = cur*10 + int(ch-'0')
+				digits++
+				j++
+				continue
 			}
-			if (ch >= '0' && ch <= '9') || ch == ';' {
+			if ch == ';' || ch == 'c' {
+				if digits > 0 {
+					attrs = append(attrs, cur)
+				}
+				cur, digits = 0, 0
+				if ch == 'c' {
+					return attrs, true
+				}
 				j++
 				continue
 			}
 			break
 		}
 	}
-	return false
+	return nil, false
 }
@@ -0,0 +1,187 @@
+package termcaps
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+type Multiplexer int
+
+const (
+	MuxNone Multiplexer = iota
+	MuxTmux
+	MuxScreen
+)
+
+func (m Multiplexer) String() string {
+	switch m {
+	case MuxNone:
+		return "none"
+	case MuxTmux:
+		return "tmux"
+	case MuxScreen:
+		return "screen"
+	default:
+		return "none"
+	}
+}
+
+func DetectMultiplexer(getenv func(string) string) Multiplexer {
+	if getenv == nil {
+		getenv = os.Getenv
+	}
+	if strings.TrimSpace(getenv("TMUX")) != "" {
+		return MuxTmux
+	}
+	if strings.TrimSpace(getenv("STY")) != "" {
+		return MuxScreen
+	}
+	return MuxNone
+}
+
+// tmuxClientTerm asks tmux for the TERM of the attached client, i.e. the
+// terminal outside tmux.
+var tmuxClientTerm = func() string {
+	out, err := exec.Command("tmux", "display-message", "-p", "#{client_termname}").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// outerEnv hides the multiplexer's TERM/TERM_PROGRAM so detection sees the
+// outer terminal. Variables like KITTY_WINDOW_ID or LC_TERMINAL are inherited
+// from the shell that started the session and are used as-is.
+func outerEnv(getenv func(string) string, mux Multiplexer, clientTerm func() string) func(string) string {
+	if mux == MuxNone {
+		return getenv
+	}
+	var term string
+	if mux == MuxTmux && clientTerm != nil {
+		term = clientTerm()
+	}
+	return func(k string) string {
+		switch k {
+		case "TERM":
+			return term
+		case "TERM_PROGRAM":
+			if v := getenv(k); !strings.EqualFold(v, "tmux") && !strings.EqualFold(v, "screen") {
+				return v
+			}
+			return ""
+		default:
+			return getenv(k)
+		}
+	}
+}
+
+// screen drops DCS strings longer than this, so passthrough is chunked.
+const screenChunk = 768
+
+// WrapPassthrough wraps one escape sequence in the multiplexer's DCS
+// passthrough envelope so it reaches the outer terminal.
+func WrapPassthrough(mux Multiplexer, seq []byte) []byte {
+	switch mux {
+	case MuxTmux:
+		// tmux: ESC P tmux; <seq with every ESC doubled> ESC \
+		out := make([]byte, 0, len(seq)+len(seq)/64+16)
+		out = append(out, "\x1bPtmux;"...)
+		for _, b := range seq {
+			if b == 0x1b {
+				out = append(out, 0x1b)
+			}
+			out = append(out, b)
+		}
+		return append(out, "\x1b\\"...)
+	case MuxScreen:
+		// screen ends the DCS at the first ST, so OSC sequences switch to
+		// BEL and are sent in chunks, each in its own envelope.
+		if bytes.HasPrefix(seq, []byte("\x1b]")) && bytes.HasSuffix(seq, []byte("\x1b\\")) {
+			seq = append(seq[:len(seq)-2:len(seq)-2], 0x07)
+		}
+		var out []byte
+		for len(seq) > 0 {
+			n := min(len(seq), screenChunk)
+			out = append(out, "\x1bP"...)
+			out = append(out, seq[:n]...)
+			out = append(out, "\x1b\\"...)
+			seq = seq[n:]
+		}
+		return out
+	default:
+		return seq
+	}
+}
+
+// PassthroughWriter wraps Kitty graphics (APC) and iTerm2 image (OSC 1337)
+// sequences for the multiplexer as they stream by; everything else, cursor
+// movement included, goes to the multiplexer unchanged. Sixel (DCS) isn't
+// wrapped: tmux 3.4+ renders it itself and answers DA1 accordingly.
+func PassthroughWriter(w io.Writer, mux Multiplexer) io.Writer {
+	if mux == MuxNone {
+		return w
+	}
+	return &passthroughWriter{w: w, mux: mux}
+}
+
+type passthroughWriter struct {
+	w   io.Writer
+	mux Multiplexer
+	// pendingEsc is set when the last byte seen outside a sequence was ESC.
+	pendingEsc bool
+	// seq holds a graphics sequence being captured, from its introducer on.
+	seq []byte
+}
+
+func (p *passthroughWriter) Write(b []byte) (int, error) {
+	var out []byte
+	for _, c := range b {
+		if p.seq != nil {
+			p.seq = append(p.seq, c)
+			if p.sequenceDone() {
+				if p.isGraphics() {
+					out = append(out, WrapPassthrough(p.mux, p.seq)...)
+				} else {
+					out = append(out, p.seq...)
+				}
+				p.seq = nil
+			}
+			continue
+		}
+		if p.pendingEsc {
+			p.pendingEsc = false
+			if c == '_' || c == ']' {
+				p.seq = []byte{0x1b, c}
+				continue
+			}
+			out = append(out, 0x1b)
+		}
+		if c == 0x1b {
+			p.pendingEsc = true
+			continue
+		}
+		out = append(out, c)
+	}
+	if len(out) > 0 {
+		if _, err := p.w.Write(out); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+func (p *passthroughWriter) sequenceDone() bool {
+	n := len(p.seq)
+	if n >= 4 && p.seq[n-2] == 0x1b && p.seq[n-1] == '\\' {
+		return true
+	}
+	// OSC may also end with BEL.
+	return p.seq[1] == ']' && p.seq[n-1] == 0x07
+}
+
+func (p *passthroughWriter) isGraphics() bool {
+	return p.seq[1] == '_' || bytes.HasPrefix(p.seq, []byte("\x1b]1337;"))
+}
@@ -0,0 +1,86 @@
+package termcaps
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestDetectMultiplexer(t *testing.T) {
+	env := map[string]string{}
+	getenv := func(k string) string { return env[k] }
+	if got := DetectMultiplexer(getenv); got != MuxNone {
+		t.Fatalf("expected none, got %v", got)
+	}
+	env["STY"] = "1234.pts-0.host"
+	if got := DetectMultiplexer(getenv); got != MuxScreen {
+		t.Fatalf("expected screen, got %v", got)
+	}
+	env["TMUX"] = "/tmp/tmux-1000/default,1,0"
+	if got := DetectMultiplexer(getenv); got != MuxTmux {
+		t.Fatalf("expected tmux, got %v", got)
+	}
+}
+
+func TestOuterEnvUsesTmuxClientTerm(t *testing.T) {
+	env := map[string]string{
+		"TMUX":         "/tmp/tmux-1000/default,1,0",
+		"TERM":         "tmux-256color",
+		"TERM_PROGRAM": "tmux",
+	}
+	getenv := outerEnv(func(k string) string { return env[k] }, MuxTmux, func() string { return "xterm-kitty" })
+	if getenv("TERM") != "xterm-kitty" || getenv("TERM_PROGRAM") != "" {
+		t.Fatalf("unexpected outer env: TERM=%q TERM_PROGRAM=%q", getenv("TERM"), getenv("TERM_PROGRAM"))
+	}
+	if got := DetectInline(getenv); got != InlineKitty {
+		t.Fatalf("expected kitty through tmux, got %v", got)
+	}
+}
+
+func TestDetectInlineLCTerminal(t *testing.T) {
+	env := map[string]string{"TERM": "tmux-256color", "LC_TERMINAL": "iTerm2"}
+	if got := DetectInline(func(k string) string { return env[k] }); got != InlineIterm {
+		t.Fatalf("expected iterm, got %v", got)
+	}
+}
+
+func TestWrapPassthroughTmux(t *testing.T) {
+	got := string(WrapPassthrough(MuxTmux, []byte("\x1b_Ga=d\x1b\\")))
+	if got != "\x1bPtmux;\x1b\x1b_Ga=d\x1b\x1b\\\x1b\\" {
+		t.Fatalf("unexpected tmux envelope %q", got)
+	}
+}
+
+func TestWrapPassthroughScreenChunksAndUsesBEL(t *testing.T) {
+	seq := "\x1b]1337;File=inline=1:" + strings.Repeat("A", 1000) + "\x1b\\"
+	got := string(WrapPassthrough(MuxScreen, []byte(seq)))
+	if strings.Count(got, "\x1bP") != 2 {
+		t.Fatalf("expected 2 chunks, got %q", got)
+	}
+	if !strings.HasSuffix(got, "A\x07\x1b\\") {
+		t.Fatalf("expected BEL-terminated OSC: %q", got[len(got)-10:])
+	}
+}
+
+func TestPassthroughWriterWrapsOnlyGraphics(t *testing.T) {
+	var buf bytes.Buffer
+	w := PassthroughWriter(&buf, MuxTmux)
+	stream := "\x1b[2;3Hhi\x1b_Ga=T;AAAA\x1b\\\x1b]0;title\x07\x1b]1337;File=:QQ==\x07done"
+	// Split writes mid-sequence, as bufio flushes would.
+	for _, part := range []string{stream[:5], stream[5:15], stream[15:]} {
+		if _, err := w.Write([]byte(part)); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	want := "\x1b[2;3Hhi" +
+		"\x1bPtmux;\x1b\x1b_Ga=T;AAAA\x1b\x1b\\\x1b\\" +
+		"\x1b]0;title\x07" +
+		"\x1bPtmux;\x1b\x1b]1337;File=:QQ==\x07\x1b\\" +
+		"done"
+	if buf.String() != want {
+		t.Fatalf("unexpected stream\n got %q\nwant %q", buf.String(), want)
+	}
+	if PassthroughWriter(&buf, MuxNone) != &buf {
+		t.Fatalf("expected writer unchanged outside a multiplexer")
+	}
+}
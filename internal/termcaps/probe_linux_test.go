@@ -0,0 +1,31 @@
+//go:build linux
+
+package termcaps
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steipete/gifgrep/internal/testutil"
+)
+
+func TestProbeSixelReadsReplyInPTY(t *testing.T) {
+	found := make(chan bool, 1)
+	p := testutil.StartPTY(t, 5, 40, func(tty *os.File, _ int) error {
+		found <- probeSixel(tty, 150*time.Millisecond)
+		return nil
+	})
+	p.WaitForOutput("DA1 query", "\x1b[c")
+	p.Type("\x1b[?62;4;22c")
+	if err := p.Wait(); err != nil {
+		t.Fatalf("probe: %v", err)
+	}
+	if !<-found {
+		t.Fatalf("sixel reply not detected")
+	}
+	if text := p.Screen().Text(); strings.Contains(text, "62;4") {
+		t.Fatalf("reply was echoed: %q", text)
+	}
+}
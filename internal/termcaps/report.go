@@ -0,0 +1,207 @@
+package termcaps
+
+import (
+	"os"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Report is everything gifgrep knows about the terminal, for termcaps-check.
+type Report struct {
+	Detected        string `json:"detected"`
+	Multiplexer     string `json:"multiplexer"`
+	TmuxPassthrough string `json:"tmux_passthrough,omitempty"`
+	KittyGraphics   string `json:"kitty_graphics"`
+	DA1             []int  `json:"da1,omitempty"`
+	DA2             []int  `json:"da2,omitempty"`
+	Sixel           bool   `json:"sixel"`
+	CellWidth       int    `json:"cell_width,omitempty"`
+	CellHeight      int    `json:"cell_height,omitempty"`
+	Truecolor       bool   `json:"truecolor"`
+	TermProgram     string `json:"term_program,omitempty"`
+	Term            string `json:"term,omitempty"`
+	ColorTerm       string `json:"colorterm,omitempty"`
+	LCTerminal      string `json:"lc_terminal,omitempty"`
+	ItermSession    string `json:"iterm_session_id,omitempty"`
+	KittyWindow     string `json:"kitty_window_id,omitempty"`
+}
+
+// Field is one report entry as a string, in display order.
+type Field struct {
+	Key   string
+	Value string
+}
+
+// Fields flattens the report for text output and --expect matching; DA
+// attributes are joined with ';' and the cell size is "WxH".
+func (r Report) Fields() []Field {
+	cell := ""
+	if r.CellWidth > 0 && r.CellHeight > 0 {
+		cell = strconv.Itoa(r.CellWidth) + "x" + strconv.Itoa(r.CellHeight)
+	}
+	return []Field{
+		{"detected", r.Detected},
+		{"multiplexer", r.Multiplexer},
+		{"tmux_passthrough", r.TmuxPassthrough},
+		{"kitty_graphics", r.KittyGraphics},
+		{"da1", joinInts(r.DA1)},
+		{"da2", joinInts(r.DA2)},
+		{"sixel", strconv.FormatBool(r.Sixel)},
+		{"cell", cell},
+		{"truecolor", strconv.FormatBool(r.Truecolor)},
+		{"term_program", r.TermProgram},
+		{"term", r.Term},
+		{"colorterm", r.ColorTerm},
+		{"lc_terminal", r.LCTerminal},
+		{"iterm_session_id", r.ItermSession},
+		{"kitty_window_id", r.KittyWindow},
+	}
+}
+
+func joinInts(v []int) string {
+	s := make([]string, len(v))
+	for i, n := range v {
+		s[i] = strconv.Itoa(n)
+	}
+	return strings.Join(s, ";")
+}
+
+type reportProbes struct {
+	kitty      func() kittyProbeResult
+	attrs      func() (da1, da2 []int)
+	cell       func() CellSize
+	clientTerm func() string
+	tmuxOption func(name string) string
+}
+
+// Inspect builds a Report. With probe set it queries /dev/tty (Kitty graphics
+// query, DA1/DA2, cell size); otherwise it only reads the environment.
+func Inspect(getenv func(string) string, probe bool) Report {
+	if getenv == nil {
+		getenv = os.Getenv
+	}
+	probes := reportProbes{clientTerm: tmuxClientTerm, tmuxOption: tmuxShowOption}
+	if probe {
+		if tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0); err == nil {
+			defer func() { _ = tty.Close() }()
+			defer rawMode(tty)()
+			probes.kitty = func() kittyProbeResult { return probeKittyGraphics(tty, 150*time.Millisecond) }
+			probes.attrs = func() ([]int, []int) { return probeDeviceAttributes(tty, 150*time.Millisecond) }
+			probes.cell = func() CellSize {
+				return cellSizeFor(sysFd(tty), func() CellSize { return probeCellSize(tty, 150*time.Millisecond) })
+			}
+		}
+	}
+	return inspect(getenv, probes)
+}
+
+func inspect(getenv func(string) string, p reportProbes) Report {
+	mux := DetectMultiplexer(getenv)
+	env := outerEnv(getenv, mux, p.clientTerm)
+
+	r := Report{
+		Multiplexer:   mux.String(),
+		KittyGraphics: kittyProbeUnknown.String(),
+		Truecolor:     DetectTruecolor(env),
+		TermProgram:   getenv("TERM_PROGRAM"),
+		Term:          getenv("TERM"),
+		ColorTerm:     getenv("COLORTERM"),
+		LCTerminal:    getenv("LC_TERMINAL"),
+		ItermSession:  getenv("ITERM_SESSION_ID"),
+		KittyWindow:   getenv("KITTY_WINDOW_ID"),
+	}
+	if mux == MuxTmux && p.tmuxOption != nil {
+		r.TmuxPassthrough = p.tmuxOption("allow-passthrough")
+	}
+
+	kitty := kittyProbeUnknown
+	// Multiplexers swallow graphics replies, so the query would only time out.
+	if mux == MuxNone && p.kitty != nil {
+		kitty = p.kitty()
+		r.KittyGraphics = kitty.String()
+	}
+	if p.attrs != nil {
+		r.DA1, r.DA2 = p.attrs()
+	}
+	if p.cell != nil {
+		c := p.cell()
+		r.CellWidth, r.CellHeight = c.Width, c.Height
+	}
+
+	detected := detectInlineRobust(env, func() kittyProbeResult { return kitty }, func() bool {
+		return slices.Contains(r.DA1, da1Sixel)
+	})
+	r.Detected = detected.String()
+	r.Sixel = detected == InlineSixel || slices.Contains(r.DA1, da1Sixel)
+	return r
+}
+
+func (k kittyProbeResult) String() string {
+	switch k {
+	case kittyProbeSupported:
+		return "supported"
+	case kittyProbeNotSupported:
+		return "unsupported"
+	case kittyProbeUnknown:
+		return "unknown"
+	default:
+		return "unknown"
+	}
+}
+
+// tmuxShowOption reads a global tmux option ("" when tmux isn't reachable).
+var tmuxShowOption = func(name string) string {
+	out, err := exec.Command("tmux", "show-options", "-gv", name).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// probeDeviceAttributes sends DA2 then DA1; terminals answer in order and
+// all of them answer DA1, so its reply ends the wait.
+func probeDeviceAttributes(tty *os.File, timeout time.Duration) (da1, da2 []int) {
+	if tty == nil {
+		return nil, nil
+	}
+	_, _ = tty.Write([]byte("\x1b[>c\x1b[c"))
+	acc := readUntilDA1(tty, timeout)
+	da1, _ = da1Attributes(acc)
+	da2, _ = da2Attributes(acc)
+	return da1, da2
+}
+
+// da2Attributes finds a secondary device attributes response
+// (ESC [ > Pp ; Pv ; Pc c) and returns its parameters.
+func da2Attributes(b []byte) ([]int, bool) {
+	for i := 0; i+3 < len(b); i++ {
+		if b[i] != 0x1b || b[i+1] != '[' || b[i+2] != '>' {
+			continue
+		}
+		var attrs []int
+		cur, digits := 0, 0
+		for j := i + 3; j < len(b) && j-i < 64; j++ {
+			ch := b[j]
+			if ch >= '0' && ch <= '9' {
+				cur = cur*10 + int(ch-'0')
+				digits++
+				continue
+			}
+			if ch == ';' || ch == 'c' {
+				if digits > 0 {
+					attrs = append(attrs, cur)
+				}
+				cur, digits = 0, 0
+				if ch == 'c' {
+					return attrs, true
+				}
+				continue
+			}
+			break
+		}
+	}
+	return nil, false
+}
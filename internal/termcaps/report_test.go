@@ -0,0 +1,71 @@
+package termcaps
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestInspectProbesAndFields(t *testing.T) {
+	env := map[string]string{"TERM": "xterm-256color", "COLORTERM": "truecolor"}
+	r := inspect(func(k string) string { return env[k] }, reportProbes{
+		kitty: func() kittyProbeResult { return kittyProbeNotSupported },
+		attrs: func() ([]int, []int) { return []int{62, 4, 22}, []int{41, 390, 0} },
+		cell:  func() CellSize { return CellSize{Width: 9, Height: 18} },
+	})
+	if r.Detected != "sixel" || !r.Sixel || !r.Truecolor || r.KittyGraphics != "unsupported" {
+		t.Fatalf("unexpected report %+v", r)
+	}
+	got := map[string]string{}
+	for _, f := range r.Fields() {
+		got[f.Key] = f.Value
+	}
+	if got["da1"] != "62;4;22" || got["da2"] != "41;390;0" || got["cell"] != "9x18" || got["multiplexer"] != "none" {
+		t.Fatalf("unexpected fields %v", got)
+	}
+}
+
+func TestInspectInsideTmux(t *testing.T) {
+	env := map[string]string{"TMUX": "/tmp/tmux-1/default,1,0", "TERM": "tmux-256color", "KITTY_WINDOW_ID": "3"}
+	kittyCalled := false
+	r := inspect(func(k string) string { return env[k] }, reportProbes{
+		kitty:      func() kittyProbeResult { kittyCalled = true; return kittyProbeNotSupported },
+		clientTerm: func() string { return "xterm-kitty" },
+		tmuxOption: func(name string) string {
+			if name != "allow-passthrough" {
+				t.Fatalf("unexpected option %q", name)
+			}
+			return "on"
+		},
+	})
+	if kittyCalled {
+		t.Fatalf("kitty query must be skipped inside tmux")
+	}
+	if r.Detected != "kitty" || r.Multiplexer != "tmux" || r.TmuxPassthrough != "on" || r.KittyGraphics != "unknown" {
+		t.Fatalf("unexpected report %+v", r)
+	}
+}
+
+func TestInspectWithoutProbes(t *testing.T) {
+	r := inspect(func(string) string { return "" }, reportProbes{})
+	if r.Detected != "none" || r.Sixel || r.CellWidth != 0 || len(r.DA1) != 0 {
+		t.Fatalf("unexpected env-only report %+v", r)
+	}
+	for _, f := range r.Fields() {
+		if f.Key == "cell" && f.Value != "" {
+			t.Fatalf("expected empty cell field")
+		}
+	}
+}
+
+func TestDA2Attributes(t *testing.T) {
+	attrs, ok := da2Attributes([]byte("junk\x1b[>1;4000;15c\x1b[?62;c"))
+	if !ok || len(attrs) != 3 || attrs[1] != 4000 {
+		t.Fatalf("unexpected DA2 %v %v", attrs, ok)
+	}
+	if _, ok := da2Attributes([]byte("\x1b[?62;4c")); ok {
+		t.Fatalf("DA1 must not parse as DA2")
+	}
+	if da1, ok := da1Attributes([]byte("\x1b[>1;2;3c\x1b[?62;4c")); !ok || !strings.HasPrefix(joinInts(da1), "62") {
+		t.Fatalf("DA1 parse skipped past DA2 wrongly: %v", da1)
+	}
+}
@@ -0,0 +1,22 @@
+package termcaps
+
+import (
+	"os"
+	"slices"
+	"time"
+)
+
+// da1Sixel is the DA1 attribute terminals use to advertise sixel graphics.
+const da1Sixel = 4
+
+// probeSixel asks for primary device attributes (DA1) and reports whether the
+// terminal lists sixel support.
+func probeSixel(tty *os.File, timeout time.Duration) bool {
+	if tty == nil {
+		return false
+	}
+	defer rawMode(tty)()
+	_, _ = tty.Write([]byte("\x1b[c"))
+	attrs, ok := da1Attributes(readUntilDA1(tty, timeout))
+	return ok && slices.Contains(attrs, da1Sixel)
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package termcaps
+
+// winsize has no pixel fields to read outside unix; callers fall back to the
+// escape-sequence probe.
+func winsize(_ int) (cols, rows, xpix, ypix int, ok bool) {
+	return 0, 0, 0, 0, false
+}
@@ -0,0 +1,13 @@
+//go:build unix
+
+package termcaps
+
+import "golang.org/x/sys/unix"
+
+func winsize(fd int) (cols, rows, xpix, ypix int, ok bool) {
+	ws, err := unix.IoctlGetWinsize(fd, unix.TIOCGWINSZ)
+	if err != nil {
+		return 0, 0, 0, 0, false
+	}
+	return int(ws.Col), int(ws.Row), int(ws.Xpixel), int(ws.Ypixel), true
+}
@@ -0,0 +1,162 @@
+//go:build linux
+
+package testutil
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+// PTY runs code against the slave side of a pseudo-terminal and mirrors
+// everything it writes into a Screen.
+type PTY struct {
+	t       *testing.T
+	master  *os.File
+	slave   *os.File
+	ctl     *os.File
+	screen  *Screen
+	done    chan error
+	drained chan struct{}
+	err     error
+	exited  bool
+
+	outMu sync.Mutex
+	out   []byte // everything written, escapes included
+}
+
+// WaitTimeout bounds WaitFor and Wait.
+var WaitTimeout = 5 * time.Second
+
+// StartPTY opens a rows×cols pseudo-terminal and runs fn on a new goroutine
+// with its slave side: tty for input and output, fd for terminal ioctls
+// (raw mode, size).
+func StartPTY(t *testing.T, rows, cols int, fn func(tty *os.File, fd int) error) *PTY {
+	t.Helper()
+	master, slave, ctl, err := openPTY()
+	if err != nil {
+		t.Skipf("no pty available: %v", err)
+	}
+	if err := setWinsize(master, rows, cols); err != nil {
+		t.Fatalf("set pty size: %v", err)
+	}
+	p := &PTY{
+		t:       t,
+		master:  master,
+		slave:   slave,
+		ctl:     ctl,
+		screen:  NewScreen(rows, cols),
+		done:    make(chan error, 1),
+		drained: make(chan struct{}),
+	}
+	go func() {
+		defer close(p.drained)
+		buf := make([]byte, 32<<10)
+		for {
+			n, err := master.Read(buf)
+			if n > 0 {
+				_, _ = p.screen.Write(buf[:n])
+				p.outMu.Lock()
+				p.out = append(p.out, buf[:n]...)
+				p.outMu.Unlock()
+			}
+			if err != nil {
+				// EIO once the slave side is closed.
+				return
+			}
+		}
+	}()
+	fd := int(ctl.Fd())
+	go func() { p.done <- fn(slave, fd) }()
+	t.Cleanup(func() {
+		_ = p.slave.Close()
+		_ = p.ctl.Close()
+		_ = p.master.Close()
+	})
+	return p
+}
+
+func (p *PTY) Screen() *Screen {
+	return p.screen
+}
+
+// Type sends keystrokes as if typed at the terminal.
+func (p *PTY) Type(keys string) {
+	p.t.Helper()
+	if _, err := p.master.Write([]byte(keys)); err != nil {
+		p.t.Fatalf("write to pty: %v", err)
+	}
+}
+
+// Resize changes the terminal size; the program sees it on its next size poll.
+func (p *PTY) Resize(rows, cols int) {
+	p.t.Helper()
+	if err := setWinsize(p.master, rows, cols); err != nil {
+		p.t.Fatalf("resize pty: %v", err)
+	}
+	p.screen.Resize(rows, cols)
+}
+
+// WaitFor polls until cond holds, failing the test with a screen dump after
+// WaitTimeout.
+func (p *PTY) WaitFor(what string, cond func(*Screen) bool) {
+	p.t.Helper()
+	deadline := time.Now().Add(WaitTimeout)
+	for !cond(p.screen) {
+		if time.Now().After(deadline) {
+			p.t.Fatalf("timed out waiting for %s; screen:\n%s", what, p.screen.Text())
+		}
+		select {
+		case err := <-p.done:
+			p.finish(err)
+			if !cond(p.screen) {
+				p.t.Fatalf("program exited (err=%v) before %s; screen:\n%s", err, what, p.screen.Text())
+			}
+			return
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+// WaitForOutput waits until the program has written seq, such as a terminal
+// query the screen does not show.
+func (p *PTY) WaitForOutput(what string, seq string) {
+	p.t.Helper()
+	p.WaitFor(what, func(*Screen) bool {
+		p.outMu.Lock()
+		defer p.outMu.Unlock()
+		return bytes.Contains(p.out, []byte(seq))
+	})
+}
+
+// Wait waits for fn to return and for its output to reach the screen.
+func (p *PTY) Wait() error {
+	p.t.Helper()
+	if p.exited {
+		return p.err
+	}
+	select {
+	case err := <-p.done:
+		p.finish(err)
+	case <-time.After(WaitTimeout):
+		p.t.Fatalf("program did not exit; screen:\n%s", p.screen.Text())
+	}
+	return p.err
+}
+
+func (p *PTY) finish(err error) {
+	p.exited = true
+	p.err = err
+	// Closing the slave makes the master read end once buffered output is
+	// drained.
+	_ = p.slave.Close()
+	_ = p.ctl.Close()
+	select {
+	case <-p.drained:
+	case <-time.After(WaitTimeout):
+		p.err = errors.Join(err, errors.New("pty output not drained"))
+	}
+}
@@ -0,0 +1,27 @@
+//go:build linux
+
+package testutil
+
+import (
+	"os"
+	"testing"
+)
+
+func TestPTYRunsAndDrains(t *testing.T) {
+	p := StartPTY(t, 5, 20, func(tty *os.File, _ int) error {
+		buf := make([]byte, 3)
+		if _, err := tty.Read(buf); err != nil {
+			return err
+		}
+		_, err := tty.WriteString("got " + string(buf))
+		return err
+	})
+	// Cooked mode: the line is echoed and delivered on Enter.
+	p.Type("abc\n")
+	if err := p.Wait(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.Screen().Line(0) != "abc" || p.Screen().Line(1) != "got abc" {
+		t.Fatalf("unexpected screen %q", p.Screen().Text())
+	}
+}
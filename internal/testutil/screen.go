@@ -0,0 +1,488 @@
+package testutil
+
+import (
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// KittyCommand is one Kitty graphics escape (ESC _ G ... ESC \), recorded
+// with the cursor position (0-based) it arrived at.
+type KittyCommand struct {
+	Params  map[string]string
+	Payload string
+	Row     int
+	Col     int
+}
+
+// KittyImage is a live Kitty placement: where a=T/a=p put image ID and how
+// many cells it spans.
+type KittyImage struct {
+	ID      uint32
+	Row     int
+	Col     int
+	Cols    int
+	Rows    int
+	Virtual bool
+}
+
+// ItermImage is one OSC 1337 file transfer (single or multipart), with the
+// cursor position it was drawn at and its decoded bytes.
+type ItermImage struct {
+	Args map[string]string
+	Data []byte
+	Row  int
+	Col  int
+}
+
+// Screen is a small virtual terminal: enough of VT100 for gifgrep's output
+// (cursor movement, erase, save/restore) plus a record of image escapes.
+// It is safe for one writer and concurrent readers.
+type Screen struct {
+	mu sync.Mutex
+
+	rows, cols int
+	cells      [][]rune
+	row, col   int
+	savedRow   int
+	savedCol   int
+	hidden     bool
+
+	kitty  []KittyCommand
+	images map[uint32]KittyImage
+	iterm  []ItermImage
+	// multipart collects an in-flight MultipartFile transfer.
+	multipart *ItermImage
+	sixels    int
+
+	state   parseState
+	seq     []byte
+	pending []byte
+}
+
+type parseState int
+
+const (
+	stateGround parseState = iota
+	stateEsc
+	stateCSI
+	// stateString covers OSC, APC and DCS bodies; seq[0] holds the introducer.
+	stateString
+	stateStringEsc
+)
+
+func NewScreen(rows, cols int) *Screen {
+	s := &Screen{rows: rows, cols: cols, images: map[uint32]KittyImage{}}
+	s.cells = make([][]rune, rows)
+	for i := range s.cells {
+		s.cells[i] = blankRow(cols)
+	}
+	return s
+}
+
+func blankRow(cols int) []rune {
+	r := make([]rune, cols)
+	for i := range r {
+		r[i] = ' '
+	}
+	return r
+}
+
+func (s *Screen) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, b := range p {
+		s.feed(b)
+	}
+	return len(p), nil
+}
+
+func (s *Screen) feed(b byte) {
+	switch s.state {
+	case stateGround:
+		s.ground(b)
+	case stateEsc:
+		s.escape(b)
+	case stateCSI:
+		s.seq = append(s.seq, b)
+		if b >= 0x40 && b <= 0x7e {
+			s.csi(s.seq)
+			s.state = stateGround
+		}
+	case stateString:
+		if b == 0x1b {
+			s.state = stateStringEsc
+			return
+		}
+		if b == 0x07 && s.seq[0] == ']' {
+			s.str(s.seq)
+			s.state = stateGround
+			return
+		}
+		s.seq = append(s.seq, b)
+	case stateStringEsc:
+		if b == '\\' {
+			s.str(s.seq)
+			s.state = stateGround
+			return
+		}
+		s.seq = append(s.seq, 0x1b, b)
+		s.state = stateString
+	}
+}
+
+func (s *Screen) ground(b byte) {
+	if len(s.pending) == 0 {
+		switch b {
+		case 0x1b:
+			s.state = stateEsc
+			return
+		case '\r':
+			s.col = 0
+			return
+		case '\n':
+			s.lineFeed()
+			return
+		case '\b':
+			s.col = max(0, s.col-1)
+			return
+		}
+		if b < 0x20 || b == 0x7f {
+			return
+		}
+	}
+	s.pending = append(s.pending, b)
+	if !utf8.FullRune(s.pending) {
+		return
+	}
+	r, _ := utf8.DecodeRune(s.pending)
+	s.pending = s.pending[:0]
+	s.put(r)
+}
+
+func (s *Screen) put(r rune) {
+	w := runewidth.RuneWidth(r)
+	if w == 0 {
+		// Combining marks (e.g. Kitty placeholder diacritics) stay with the
+		// previous cell.
+		return
+	}
+	// col == cols is the pending-wrap state after filling the last column;
+	// the wrap happens only when another character follows.
+	if s.col+w > s.cols {
+		s.col = 0
+		s.lineFeed()
+	}
+	s.cells[s.row][s.col] = r
+	if w == 2 && s.col+1 < s.cols {
+		s.cells[s.row][s.col+1] = 0
+	}
+	s.col += w
+}
+
+func (s *Screen) lineFeed() {
+	if s.row < s.rows-1 {
+		s.row++
+		return
+	}
+	copy(s.cells, s.cells[1:])
+	s.cells[s.rows-1] = blankRow(s.cols)
+}
+
+func (s *Screen) escape(b byte) {
+	s.state = stateGround
+	switch b {
+	case '[':
+		s.seq = s.seq[:0]
+		s.state = stateCSI
+	case ']', '_', 'P':
+		s.seq = append(s.seq[:0], b)
+		s.state = stateString
+	case '7':
+		s.savedRow, s.savedCol = s.row, min(s.col, s.cols-1)
+	case '8':
+		s.row, s.col = s.savedRow, s.savedCol
+	}
+}
+
+func (s *Screen) csi(seq []byte) {
+	final := seq[len(seq)-1]
+	body := string(seq[:len(seq)-1])
+	private := strings.HasPrefix(body, "?") || strings.HasPrefix(body, ">")
+	if private {
+		if body == "?25" {
+			s.hidden = final == 'l'
+		}
+		return
+	}
+	s.col = min(s.col, s.cols-1)
+	params := csiParams(body)
+	arg := func(i, def int) int {
+		if i < len(params) && params[i] > 0 {
+			return params[i]
+		}
+		return def
+	}
+	switch final {
+	case 'H', 'f':
+		s.row = clamp(arg(0, 1)-1, 0, s.rows-1)
+		s.col = clamp(arg(1, 1)-1, 0, s.cols-1)
+	case 'A':
+		s.row = clamp(s.row-arg(0, 1), 0, s.rows-1)
+	case 'B':
+		s.row = clamp(s.row+arg(0, 1), 0, s.rows-1)
+	case 'C':
+		s.col = clamp(s.col+arg(0, 1), 0, s.cols-1)
+	case 'D':
+		s.col = clamp(s.col-arg(0, 1), 0, s.cols-1)
+	case 'G':
+		s.col = clamp(arg(0, 1)-1, 0, s.cols-1)
+	case 'J':
+		s.eraseDisplay(arg(0, 0))
+	case 'K':
+		s.eraseLine(arg(0, 0))
+	case 's':
+		s.savedRow, s.savedCol = s.row, s.col
+	case 'u':
+		s.row, s.col = s.savedRow, s.savedCol
+	}
+}
+
+func csiParams(body string) []int {
+	if body == "" {
+		return nil
+	}
+	parts := strings.Split(body, ";")
+	out := make([]int, len(parts))
+	for i, p := range parts {
+		out[i], _ = strconv.Atoi(p)
+	}
+	return out
+}
+
+func (s *Screen) eraseDisplay(mode int) {
+	switch mode {
+	case 0:
+		s.eraseLine(0)
+		for r := s.row + 1; r < s.rows; r++ {
+			s.cells[r] = blankRow(s.cols)
+		}
+	case 1:
+		s.eraseLine(1)
+		for r := 0; r < s.row; r++ {
+			s.cells[r] = blankRow(s.cols)
+		}
+	default:
+		for r := range s.cells {
+			s.cells[r] = blankRow(s.cols)
+		}
+	}
+}
+
+func (s *Screen) eraseLine(mode int) {
+	from, to := s.col, s.cols
+	switch mode {
+	case 1:
+		from, to = 0, s.col+1
+	case 2:
+		from = 0
+	}
+	for c := from; c < to; c++ {
+		s.cells[s.row][c] = ' '
+	}
+}
+
+// str handles a completed OSC, APC or DCS body.
+func (s *Screen) str(seq []byte) {
+	body := string(seq[1:])
+	switch seq[0] {
+	case '_':
+		if strings.HasPrefix(body, "G") {
+			s.kittyCommand(body[1:])
+		}
+	case ']':
+		s.osc(body)
+	case 'P':
+		if i := strings.IndexByte(body, 'q'); i >= 0 && !strings.ContainsAny(body[:i], "+$") {
+			s.sixels++
+		}
+	}
+}
+
+func (s *Screen) kittyCommand(body string) {
+	control, payload, _ := strings.Cut(body, ";")
+	params := map[string]string{}
+	for _, kv := range strings.Split(control, ",") {
+		if k, v, ok := strings.Cut(kv, "="); ok {
+			params[k] = v
+		}
+	}
+	s.kitty = append(s.kitty, KittyCommand{Params: params, Payload: payload, Row: s.row, Col: s.col})
+
+	id := parseUint32(params["i"])
+	switch params["a"] {
+	case "T", "p":
+		s.images[id] = KittyImage{
+			ID:      id,
+			Row:     s.row,
+			Col:     s.col,
+			Cols:    atoi(params["c"]),
+			Rows:    atoi(params["r"]),
+			Virtual: params["U"] == "1",
+		}
+	case "d":
+		switch params["d"] {
+		case "I", "i":
+			delete(s.images, id)
+		case "", "a", "A":
+			clear(s.images)
+		}
+	}
+}
+
+func (s *Screen) osc(body string) {
+	rest, ok := strings.CutPrefix(body, "1337;")
+	if !ok {
+		return
+	}
+	switch {
+	case strings.HasPrefix(rest, "File="):
+		args, data, _ := strings.Cut(strings.TrimPrefix(rest, "File="), ":")
+		img := ItermImage{Args: parseArgs(args), Row: s.row, Col: s.col}
+		img.Data, _ = base64.StdEncoding.DecodeString(data)
+		s.iterm = append(s.iterm, img)
+	case strings.HasPrefix(rest, "MultipartFile="):
+		s.multipart = &ItermImage{Args: parseArgs(strings.TrimPrefix(rest, "MultipartFile=")), Row: s.row, Col: s.col}
+	case strings.HasPrefix(rest, "FilePart="):
+		if s.multipart != nil {
+			part, _ := base64.StdEncoding.DecodeString(strings.TrimPrefix(rest, "FilePart="))
+			s.multipart.Data = append(s.multipart.Data, part...)
+		}
+	case rest == "FileEnd":
+		if s.multipart != nil {
+			s.iterm = append(s.iterm, *s.multipart)
+			s.multipart = nil
+		}
+	}
+}
+
+func parseArgs(s string) map[string]string {
+	args := map[string]string{}
+	for _, kv := range strings.Split(s, ";") {
+		if k, v, ok := strings.Cut(kv, "="); ok {
+			args[k] = v
+		}
+	}
+	return args
+}
+
+// Line returns row r (0-based) with trailing spaces trimmed.
+func (s *Screen) Line(r int) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r < 0 || r >= s.rows {
+		return ""
+	}
+	return s.line(r)
+}
+
+func (s *Screen) line(r int) string {
+	var b strings.Builder
+	for _, c := range s.cells[r] {
+		if c != 0 {
+			b.WriteRune(c)
+		}
+	}
+	return strings.TrimRight(b.String(), " ")
+}
+
+// Text returns all rows joined with newlines.
+func (s *Screen) Text() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	lines := make([]string, s.rows)
+	for r := range lines {
+		lines[r] = s.line(r)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (s *Screen) Contains(text string) bool {
+	return strings.Contains(s.Text(), text)
+}
+
+// Cursor returns the 0-based cursor position.
+func (s *Screen) Cursor() (row, col int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.row, min(s.col, s.cols-1)
+}
+
+func (s *Screen) CursorVisible() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return !s.hidden
+}
+
+// Resize changes the grid size, keeping the top-left content.
+func (s *Screen) Resize(rows, cols int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cells := make([][]rune, rows)
+	for r := range cells {
+		cells[r] = blankRow(cols)
+		if r < s.rows {
+			copy(cells[r], s.cells[r])
+		}
+	}
+	s.rows, s.cols, s.cells = rows, cols, cells
+	s.row = clamp(s.row, 0, rows-1)
+	s.col = clamp(s.col, 0, cols-1)
+}
+
+func (s *Screen) KittyCommands() []KittyCommand {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]KittyCommand(nil), s.kitty...)
+}
+
+// KittyImages returns the live placements, i.e. not yet deleted.
+func (s *Screen) KittyImages() []KittyImage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]KittyImage, 0, len(s.images))
+	for _, img := range s.images {
+		out = append(out, img)
+	}
+	return out
+}
+
+func (s *Screen) ItermImages() []ItermImage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]ItermImage(nil), s.iterm...)
+}
+
+func (s *Screen) Sixels() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sixels
+}
+
+func clamp(v, lo, hi int) int {
+	return max(lo, min(hi, v))
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+func parseUint32(s string) uint32 {
+	n, _ := strconv.ParseUint(s, 10, 32)
+	return uint32(n)
+}
@@ -0,0 +1,87 @@
+package testutil
+
+import (
+	"testing"
+)
+
+func TestScreenTextAndCursor(t *testing.T) {
+	s := NewScreen(4, 10)
+	_, _ = s.Write([]byte("hello\r\nworld"))
+	_, _ = s.Write([]byte("\x1b[1;3H\x1b[K"))
+	if s.Line(0) != "he" || s.Line(1) != "world" {
+		t.Fatalf("unexpected text %q", s.Text())
+	}
+	_, _ = s.Write([]byte("\x1b7\x1b[4;8Hxy\x1b8Z"))
+	if s.Line(3) != "       xy" || s.Line(0) != "heZ" {
+		t.Fatalf("save/restore failed: %q", s.Text())
+	}
+	_, _ = s.Write([]byte("\x1b[2J\x1b[H\x1b[?25l"))
+	if s.Text() != "\n\n\n" || s.CursorVisible() {
+		t.Fatalf("expected cleared screen with hidden cursor")
+	}
+}
+
+func TestScreenWrapsAndScrolls(t *testing.T) {
+	s := NewScreen(2, 3)
+	_, _ = s.Write([]byte("abc"))
+	if r, c := s.Cursor(); r != 0 || c != 2 {
+		t.Fatalf("expected pending wrap at last column, got %d,%d", r, c)
+	}
+	_, _ = s.Write([]byte("def"))
+	_, _ = s.Write([]byte("g"))
+	if s.Line(0) != "def" || s.Line(1) != "g" {
+		t.Fatalf("unexpected scroll %q", s.Text())
+	}
+}
+
+func TestScreenSplitSequences(t *testing.T) {
+	s := NewScreen(2, 10)
+	for _, b := range []byte("\x1b[2;4Hé\x1b_Ga=T,i=3,c=2,r=1;AAAA\x1b\\") {
+		_, _ = s.Write([]byte{b})
+	}
+	if s.Line(1) != "   é" {
+		t.Fatalf("unexpected text %q", s.Text())
+	}
+	imgs := s.KittyImages()
+	if len(imgs) != 1 || imgs[0].ID != 3 || imgs[0].Row != 1 || imgs[0].Col != 4 || imgs[0].Cols != 2 {
+		t.Fatalf("unexpected kitty images %+v", imgs)
+	}
+}
+
+func TestScreenKittyDelete(t *testing.T) {
+	s := NewScreen(2, 10)
+	_, _ = s.Write([]byte("\x1b_Ga=T,i=1,c=2,r=1;AAAA\x1b\\\x1b_Ga=p,i=2,p=1,c=1,r=1,U=1\x1b\\"))
+	_, _ = s.Write([]byte("\x1b_Ga=d,d=I,i=1,q=2\x1b\\"))
+	imgs := s.KittyImages()
+	if len(imgs) != 1 || imgs[0].ID != 2 || !imgs[0].Virtual {
+		t.Fatalf("expected only the virtual image left: %+v", imgs)
+	}
+	_, _ = s.Write([]byte("\x1b_Ga=d\x1b\\"))
+	if len(s.KittyImages()) != 0 || len(s.KittyCommands()) != 4 {
+		t.Fatalf("expected all images deleted")
+	}
+}
+
+func TestScreenItermImages(t *testing.T) {
+	s := NewScreen(3, 10)
+	_, _ = s.Write([]byte("\x1b[2;2H\x1b]1337;File=inline=1;width=4:AQID\x07"))
+	_, _ = s.Write([]byte("\x1b]1337;MultipartFile=inline=1;size=4\x1b\\\x1b]1337;FilePart=AQID\x1b\\\x1b]1337;FilePart=BA==\x1b\\\x1b]1337;FileEnd\x1b\\"))
+	imgs := s.ItermImages()
+	if len(imgs) != 2 {
+		t.Fatalf("expected 2 images, got %d", len(imgs))
+	}
+	if imgs[0].Row != 1 || imgs[0].Col != 1 || imgs[0].Args["width"] != "4" || string(imgs[0].Data) != "\x01\x02\x03" {
+		t.Fatalf("unexpected single-part image %+v", imgs[0])
+	}
+	if string(imgs[1].Data) != "\x01\x02\x03\x04" || imgs[1].Args["size"] != "4" {
+		t.Fatalf("unexpected multipart image %+v", imgs[1])
+	}
+}
+
+func TestScreenCountsSixel(t *testing.T) {
+	s := NewScreen(2, 10)
+	_, _ = s.Write([]byte("\x1bP0;1;0q\"1;1;2;2#0!2~-\x1b\\"))
+	if s.Sixels() != 1 || s.Text() != "\n" {
+		t.Fatalf("expected one sixel image and no text")
+	}
+}
@@ -19,14 +19,14 @@ type FakeTransport struct {
 func (t *FakeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 	switch req.URL.Host {
 	case "api.tenor.com":
-		body := `{"results":[{"id":"1","title":"Cat One","content_description":"","tags":["cat","fun"],"media":[{"gif":{"url":"https://example.test/full.gif","dims":[200,100]},"tinygif":{"url":"https://example.test/preview.gif","dims":[50,25]}}]}]}`
+		body := `{"results":[{"id":"1","title":"Cat One","content_description":"","itemurl":"https://tenor.com/view/cat-one-1","tags":["cat","fun"],"media":[{"gif":{"url":"https://example.test/full.gif","dims":[200,100]},"tinygif":{"url":"https://example.test/preview.gif","dims":[50,25]}}]}]}`
 		return &http.Response{
 			StatusCode: http.StatusOK,
 			Header:     http.Header{"Content-Type": []string{"application/json"}},
 			Body:       io.NopCloser(strings.NewReader(body)),
 		}, nil
 	case "api.giphy.com":
-		body := `{"data":[{"id":"g1","title":"Cat One","images":{"original":{"url":"https://example.test/full.gif","width":"200","height":"100"},"fixed_width_small":{"url":"https://example.test/preview.gif","width":"50","height":"25"}}}]}`
+		body := `{"data":[{"id":"g1","title":"Cat One","url":"https://giphy.com/gifs/cat-one-g1","images":{"original":{"url":"https://example.test/full.gif","width":"200","height":"100"},"fixed_width_small":{"url":"https://example.test/preview.gif","width":"50","height":"25"}}}]}`
 		return &http.Response{
 			StatusCode: http.StatusOK,
 			Header:     http.Header{"Content-Type": []string{"application/json"}},
@@ -108,3 +108,29 @@ func MakeTestGIF() []byte {
 	_ = gif.EncodeAll(&buf, g)
 	return buf.Bytes()
 }
+
+// MakeTestGIFFrames returns a 2x2 GIF with n frames of 20ms each.
+func MakeTestGIFFrames(n int) []byte {
+	delays := make([]int, n)
+	for i := range delays {
+		delays[i] = 2
+	}
+	return MakeTestGIFDelays(delays...)
+}
+
+// MakeTestGIFDelays returns a 2x2 GIF with one frame per delay, given in
+// centiseconds as stored in the file.
+func MakeTestGIFDelays(delays ...int) []byte {
+	pal := color.Palette{color.Black, color.White}
+	g := &gif.GIF{Config: image.Config{Width: 2, Height: 2, ColorModel: pal}}
+	for i, d := range delays {
+		frame := image.NewPaletted(image.Rect(0, 0, 2, 2), pal)
+		frame.SetColorIndex(i%2, (i/2)%2, 1)
+		g.Image = append(g.Image, frame)
+		g.Delay = append(g.Delay, d)
+		g.Disposal = append(g.Disposal, gif.DisposalNone)
+	}
+	var buf bytes.Buffer
+	_ = gif.EncodeAll(&buf, g)
+	return buf.Bytes()
+}
@@ -0,0 +1,65 @@
+package tui
+
+import (
+	"bufio"
+
+	"github.com/steipete/gifgrep/internal/clipboard"
+)
+
+var (
+	copyTextFn = clipboard.CopyText
+	copyFileFn = clipboard.CopyFile
+)
+
+// copySelected copies the selected result's URL, or with gif its full GIF
+// (the saved or prefetched file, else a fresh fetch into the temp dir).
+func copySelected(state *appState, out *bufio.Writer, gif bool) {
+	defer func() { state.renderDirty = true }()
+	if state.selected < 0 || state.selected >= len(state.results) {
+		flashHeader(state, "No selection")
+		return
+	}
+	item := state.results[state.selected]
+	if item.URL == "" {
+		flashHeader(state, "No URL")
+		return
+	}
+	if !gif {
+		err := copyTextFn(out, item.URL)
+		_ = out.Flush()
+		if err != nil {
+			flashHeader(state, "Copy error: "+err.Error())
+			return
+		}
+		flashHeader(state, "Copied URL")
+		return
+	}
+
+	path, ok := savedPathForResult(state, item)
+	if !ok {
+		path, ok = tempPathForResult(state, item)
+	}
+	if !ok {
+		flashHeader(state, "Copying…")
+		render(state, out, state.lastRows, state.lastCols)
+		_ = out.Flush()
+
+		dir, err := ensureTempDir(state)
+		if err == nil {
+			path, err = prefetchGIFToTemp(item.URL, dir, 0)
+		}
+		if err != nil {
+			flashHeader(state, "Copy error: "+err.Error())
+			return
+		}
+		if state.tempPaths == nil {
+			state.tempPaths = map[string]string{}
+		}
+		state.tempPaths[resultKey(item)] = path
+	}
+	if err := copyFileFn(path); err != nil {
+		flashHeader(state, "Copy error: "+err.Error())
+		return
+	}
+	flashHeader(state, "Copied GIF")
+}
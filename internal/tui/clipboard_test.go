@@ -0,0 +1,91 @@
+package tui
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/steipete/gifgrep/internal/model"
+)
+
+func TestCopyKeysCopyURLAndGIF(t *testing.T) {
+	prevText, prevFile := copyTextFn, copyFileFn
+	t.Cleanup(func() { copyTextFn, copyFileFn = prevText, prevFile })
+
+	var copiedText, copiedFile string
+	copyTextFn = func(w io.Writer, s string) error {
+		copiedText = s
+		_, err := io.WriteString(w, "<osc52>")
+		return err
+	}
+	copyFileFn = func(path string) error {
+		copiedFile = path
+		return nil
+	}
+
+	saved := filepath.Join(t.TempDir(), "cat.gif")
+	if err := os.WriteFile(saved, []byte("GIF89a"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	item := model.Result{ID: "1", URL: "https://example.test/cat.gif"}
+	state := &appState{results: []model.Result{item}, keys: keymap{}}
+	trackSavedPath(state, item, saved)
+
+	var term bytes.Buffer
+	out := bufio.NewWriter(&term)
+	handleBrowseInput(state, inputEvent{kind: keyRune, ch: 'y'}, out)
+	if copiedText != item.URL || term.String() != "<osc52>" || state.headerFlash != "Copied URL" {
+		t.Fatalf("y: text %q, term %q, flash %q", copiedText, term.String(), state.headerFlash)
+	}
+
+	handleBrowseInput(state, inputEvent{kind: keyRune, ch: 'Y'}, out)
+	if copiedFile != saved || state.headerFlash != "Copied GIF" {
+		t.Fatalf("Y: file %q, flash %q", copiedFile, state.headerFlash)
+	}
+	if state.mode != modeBrowse {
+		t.Fatalf("copy keys must not start a query")
+	}
+
+	copyFileFn = func(string) error { return errors.New("no clipboard tool") }
+	copySelected(state, out, true)
+	if state.headerFlash != "Copy error: no clipboard tool" {
+		t.Fatalf("flash %q", state.headerFlash)
+	}
+}
+
+func TestCopyGIFShowsStatusWhileFetching(t *testing.T) {
+	prevFile := copyFileFn
+	t.Cleanup(func() { copyFileFn = prevFile })
+
+	var term bytes.Buffer
+	out := bufio.NewWriter(&term)
+	var shownBeforeFetch bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		shownBeforeFetch = strings.Contains(term.String(), "Copying…")
+		_, _ = w.Write([]byte("GIF89a"))
+	}))
+	t.Cleanup(srv.Close)
+
+	var copiedFile string
+	copyFileFn = func(path string) error {
+		copiedFile = path
+		return nil
+	}
+	item := model.Result{ID: "1", URL: srv.URL + "/cat.gif"}
+	state := &appState{results: []model.Result{item}, keys: keymap{}, tempDir: t.TempDir(), lastRows: 20, lastCols: 80}
+
+	copySelected(state, out, true)
+	if !shownBeforeFetch {
+		t.Fatalf("Copying… was not rendered before the fetch: %q", term.String())
+	}
+	if copiedFile == "" || state.tempPaths[resultKey(item)] != copiedFile || state.headerFlash != "Copied GIF" {
+		t.Fatalf("file %q, flash %q", copiedFile, state.headerFlash)
+	}
+}
@@ -0,0 +1,157 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/steipete/gifgrep/internal/favorites"
+	"github.com/steipete/gifgrep/internal/model"
+	"github.com/steipete/gifgrep/internal/search"
+)
+
+var favoritesPathFn = favorites.DefaultPath
+
+func loadFavorites() (*favorites.Store, error) {
+	path, err := favoritesPathFn()
+	if err != nil {
+		return nil, err
+	}
+	return favorites.Load(path)
+}
+
+// starCollection is where s adds to: the collection on screen, else the one
+// from --collection.
+func starCollection(state *appState) string {
+	if state.viewing != "" {
+		return state.viewing
+	}
+	if state.opts.Collection != "" {
+		return state.opts.Collection
+	}
+	return favorites.DefaultCollection
+}
+
+// resultSource is the provider a result came from; collections mix them.
+func resultSource(state *appState, item model.Result) string {
+	if src, ok := state.sources[resultKey(item)]; ok {
+		return src
+	}
+	return search.ResolveSource(state.opts.Source)
+}
+
+// starSelected toggles the selection in the star collection. Removed entries
+// stay on screen so a second s puts them back.
+func starSelected(state *appState) {
+	defer func() { state.renderDirty = true }()
+	if state.selected < 0 || state.selected >= len(state.results) {
+		flashHeader(state, "No selection")
+		return
+	}
+	item := state.results[state.selected]
+	name := starCollection(state)
+	source := resultSource(state, item)
+	store, err := loadFavorites()
+	if err != nil {
+		flashHeader(state, "Star error: "+err.Error())
+		return
+	}
+	msg := "Removed from " + name
+	if !store.Remove(name, item, source) {
+		if _, err := store.Add(name, item, source); err != nil {
+			flashHeader(state, "Star error: "+err.Error())
+			return
+		}
+		msg = "Starred in " + name
+	}
+	if err := store.Save(); err != nil {
+		flashHeader(state, "Star error: "+err.Error())
+		return
+	}
+	flashHeader(state, msg)
+}
+
+// showNextCollection cycles through the collections, showing each as the
+// result list.
+func showNextCollection(state *appState, prefetchCh chan<- prefetchResult) {
+	store, err := loadFavorites()
+	if err != nil {
+		flashHeader(state, "Collections error: "+err.Error())
+		state.renderDirty = true
+		return
+	}
+	names := store.Names()
+	if len(names) == 0 {
+		flashHeader(state, fmt.Sprintf("No collections yet (%c stars a GIF)", state.keys.key(actionStar)))
+		state.renderDirty = true
+		return
+	}
+	next := names[0]
+	for i, name := range names {
+		if name == state.viewing {
+			next = names[(i+1)%len(names)]
+			break
+		}
+	}
+	showCollection(state, store, next, prefetchCh)
+}
+
+// collectionQuery reads a typed query: "@name" (or a bare "@") may open a
+// collection, and "@@" escapes a literal "@". It returns the text to search
+// for otherwise and whether to try a collection first.
+func collectionQuery(query string) (string, bool) {
+	trimmed := strings.TrimSpace(query)
+	if strings.HasPrefix(trimmed, "@@") {
+		return trimmed[1:], false
+	}
+	return query, strings.HasPrefix(trimmed, "@")
+}
+
+// openCollectionQuery handles an "@name" query. A bare "@" opens the first
+// collection. It reports false, so the caller searches for the query as
+// typed, when no collection has that name.
+func openCollectionQuery(state *appState, query string, prefetchCh chan<- prefetchResult) bool {
+	name := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(query), "@"))
+	store, err := loadFavorites()
+	if err != nil {
+		state.status = "Collections error: " + err.Error()
+		state.renderDirty = true
+		return true
+	}
+	names := store.Names()
+	if name == "" {
+		if len(names) == 0 {
+			state.status = "No collections yet"
+			state.renderDirty = true
+			return true
+		}
+		name = names[0]
+	}
+	if _, ok := store.Collections[name]; !ok {
+		return false
+	}
+	showCollection(state, store, name, prefetchCh)
+	return true
+}
+
+func showCollection(state *appState, store *favorites.Store, name string, prefetchCh chan<- prefetchResult) {
+	entries := store.Collections[name]
+	results := make([]model.Result, len(entries))
+	state.sources = make(map[string]string, len(entries))
+	for i, e := range entries {
+		results[i] = e.Result
+		if e.Source != "" {
+			state.sources[resultKey(e.Result)] = e.Source
+		}
+	}
+	state.viewing = name
+	state.query = "@" + name
+	state.mode = modeBrowse
+	state.results = results
+	state.selected = 0
+	state.scroll = 0
+	state.status = fmt.Sprintf("★ %s · %d GIFs", name, len(results))
+	loadSelectedImage(state)
+	resetPrefetch(state)
+	startPrefetch(state, results, prefetchCh)
+	state.renderDirty = true
+}
@@ -0,0 +1,138 @@
+package tui
+
+import (
+	"bufio"
+	"bytes"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/steipete/gifgrep/internal/favorites"
+	"github.com/steipete/gifgrep/internal/model"
+	"github.com/steipete/gifgrep/internal/testutil"
+)
+
+func stubFavorites(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "favorites.json")
+	prev := favoritesPathFn
+	favoritesPathFn = func() (string, error) { return path, nil }
+	t.Cleanup(func() { favoritesPathFn = prev })
+	return path
+}
+
+func TestStarKeyTogglesFavorite(t *testing.T) {
+	path := stubFavorites(t)
+	item := model.Result{ID: "1", Title: "Cat", URL: "https://x.test/1.gif"}
+	state := &appState{results: []model.Result{item}, keys: keymap{}, opts: model.Options{Source: "tenor", Collection: "reactions"}}
+	out := bufio.NewWriter(&bytes.Buffer{})
+
+	handleBrowseInput(state, inputEvent{kind: keyRune, ch: 's'}, out)
+	if state.headerFlash != "Starred in reactions" {
+		t.Fatalf("flash %q", state.headerFlash)
+	}
+	store, err := favorites.Load(path)
+	if err != nil || !store.Contains("reactions", item, "tenor") {
+		t.Fatalf("not stored: %+v %v", store, err)
+	}
+
+	handleBrowseInput(state, inputEvent{kind: keyRune, ch: 's'}, out)
+	if state.headerFlash != "Removed from reactions" {
+		t.Fatalf("flash %q", state.headerFlash)
+	}
+	if store, _ := favorites.Load(path); len(store.Names()) != 0 {
+		t.Fatalf("expected an empty store, got %v", store.Names())
+	}
+	if state.mode != modeBrowse {
+		t.Fatalf("s must not start a query")
+	}
+}
+
+func TestCollectionsKeyCyclesCollections(t *testing.T) {
+	path := stubFavorites(t)
+	store, _ := favorites.Load(path)
+	_, _ = store.Add("favorites", model.Result{ID: "1", Title: "Cat", URL: "https://x.test/1.gif"}, "giphy")
+	_, _ = store.Add("favorites", model.Result{ID: "2", Title: "Dog", URL: "https://x.test/2.gif"}, "tenor")
+	_, _ = store.Add("work", model.Result{ID: "3", Title: "Wave", URL: "https://x.test/3.gif"}, "tenor")
+	if err := store.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	state := &appState{mode: modeBrowse, keys: keymap{}, opts: model.Options{Source: "tenor"}}
+	out := bufio.NewWriter(&bytes.Buffer{})
+	handleInput(state, inputEvent{kind: keyRune, ch: 'c'}, out, nil)
+	if state.viewing != "favorites" || len(state.results) != 2 || state.status != "★ favorites · 2 GIFs" || state.query != "@favorites" {
+		t.Fatalf("first: viewing %q, %d results, status %q", state.viewing, len(state.results), state.status)
+	}
+	if got := resultSource(state, state.results[0]); got != "giphy" {
+		t.Fatalf("source %q", got)
+	}
+
+	handleInput(state, inputEvent{kind: keyRune, ch: 'c'}, out, nil)
+	if state.viewing != "work" || len(state.results) != 1 {
+		t.Fatalf("second: viewing %q", state.viewing)
+	}
+	// s inside a collection stars into (here: removes from) that collection.
+	starSelected(state)
+	if state.headerFlash != "Removed from work" {
+		t.Fatalf("flash %q", state.headerFlash)
+	}
+
+	handleInput(state, inputEvent{kind: keyRune, ch: 'c'}, out, nil)
+	if state.viewing != "favorites" {
+		t.Fatalf("should wrap to favorites, got %q", state.viewing)
+	}
+}
+
+// queryRecorder records the search terms sent to the providers.
+type queryRecorder struct {
+	testutil.FakeTransport
+	queries []string
+}
+
+func (r *queryRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	if q := req.URL.Query().Get("q"); q != "" {
+		r.queries = append(r.queries, q)
+	}
+	return r.FakeTransport.RoundTrip(req)
+}
+
+func TestCollectionQuery(t *testing.T) {
+	path := stubFavorites(t)
+	state := &appState{mode: modeQuery, keys: keymap{}, query: "@"}
+	out := bufio.NewWriter(&bytes.Buffer{})
+	handleQueryInput(state, inputEvent{kind: keyEnter}, out, nil)
+	if state.status != "No collections yet" || state.mode != modeQuery {
+		t.Fatalf("status %q, mode %v", state.status, state.mode)
+	}
+
+	store, _ := favorites.Load(path)
+	_, _ = store.Add("work", model.Result{ID: "3", URL: "https://x.test/3.gif"}, "tenor")
+	_ = store.Save()
+	state.query = "@work"
+	handleQueryInput(state, inputEvent{kind: keyEnter}, out, nil)
+	if state.viewing != "work" || state.mode != modeBrowse || len(state.results) != 1 {
+		t.Fatalf("viewing %q, mode %v", state.viewing, state.mode)
+	}
+}
+
+func TestCollectionQueryFallsBackToSearch(t *testing.T) {
+	path := stubFavorites(t)
+	store, _ := favorites.Load(path)
+	_, _ = store.Add("work", model.Result{ID: "3", URL: "https://x.test/3.gif"}, "tenor")
+	_ = store.Save()
+
+	rec := &queryRecorder{}
+	testutil.WithTransport(t, rec, func() {
+		for _, query := range []string{"@someone", "@@work"} {
+			state := &appState{mode: modeQuery, keys: keymap{}, query: query, opts: model.Options{Limit: 1, Source: "tenor"}}
+			handleQueryInput(state, inputEvent{kind: keyEnter}, bufio.NewWriter(&bytes.Buffer{}), nil)
+			if state.viewing != "" || state.status != "1 results" {
+				t.Fatalf("%s: viewing %q, status %q", query, state.viewing, state.status)
+			}
+		}
+	})
+	if len(rec.queries) != 2 || rec.queries[0] != "@someone" || rec.queries[1] != "@work" {
+		t.Fatalf("searched for %q", rec.queries)
+	}
+}
@@ -3,6 +3,8 @@ package tui
 import (
 	"bufio"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/steipete/gifgrep/internal/download"
 	"github.com/steipete/gifgrep/internal/model"
@@ -10,8 +12,8 @@ import (
 )
 
 var (
-	downloadToDownloadsFn = download.ToDownloads
-	revealFn              = reveal.Reveal
+	saveFn   = download.Save
+	revealFn = reveal.Reveal
 )
 
 func downloadSelected(state *appState, out *bufio.Writer, revealAfter bool) {
@@ -31,12 +33,13 @@ func downloadSelected(state *appState, out *bufio.Writer, revealAfter bool) {
 	render(state, out, state.lastRows, state.lastCols)
 	_ = out.Flush()
 
-	filePath, err := downloadToDownloadsFn(item)
+	saved, err := saveFn(item, download.OptionsFrom(state.opts, resultSource(state, item)))
 	if err != nil {
 		flashHeader(state, "Download error: "+err.Error())
 		state.renderDirty = true
 		return
 	}
+	filePath := saved.Path
 	state.lastSavedPath = filePath
 	trackSavedPath(state, item, filePath)
 	loadSelectedImage(state)
@@ -50,7 +53,14 @@ func downloadSelected(state *appState, out *bufio.Writer, revealAfter bool) {
 		state.renderDirty = true
 		return
 	}
-	flashHeader(state, "Saved")
+	switch {
+	case saved.Duplicate != "":
+		flashHeader(state, "Already saved at "+displayPath(saved.Duplicate))
+	case saved.Skipped:
+		flashHeader(state, "Already saved")
+	default:
+		flashHeader(state, "Saved")
+	}
 	state.renderDirty = true
 }
 
@@ -121,3 +131,15 @@ func resultKey(item model.Result) string {
 	}
 	return "unknown"
 }
+
+// displayPath shortens p for the header by replacing $HOME with ~.
+func displayPath(p string) string {
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" {
+		return p
+	}
+	if rel, err := filepath.Rel(home, p); err == nil && !strings.HasPrefix(rel, "..") {
+		return filepath.Join("~", rel)
+	}
+	return p
+}
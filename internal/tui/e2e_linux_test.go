@@ -0,0 +1,146 @@
+//go:build linux
+
+package tui
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/steipete/gifgrep/internal/model"
+	"github.com/steipete/gifgrep/internal/termcaps"
+	"github.com/steipete/gifgrep/internal/testutil"
+)
+
+// startE2E runs the TUI in a 24×100 pty against the stub Tenor transport.
+// The environment pins the protocol so nothing probes the real /dev/tty.
+func startE2E(t *testing.T, inline, query string) *testutil.PTY {
+	t.Helper()
+	for k, v := range map[string]string{
+		"GIFGREP_INLINE":             inline,
+		"KITTY_WINDOW_ID":            "1",
+		"TERM_PROGRAM":               "",
+		"TMUX":                       "",
+		"STY":                        "",
+		"GIFGREP_SOFTWARE_ANIM":      "0",
+		"GIFGREP_KITTY_MEDIUM":       "direct",
+		"GIFGREP_KITTY_PLACEHOLDERS": "0",
+		"GIFGREP_ITERM_MULTIPART":    "0",
+		"GIFGREP_CELL_ASPECT":        "",
+	} {
+		t.Setenv(k, v)
+	}
+	rt := &testutil.FakeTransport{GIFData: testutil.MakeTestGIF()}
+	var p *testutil.PTY
+	testutil.WithTransport(t, rt, func() {
+		p = testutil.StartPTY(t, 24, 100, func(tty *os.File, fd int) error {
+			return runWith(Env{
+				In:       tty,
+				Out:      tty,
+				FD:       fd,
+				CellSize: func() termcaps.CellSize { return termcaps.CellSize{Width: 10, Height: 20} },
+			}, model.Options{Source: "tenor"}, query)
+		})
+	})
+	return p
+}
+
+func TestE2EKittyPreviewAndCleanup(t *testing.T) {
+	p := startE2E(t, "kitty", "cats")
+	p.WaitFor("result list", func(s *testutil.Screen) bool {
+		return s.Contains("Cat One") && s.Contains("1 results")
+	})
+	p.WaitFor("kitty preview", func(s *testutil.Screen) bool {
+		return len(s.KittyImages()) == 1
+	})
+	s := p.Screen()
+	img := s.KittyImages()[0]
+	// Wide terminals put the preview on the left, the list beside it.
+	if img.Col != 0 || img.Cols <= 0 || img.Rows <= 0 || img.Cols > 100-28 || img.Row+img.Rows > 24 {
+		t.Fatalf("unexpected preview placement %+v", img)
+	}
+	if col := listColumn(s, "Cat One"); col <= img.Cols {
+		t.Fatalf("list at col %d overlaps the %d-col preview", col, img.Cols)
+	}
+	if s.CursorVisible() {
+		t.Fatalf("cursor should be hidden while running")
+	}
+
+	p.Type("q")
+	if err := p.Wait(); err != nil {
+		t.Fatalf("tui exited with %v", err)
+	}
+	if !s.CursorVisible() {
+		t.Fatalf("cursor not restored on exit")
+	}
+	if imgs := s.KittyImages(); len(imgs) != 0 {
+		t.Fatalf("images left on screen after exit: %+v", imgs)
+	}
+}
+
+func TestE2ETypedSearch(t *testing.T) {
+	p := startE2E(t, "kitty", "")
+	p.WaitFor("prompt", func(s *testutil.Screen) bool {
+		return s.Contains("Type a search and press Enter")
+	})
+	p.Type("dogs\r")
+	p.WaitFor("results", func(s *testutil.Screen) bool {
+		return s.Contains("Cat One") && strings.Contains(s.Text(), "dogs")
+	})
+	p.Type("\x03")
+	if err := p.Wait(); err != nil {
+		t.Fatalf("tui exited with %v", err)
+	}
+}
+
+func TestE2EItermPreview(t *testing.T) {
+	p := startE2E(t, "iterm", "cats")
+	p.WaitFor("iTerm2 preview", func(s *testutil.Screen) bool {
+		return len(s.ItermImages()) > 0
+	})
+	img := p.Screen().ItermImages()[0]
+	if img.Args["inline"] != "1" || !bytes.Equal(img.Data, testutil.MakeTestGIF()) {
+		t.Fatalf("unexpected inline file %+v", img.Args)
+	}
+	if col := listColumn(p.Screen(), "Cat One"); img.Col != 0 || col <= 0 {
+		t.Fatalf("expected preview at col 0 and list beside it, got %d and %d", img.Col, col)
+	}
+	p.Type("q")
+	if err := p.Wait(); err != nil {
+		t.Fatalf("tui exited with %v", err)
+	}
+}
+
+func TestE2EBlocksPreviewFollowsResize(t *testing.T) {
+	p := startE2E(t, "blocks", "cats")
+	hasBlocks := func(s *testutil.Screen) bool {
+		return strings.ContainsAny(s.Text(), "▀▄█")
+	}
+	p.WaitFor("block-art preview", hasBlocks)
+
+	// Narrow terminals stack the preview below the list.
+	p.Resize(24, 60)
+	p.WaitFor("stacked preview", func(s *testutil.Screen) bool {
+		for r := 12; r < 24; r++ {
+			if strings.ContainsAny(s.Line(r), "▀▄█") {
+				return true
+			}
+		}
+		return false
+	})
+	p.Type("q")
+	if err := p.Wait(); err != nil {
+		t.Fatalf("tui exited with %v", err)
+	}
+}
+
+// listColumn returns the column where text starts on screen, or -1.
+func listColumn(s *testutil.Screen, text string) int {
+	for _, line := range strings.Split(s.Text(), "\n") {
+		if i := strings.Index(line, text); i >= 0 {
+			return len([]rune(line[:i]))
+		}
+	}
+	return -1
+}
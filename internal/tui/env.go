@@ -6,6 +6,7 @@ import (
 	"os/signal"
 	"syscall"
 
+	"github.com/steipete/gifgrep/internal/termcaps"
 	"golang.org/x/term"
 )
 
@@ -17,6 +18,7 @@ type Env struct {
 	MakeRaw    func(int) (*term.State, error)
 	Restore    func(int, *term.State) error
 	GetSize    func(int) (int, int, error)
+	CellSize   func() termcaps.CellSize
 	SignalCh   <-chan os.Signal
 }
 
@@ -34,6 +36,7 @@ func defaultEnv() Env {
 		MakeRaw:    term.MakeRaw,
 		Restore:    term.Restore,
 		GetSize:    term.GetSize,
+		CellSize:   termcaps.DetectCellSize,
 		SignalCh:   sigs,
 	}
 }
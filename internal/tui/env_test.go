@@ -5,9 +5,12 @@ import (
 	"errors"
 	"io"
 	"os"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/steipete/gifgrep/internal/model"
+	"github.com/steipete/gifgrep/internal/termcaps"
 	"github.com/steipete/gifgrep/internal/testutil"
 	"golang.org/x/term"
 )
@@ -43,6 +46,51 @@ func TestRunTUIWithQuit(t *testing.T) {
 	}
 }
 
+// watchedReader notes when the input reader first reads.
+type watchedReader struct {
+	r    io.Reader
+	read atomic.Bool
+}
+
+func (w *watchedReader) Read(b []byte) (int, error) {
+	w.read.Store(true)
+	return w.r.Read(b)
+}
+
+func TestRunTUIProbesCellSizeBeforeReadingInput(t *testing.T) {
+	t.Setenv("GIFGREP_INLINE", "kitty")
+	in := &watchedReader{r: bytes.NewReader([]byte("q"))}
+	raw, probed := false, false
+	env := Env{
+		In:         in,
+		Out:        io.Discard,
+		FD:         1,
+		IsTerminal: func(int) bool { return true },
+		MakeRaw: func(int) (*term.State, error) {
+			raw = true
+			return &term.State{}, nil
+		},
+		Restore: func(int, *term.State) error { return nil },
+		GetSize: func(int) (int, int, error) { return 80, 24, nil },
+		CellSize: func() termcaps.CellSize {
+			// Give an already started input reader time to reach Read.
+			time.Sleep(20 * time.Millisecond)
+			if !raw || in.read.Load() {
+				t.Errorf("cell size probed with raw=%v, input reading=%v", raw, in.read.Load())
+			}
+			probed = true
+			return termcaps.CellSize{}
+		},
+		SignalCh: make(chan os.Signal),
+	}
+	if err := runWith(env, model.Options{Source: "tenor"}, ""); err != nil {
+		t.Fatalf("runWith: %v", err)
+	}
+	if !probed {
+		t.Fatalf("cell size not probed")
+	}
+}
+
 func TestRunTUIWithSearch(t *testing.T) {
 	t.Setenv("GIFGREP_INLINE", "kitty")
 	gifData := testutil.MakeTestGIF()
@@ -0,0 +1,35 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/steipete/gifgrep/internal/testutil"
+)
+
+func FuzzGIFSize(f *testing.F) {
+	paths, err := filepath.Glob(filepath.Join("..", "..", "gifdecode", "testdata", "*.gif"))
+	if err != nil {
+		f.Fatalf("glob fixtures: %v", err)
+	}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			f.Fatalf("read fixture: %v", err)
+		}
+		f.Add(data)
+	}
+	f.Add(testutil.MakeTestGIF())
+	f.Add([]byte("GIF89a"))
+	f.Add([]byte("GIF87a\xff\xff\xff\xff"))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		w, h := gifSize(data)
+		if w < 0 || h < 0 || w > 0xffff || h > 0xffff {
+			t.Fatalf("unexpected size %dx%d", w, h)
+		}
+		if (w != 0 || h != 0) && len(data) < 10 {
+			t.Fatalf("size from short input")
+		}
+	})
+}
@@ -0,0 +1,129 @@
+package tui
+
+import (
+	"bufio"
+	"bytes"
+	"image"
+	"image/png"
+	"math"
+	"time"
+
+	"github.com/steipete/gifgrep/internal/blocks"
+	"github.com/steipete/gifgrep/internal/sixel"
+	"github.com/steipete/gifgrep/internal/termcaps"
+)
+
+// gridFrames caches encoded frames for one animation at one preview size;
+// encoding is the expensive part of software playback. Each frame is a list
+// of chunks drawn at successive rows of the preview rect (one chunk for a
+// sixel image, one per row for block art).
+type gridFrames struct {
+	id          uint32
+	cols        int
+	rows        int
+	data        [][]string
+	transparent []bool
+}
+
+// drawsInGrid reports whether the protocol paints previews into the text grid
+// and relies on gifgrep for playback.
+func drawsInGrid(inline termcaps.InlineProtocol) bool {
+	return inline == termcaps.InlineSixel || inline == termcaps.InlineBlocks
+}
+
+func drawPreviewGrid(state *appState, out *bufio.Writer, cols, rows int, row, col int) {
+	anim := state.currentAnim
+	if anim == nil || len(anim.Frames) == 0 {
+		return
+	}
+	rect := itermRect{row: row, col: col, cols: cols, rows: rows}
+	if state.previewNeedsSend || state.gridLast != rect {
+		// Blank the old and new rects so a smaller image doesn't leave stale
+		// pixels behind.
+		if state.gridLast.cols > 0 && state.gridLast != rect {
+			clearItermRectFn(out, state.gridLast.row, state.gridLast.col, state.gridLast.cols, state.gridLast.rows)
+		}
+		clearItermRectFn(out, row, col, cols, rows)
+		state.gridLast = rect
+	}
+	if state.previewNeedsSend {
+		state.manualAnim = len(anim.Frames) > 1
+		state.manualFrame = 0
+		state.manualNext = time.Now().Add(anim.Frames[0].Delay)
+	}
+	// Text rendering may have overwritten the image, so it is redrawn on every
+	// render; frames come from the cache.
+	writeGridFrame(state, out, state.manualFrame, rect)
+	state.previewNeedsSend = false
+	state.previewDirty = false
+	state.lastPreview.cols = cols
+	state.lastPreview.rows = rows
+}
+
+func writeGridFrame(state *appState, out *bufio.Writer, idx int, rect itermRect) {
+	chunks, transparent := gridFrame(state, idx, rect.cols, rect.rows)
+	if len(chunks) == 0 {
+		return
+	}
+	if transparent && state.manualAnim {
+		// Transparent sixels keep what's on screen, i.e. the previous frame.
+		clearItermRectFn(out, rect.row, rect.col, rect.cols, rect.rows)
+	}
+	saveCursor(out)
+	for i, chunk := range chunks {
+		moveCursor(out, rect.row+i, rect.col)
+		_, _ = out.WriteString(chunk)
+	}
+	restoreCursor(out)
+}
+
+func gridFrame(state *appState, idx, cols, rows int) ([]string, bool) {
+	anim := state.currentAnim
+	if anim == nil || idx < 0 || idx >= len(anim.Frames) {
+		return nil, false
+	}
+	c := &state.gridCache
+	if c.id != anim.ID || c.cols != cols || c.rows != rows || len(c.data) != len(anim.Frames) {
+		*c = gridFrames{
+			id:          anim.ID,
+			cols:        cols,
+			rows:        rows,
+			data:        make([][]string, len(anim.Frames)),
+			transparent: make([]bool, len(anim.Frames)),
+		}
+	}
+	if c.data[idx] == nil {
+		img, err := png.Decode(bytes.NewReader(anim.Frames[idx].PNG))
+		if err != nil {
+			return nil, false
+		}
+		if state.inline == termcaps.InlineBlocks {
+			// Blocks fill every cell, so nothing shows through.
+			c.data[idx] = blocks.Render(img, cols, rows, state.blocks)
+		} else {
+			cellW, cellH := sixelCellSize(state.cell)
+			b := img.Bounds()
+			w, h := sixel.FitPixels(b.Dx(), b.Dy(), cols*cellW, rows*cellH)
+			c.data[idx] = []string{string(sixel.Encode(img, w, h))}
+			c.transparent[idx] = !isOpaque(img)
+		}
+	}
+	return c.data[idx], c.transparent[idx]
+}
+
+// sixelCellSize keeps the pixel box consistent with the cell aspect used to
+// size the preview rectangle.
+func sixelCellSize(cell termcaps.CellSize) (int, int) {
+	w := sixel.DefaultCellWidth
+	if cell.Valid() {
+		w = cell.Width
+	}
+	return w, int(math.Round(float64(w) / cellAspectRatio(cell)))
+}
+
+func isOpaque(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return o.Opaque()
+	}
+	return false
+}
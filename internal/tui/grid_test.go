@@ -0,0 +1,136 @@
+package tui
+
+import (
+	"bufio"
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steipete/gifgrep/gifdecode"
+	"github.com/steipete/gifgrep/internal/blocks"
+	"github.com/steipete/gifgrep/internal/termcaps"
+)
+
+func solidPNG(t *testing.T, c color.Color) []byte {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, 4, 2))
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 4; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("png encode: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestDrawPreviewSixelAnimates(t *testing.T) {
+	prev := clearItermRectFn
+	t.Cleanup(func() { clearItermRectFn = prev })
+	var clears int
+	clearItermRectFn = func(_ *bufio.Writer, _, _, _, _ int) { clears++ }
+
+	state := &appState{
+		inline: termcaps.InlineSixel,
+		currentAnim: &gifAnimation{
+			ID: 3,
+			Frames: []gifdecode.Frame{
+				{PNG: solidPNG(t, color.White), Delay: 10 * time.Millisecond},
+				{PNG: solidPNG(t, color.Black), Delay: 10 * time.Millisecond},
+			},
+			Width:  4,
+			Height: 2,
+		},
+		previewNeedsSend: true,
+		previewRow:       2,
+		previewCol:       1,
+	}
+	var buf bytes.Buffer
+	out := bufio.NewWriter(&buf)
+	drawPreview(state, out, 10, 5, 2, 1)
+	_ = out.Flush()
+	if !strings.Contains(buf.String(), "\x1b[2;1H\x1bP0;1;0q") {
+		t.Fatalf("expected sixel at preview origin: %q", buf.String())
+	}
+	if !state.manualAnim || clears != 1 {
+		t.Fatalf("expected manual animation and one clear, got %v/%d", state.manualAnim, clears)
+	}
+
+	buf.Reset()
+	state.manualNext = time.Now().Add(-time.Millisecond)
+	advanceManualAnimation(state, out)
+	_ = out.Flush()
+	if state.manualFrame != 1 || !strings.Contains(buf.String(), "#0;2;0;0;0") {
+		t.Fatalf("expected black second frame: %q", buf.String())
+	}
+	if state.gridCache.id != 3 || state.gridCache.data[0] == nil || state.gridCache.data[1] == nil {
+		t.Fatalf("expected both frames cached")
+	}
+
+	// Moving the preview clears the old and new rects.
+	drawPreview(state, out, 10, 5, 3, 1)
+	if clears != 3 {
+		t.Fatalf("expected old and new rect clears, got %d", clears)
+	}
+}
+
+func TestRenderClearsGridPreviewWhenPreviewGoes(t *testing.T) {
+	prev := clearItermRectFn
+	t.Cleanup(func() { clearItermRectFn = prev })
+	var cleared itermRect
+	clearItermRectFn = func(_ *bufio.Writer, row, col, cols, rows int) {
+		cleared = itermRect{row: row, col: col, cols: cols, rows: rows}
+	}
+
+	state := &appState{
+		mode:     modeBrowse,
+		inline:   termcaps.InlineSixel,
+		gridLast: itermRect{row: 2, col: 1, cols: 10, rows: 5},
+	}
+	var buf bytes.Buffer
+	out := bufio.NewWriter(&buf)
+	render(state, out, 10, 60)
+	if cleared != (itermRect{row: 2, col: 1, cols: 10, rows: 5}) || state.gridLast.cols != 0 {
+		t.Fatalf("expected stale sixel rect cleared, got %+v", cleared)
+	}
+}
+
+func TestDrawPreviewBlocksWritesEveryRow(t *testing.T) {
+	state := &appState{
+		inline: termcaps.InlineBlocks,
+		blocks: blocks.Options{TrueColor: true},
+		currentAnim: &gifAnimation{
+			ID:     5,
+			Frames: []gifdecode.Frame{{PNG: solidPNG(t, color.White), Delay: 10 * time.Millisecond}},
+			Width:  4,
+			Height: 2,
+		},
+		previewNeedsSend: true,
+	}
+	var buf bytes.Buffer
+	out := bufio.NewWriter(&buf)
+	drawPreview(state, out, 4, 2, 3, 2)
+	_ = out.Flush()
+	s := buf.String()
+	for _, pos := range []string{"\x1b[3;2H\x1b[38;2;255;255;255;48;2;255;255;255m▀▀▀▀", "\x1b[4;2H\x1b[38;2;255;255;255;48;2;255;255;255m▀▀▀▀"} {
+		if !strings.Contains(s, pos) {
+			t.Fatalf("expected row %q in %q", pos, s)
+		}
+	}
+	if state.manualAnim {
+		t.Fatalf("single frame should not animate")
+	}
+}
+
+func TestDetectInlineProtocolFallsBackToBlocks(t *testing.T) {
+	t.Setenv("GIFGREP_INLINE", "none")
+	if got := detectInlineProtocol(); got != termcaps.InlineBlocks {
+		t.Fatalf("expected blocks fallback, got %v", got)
+	}
+}
@@ -0,0 +1,156 @@
+package tui
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/steipete/gifgrep/internal/history"
+	"github.com/steipete/gifgrep/internal/search"
+)
+
+// reverseSearch is an active Ctrl-R search over the query history.
+type reverseSearch struct {
+	term   string
+	match  int // index into appState.history, -1 before the first match
+	failed bool
+	saved  string // query to restore on Esc
+}
+
+// loadHistory reads past queries for Up/Down and Ctrl-R. Without a history
+// path (tests, or no home directory) recall only covers this session.
+func loadHistory(state *appState) {
+	if state.opts.HistoryPath == "" {
+		return
+	}
+	entries, err := history.Load(state.opts.HistoryPath)
+	if err != nil {
+		return
+	}
+	state.history = history.Queries(entries)
+}
+
+// recordSearch adds a finished search to the history, moving a repeated
+// query to the end.
+func recordSearch(state *appState, query string, results int) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return
+	}
+	kept := state.history[:0]
+	for _, q := range state.history {
+		if q != query {
+			kept = append(kept, q)
+		}
+	}
+	state.history = append(kept, query)
+	state.historyBack = 0
+	if state.opts.HistoryPath != "" {
+		_ = history.Append(state.opts.HistoryPath, history.Entry{
+			Query:   query,
+			Source:  search.ResolveSource(state.opts.Source),
+			Results: results,
+		})
+	}
+}
+
+// historyPrev and historyNext step through older and newer queries; stepping
+// past the newest brings back what was being typed.
+func historyPrev(state *appState) {
+	if state.historyBack >= len(state.history) {
+		return
+	}
+	if state.historyBack == 0 {
+		state.historyDraft = state.query
+	}
+	state.historyBack++
+	state.query = state.history[len(state.history)-state.historyBack]
+	state.renderDirty = true
+}
+
+func historyNext(state *appState) {
+	if state.historyBack == 0 {
+		return
+	}
+	state.historyBack--
+	if state.historyBack == 0 {
+		state.query = state.historyDraft
+	} else {
+		state.query = state.history[len(state.history)-state.historyBack]
+	}
+	state.renderDirty = true
+}
+
+// startReverseSearch begins a Ctrl-R search or, when one is active, moves to
+// the next older match.
+func startReverseSearch(state *appState) {
+	state.renderDirty = true
+	if rs := state.rsearch; rs != nil {
+		if rs.term == "" || rs.match <= 0 {
+			rs.failed = rs.term != ""
+			return
+		}
+		findReverse(state, rs.match-1)
+		return
+	}
+	state.rsearch = &reverseSearch{match: -1, saved: state.query}
+}
+
+// handleReverseSearchInput edits the search term. It reports whether ev was
+// consumed; Enter accepts the match and falls through to run the search.
+func handleReverseSearchInput(state *appState, ev inputEvent) bool {
+	rs := state.rsearch
+	state.renderDirty = true
+	switch ev.kind {
+	case keyRune:
+		rs.term += string(ev.ch)
+		from := rs.match
+		if from < 0 {
+			from = len(state.history) - 1
+		}
+		findReverse(state, from)
+		return true
+	case keyBackspace:
+		if rs.term != "" {
+			_, size := utf8.DecodeLastRuneInString(rs.term)
+			rs.term = rs.term[:len(rs.term)-size]
+		}
+		rs.match = -1
+		rs.failed = false
+		if rs.term == "" {
+			state.query = rs.saved
+			return true
+		}
+		findReverse(state, len(state.history)-1)
+		return true
+	case keyCtrlR:
+		startReverseSearch(state)
+		return true
+	case keyEsc:
+		state.query = rs.saved
+		state.rsearch = nil
+		return true
+	case keyEnter, keyUp, keyDown:
+		state.rsearch = nil
+		state.historyBack = 0
+		return ev.kind != keyEnter
+	case keyCtrlC, keyUnknown:
+		return false
+	}
+	return false
+}
+
+// findReverse shows the newest query at or before index from that contains
+// the term, case-insensitively; a miss keeps the previous match.
+func findReverse(state *appState, from int) {
+	rs := state.rsearch
+	term := strings.ToLower(rs.term)
+	for i := minInt(from, len(state.history)-1); i >= 0; i-- {
+		if strings.Contains(strings.ToLower(state.history[i]), term) {
+			rs.match = i
+			rs.failed = false
+			state.query = state.history[i]
+			return
+		}
+	}
+	rs.failed = true
+}
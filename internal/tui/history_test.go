@@ -0,0 +1,127 @@
+package tui
+
+import (
+	"bufio"
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/steipete/gifgrep/internal/history"
+	"github.com/steipete/gifgrep/internal/model"
+)
+
+func TestHistoryUpDownRecall(t *testing.T) {
+	state := &appState{mode: modeQuery, keys: keymap{}, history: []string{"cats", "dogs", "owls"}}
+	out := bufio.NewWriter(&bytes.Buffer{})
+	state.query = "par"
+
+	steps := []struct {
+		kind keyKind
+		want string
+	}{
+		{keyUp, "owls"}, {keyUp, "dogs"}, {keyUp, "cats"}, {keyUp, "cats"},
+		{keyDown, "dogs"}, {keyDown, "owls"}, {keyDown, "par"}, {keyDown, "par"},
+	}
+	for i, step := range steps {
+		handleQueryInput(state, inputEvent{kind: step.kind}, out, nil)
+		if state.query != step.want {
+			t.Fatalf("step %d: query %q, want %q", i, state.query, step.want)
+		}
+	}
+
+	// Editing a recalled query makes it the new draft.
+	handleQueryInput(state, inputEvent{kind: keyUp}, out, nil)
+	handleQueryInput(state, inputEvent{kind: keyRune, ch: '!'}, out, nil)
+	handleQueryInput(state, inputEvent{kind: keyUp}, out, nil)
+	handleQueryInput(state, inputEvent{kind: keyDown}, out, nil)
+	if state.query != "owls!" {
+		t.Fatalf("draft %q", state.query)
+	}
+}
+
+func TestReverseSearch(t *testing.T) {
+	state := &appState{mode: modeBrowse, keys: keymap{}, query: "typed", history: []string{"cat jump", "dog", "Cat nap", "owl"}}
+	out := bufio.NewWriter(&bytes.Buffer{})
+
+	handleInput(state, inputEvent{kind: keyCtrlR}, out, nil)
+	if state.mode != modeQuery || state.rsearch == nil {
+		t.Fatalf("Ctrl-R should open reverse search from browse mode")
+	}
+	for _, r := range "cat" {
+		handleQueryInput(state, inputEvent{kind: keyRune, ch: r}, out, nil)
+	}
+	if state.query != "Cat nap" {
+		t.Fatalf("match %q", state.query)
+	}
+	handleQueryInput(state, inputEvent{kind: keyCtrlR}, out, nil)
+	if state.query != "cat jump" || state.rsearch.failed {
+		t.Fatalf("older match %q", state.query)
+	}
+	handleQueryInput(state, inputEvent{kind: keyCtrlR}, out, nil)
+	if state.query != "cat jump" || !state.rsearch.failed {
+		t.Fatalf("no older match should fail and keep %q", state.query)
+	}
+
+	var term bytes.Buffer
+	w := bufio.NewWriter(&term)
+	drawSearch(w, state, layout{cols: 80, searchRow: 5})
+	_ = w.Flush()
+	if !strings.Contains(term.String(), "(failed reverse-i-search)`cat': cat jump") {
+		t.Fatalf("search row %q", term.String())
+	}
+
+	handleQueryInput(state, inputEvent{kind: keyEsc}, out, nil)
+	if state.rsearch != nil || state.query != "typed" || state.mode != modeQuery {
+		t.Fatalf("Esc should restore %q, got %q", "typed", state.query)
+	}
+
+	handleQueryInput(state, inputEvent{kind: keyCtrlR}, out, nil)
+	handleQueryInput(state, inputEvent{kind: keyRune, ch: 'd'}, out, nil)
+	handleQueryInput(state, inputEvent{kind: keyUp}, out, nil)
+	if state.rsearch != nil || state.query != "dog" {
+		t.Fatalf("Up should accept the match, got %q", state.query)
+	}
+}
+
+func TestReverseSearchTypesQuitKeyAndErasesRunes(t *testing.T) {
+	state := &appState{mode: modeQuery, keys: keymap{}, history: []string{"quick", "café"}}
+	out := bufio.NewWriter(&bytes.Buffer{})
+
+	handleInput(state, inputEvent{kind: keyCtrlR}, out, nil)
+	if handleInput(state, inputEvent{kind: keyRune, ch: 'q'}, out, nil) {
+		t.Fatalf("q during Ctrl-R should search, not quit")
+	}
+	if state.rsearch.term != "q" || state.query != "quick" {
+		t.Fatalf("term %q, match %q", state.rsearch.term, state.query)
+	}
+
+	handleInput(state, inputEvent{kind: keyEsc}, out, nil)
+	handleInput(state, inputEvent{kind: keyCtrlR}, out, nil)
+	for _, r := range "café" {
+		handleInput(state, inputEvent{kind: keyRune, ch: r}, out, nil)
+	}
+	handleInput(state, inputEvent{kind: keyBackspace}, out, nil)
+	if state.rsearch.term != "caf" || state.query != "café" {
+		t.Fatalf("backspace left term %q, match %q", state.rsearch.term, state.query)
+	}
+}
+
+func TestRecordSearchPersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	state := &appState{keys: keymap{}, history: []string{"cats", "dogs"}, opts: model.Options{Source: "tenor", HistoryPath: path}}
+	recordSearch(state, " cats ", 7)
+	if strings.Join(state.history, ",") != "dogs,cats" {
+		t.Fatalf("history %v", state.history)
+	}
+	entries, err := history.Load(path)
+	if err != nil || len(entries) != 1 || entries[0].Query != "cats" || entries[0].Source != "tenor" || entries[0].Results != 7 {
+		t.Fatalf("entries %+v %v", entries, err)
+	}
+
+	fresh := &appState{opts: state.opts}
+	loadHistory(fresh)
+	if strings.Join(fresh.history, ",") != "cats" {
+		t.Fatalf("loaded %v", fresh.history)
+	}
+}
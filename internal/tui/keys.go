@@ -0,0 +1,80 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Browse-mode actions that config.toml's [keys] table can rebind.
+const (
+	actionSearch            = "search"
+	actionDownload          = "download"
+	actionReveal            = "reveal"
+	actionOpen              = "open"
+	actionShowURL           = "url"
+	actionStar              = "star"
+	actionBrowseCollections = "collections"
+	actionCopy              = "copy"
+	actionCopyGIF           = "copy_gif"
+	actionQuit              = "quit"
+)
+
+var defaultKeys = map[string]rune{
+	actionSearch:            '/',
+	actionDownload:          'd',
+	actionReveal:            'f',
+	actionOpen:              'o',
+	actionShowURL:           'u',
+	actionStar:              's',
+	actionBrowseCollections: 'c',
+	actionCopy:              'y',
+	actionCopyGIF:           'Y',
+	actionQuit:              'q',
+}
+
+// keymap maps actions to keys; missing actions use defaultKeys.
+type keymap map[string]rune
+
+func (k keymap) key(action string) rune {
+	if r, ok := k[action]; ok {
+		return r
+	}
+	return defaultKeys[action]
+}
+
+// newKeymap applies action = key overrides to the defaults. Keys are single
+// printable ASCII characters, the only runes the input reader produces.
+func newKeymap(overrides map[string]string) (keymap, error) {
+	k := keymap{}
+	for action, r := range defaultKeys {
+		k[action] = r
+	}
+	for action, key := range overrides {
+		if _, ok := defaultKeys[action]; !ok {
+			return nil, fmt.Errorf("keys.%s: unknown action (have: %s)", action, strings.Join(sortedActions(), ", "))
+		}
+		if len(key) != 1 || key[0] < 0x20 || key[0] >= 0x7f {
+			return nil, fmt.Errorf("keys.%s: %q is not a single printable ASCII character", action, key)
+		}
+		k[action] = rune(key[0])
+	}
+	bound := map[rune]string{}
+	for _, action := range sortedActions() {
+		r := k[action]
+		if other, ok := bound[r]; ok {
+			return nil, fmt.Errorf("keys: %q is bound to both %s and %s", r, other, action)
+		}
+		bound[r] = action
+	}
+	return k, nil
+}
+
+func sortedActions() []string {
+	actions := make([]string, 0, len(defaultKeys))
+	for action := range defaultKeys {
+		actions = append(actions, action)
+	}
+	sort.Strings(actions)
+	return actions
+}
@@ -0,0 +1,74 @@
+package tui
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/steipete/gifgrep/internal/download"
+	"github.com/steipete/gifgrep/internal/model"
+)
+
+func TestNewKeymapOverrides(t *testing.T) {
+	k, err := newKeymap(map[string]string{"download": "D", "quit": "x"})
+	if err != nil {
+		t.Fatalf("newKeymap: %v", err)
+	}
+	if k.key(actionDownload) != 'D' || k.key(actionQuit) != 'x' || k.key(actionReveal) != 'f' {
+		t.Fatalf("unexpected keymap %v", k)
+	}
+	var nilMap keymap
+	if nilMap.key(actionSearch) != '/' {
+		t.Fatalf("nil keymap should fall back to defaults")
+	}
+}
+
+func TestNewKeymapErrors(t *testing.T) {
+	cases := map[string]map[string]string{
+		"unknown action": {"dance": "z"},
+		"multi-char":     {"download": "dd"},
+		"non-ascii":      {"download": "é"},
+		"duplicate":      {"download": "f"},
+	}
+	for name, overrides := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := newKeymap(overrides); err == nil || !strings.HasPrefix(err.Error(), "keys") {
+				t.Fatalf("expected keys error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestBrowseInputUsesKeymap(t *testing.T) {
+	orig := saveFn
+	t.Cleanup(func() { saveFn = orig })
+	var gotDir string
+	saveFn = func(_ model.Result, opts download.Options) (download.Saved, error) {
+		gotDir = opts.Dir
+		return download.Saved{}, nil
+	}
+
+	keys, err := newKeymap(map[string]string{"download": "D"})
+	if err != nil {
+		t.Fatalf("newKeymap: %v", err)
+	}
+	state := &appState{
+		mode:    modeBrowse,
+		keys:    keys,
+		opts:    model.Options{DownloadDir: "/tmp/gifs"},
+		results: []model.Result{{ID: "1", URL: "https://example.test/1.gif"}},
+	}
+	out := bufio.NewWriter(&bytes.Buffer{})
+
+	handleBrowseInput(state, inputEvent{kind: keyRune, ch: 'd'}, out)
+	if state.mode != modeQuery || state.query != "d" {
+		t.Fatalf("unbound 'd' should start a query, got mode %v query %q", state.mode, state.query)
+	}
+
+	state.mode = modeBrowse
+	handleBrowseInput(state, inputEvent{kind: keyRune, ch: 'D'}, out)
+	if gotDir != "/tmp/gifs" {
+		t.Fatalf("expected download into the configured dir, got %q", gotDir)
+	}
+}
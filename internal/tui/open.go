@@ -0,0 +1,40 @@
+package tui
+
+import "github.com/steipete/gifgrep/internal/reveal"
+
+var openURLFn = reveal.OpenURL
+
+// openSelected opens the selected result's provider page in the browser,
+// falling back to the GIF itself.
+func openSelected(state *appState) {
+	defer func() { state.renderDirty = true }()
+	if state.selected < 0 || state.selected >= len(state.results) {
+		flashHeader(state, "No selection")
+		return
+	}
+	item := state.results[state.selected]
+	target := item.PageURL
+	if target == "" {
+		target = item.URL
+	}
+	if target == "" {
+		flashHeader(state, "No URL")
+		return
+	}
+	if err := openURLFn(target); err != nil {
+		flashHeader(state, "Open failed: "+err.Error())
+		return
+	}
+	flashHeader(state, "Opened in browser")
+}
+
+// selectedURLLine is the selected result's GIF URL while the URL line is
+// toggled on. It wraps over the status and hint rows, and further up when
+// it needs more, rather than being cut at the terminal width.
+func selectedURLLine(state *appState) (string, bool) {
+	if !state.showURL || state.selected < 0 || state.selected >= len(state.results) {
+		return "", false
+	}
+	url := state.results[state.selected].URL
+	return url, url != ""
+}
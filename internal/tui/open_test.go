@@ -0,0 +1,106 @@
+package tui
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/steipete/gifgrep/internal/model"
+)
+
+func TestOpenKeyPrefersPageURL(t *testing.T) {
+	prev := openURLFn
+	t.Cleanup(func() { openURLFn = prev })
+
+	var opened string
+	openURLFn = func(url string) error {
+		opened = url
+		return nil
+	}
+	state := &appState{results: []model.Result{
+		{ID: "1", URL: "https://media.test/1.gif", PageURL: "https://tenor.com/view/cat-1"},
+		{ID: "2", URL: "https://media.test/2.gif"},
+	}, keys: keymap{}}
+	out := bufio.NewWriter(&bytes.Buffer{})
+
+	handleBrowseInput(state, inputEvent{kind: keyRune, ch: 'o'}, out)
+	if opened != "https://tenor.com/view/cat-1" || state.headerFlash != "Opened in browser" {
+		t.Fatalf("opened %q, flash %q", opened, state.headerFlash)
+	}
+
+	state.selected = 1
+	handleBrowseInput(state, inputEvent{kind: keyRune, ch: 'o'}, out)
+	if opened != "https://media.test/2.gif" {
+		t.Fatalf("fallback opened %q", opened)
+	}
+
+	openURLFn = func(string) error { return errors.New("no URL opener found") }
+	openSelected(state)
+	if state.headerFlash != "Open failed: no URL opener found" {
+		t.Fatalf("flash %q", state.headerFlash)
+	}
+	if state.mode != modeBrowse {
+		t.Fatalf("o must not start a query")
+	}
+}
+
+func TestURLKeyTogglesFullWidthLine(t *testing.T) {
+	url := "https://media.tenor.com/abcdefghijklmnop/a-very-long-cat-name-that-keeps-going.gif"
+	state := &appState{results: []model.Result{{ID: "1", URL: url}}, keys: keymap{}, status: "1 results"}
+
+	var buf bytes.Buffer
+	out := bufio.NewWriter(&buf)
+	handleBrowseInput(state, inputEvent{kind: keyRune, ch: 'u'}, out)
+	if !state.showURL || !state.renderDirty {
+		t.Fatalf("u should toggle the URL line on")
+	}
+	lay := buildLayout(state, 10, 100)
+	drawStatus(out, state, lay)
+	_ = out.Flush()
+	if !strings.Contains(buf.String(), url+"\x1b[K") || strings.Contains(buf.String(), "results") {
+		t.Fatalf("status row = %q", buf.String())
+	}
+
+	handleBrowseInput(state, inputEvent{kind: keyRune, ch: 'u'}, out)
+	buf.Reset()
+	drawStatus(out, state, buildLayout(state, 10, 100))
+	_ = out.Flush()
+	if state.showURL || !strings.Contains(buf.String(), "1 results") {
+		t.Fatalf("status row after toggle = %q", buf.String())
+	}
+}
+
+func TestURLLineWrapsInsteadOfTruncating(t *testing.T) {
+	url := "https://media.tenor.com/abcdefghijklmnop/a-very-long-cat-name-that-keeps-going.gif"
+	state := &appState{results: []model.Result{{ID: "1", URL: url}}, keys: keymap{}, showURL: true}
+
+	var buf bytes.Buffer
+	out := bufio.NewWriter(&buf)
+	lay := buildLayout(state, 12, 30)
+	if len(lay.urlLines) != 3 || strings.Join(lay.urlLines, "") != url {
+		t.Fatalf("url lines = %q", lay.urlLines)
+	}
+	if lay.statusRow != 10 || lay.searchRow != 9 || lay.contentBottom != 8 {
+		t.Fatalf("layout = %+v", lay)
+	}
+	drawStatus(out, state, lay)
+	drawHints(out, state, lay)
+	_ = out.Flush()
+	for i, line := range lay.urlLines {
+		if !strings.Contains(buf.String(), fmt.Sprintf("\x1b[%d;1H%s\x1b[K", lay.statusRow+i, line)) {
+			t.Fatalf("row %d missing %q: %q", lay.statusRow+i, line, buf.String())
+		}
+	}
+	if strings.Contains(buf.String(), "Search") {
+		t.Fatalf("hints drawn over the URL: %q", buf.String())
+	}
+
+	// Two lines fit the status and hint rows without moving the search row.
+	lay = buildLayout(state, 12, 60)
+	if len(lay.urlLines) != 2 || lay.statusRow != 11 || lay.searchRow != 10 {
+		t.Fatalf("layout = %+v", lay)
+	}
+}
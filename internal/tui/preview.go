@@ -22,6 +22,12 @@ func gifSize(raw []byte) (w, h int) {
 	return w, h
 }
 
+// decodesFrames reports whether the protocol draws decoded frames rather than
+// the raw GIF bytes.
+func decodesFrames(inline termcaps.InlineProtocol) bool {
+	return inline == termcaps.InlineKitty || drawsInGrid(inline)
+}
+
 func loadSelectedImage(state *appState) {
 	if state.cache == nil {
 		state.cache = map[string]*gifCacheEntry{}
@@ -62,7 +68,7 @@ func loadSelectedImage(state *appState) {
 		}
 		w, h := gifSize(data)
 		entry = &gifCacheEntry{RawGIF: data, Width: w, Height: h}
-		if state.inline == termcaps.InlineKitty {
+		if decodesFrames(state.inline) {
 			decoded, err := gifdecode.Decode(data, gifdecode.DefaultOptions())
 			if err != nil {
 				state.status = "Image error: " + err.Error()
@@ -75,7 +81,7 @@ func loadSelectedImage(state *appState) {
 		}
 		state.cache[source] = entry
 	}
-	if entry != nil && entry.Frames == nil && state.inline == termcaps.InlineKitty {
+	if entry != nil && entry.Frames == nil && decodesFrames(state.inline) {
 		decoded, err := gifdecode.Decode(entry.RawGIF, gifdecode.DefaultOptions())
 		if err != nil {
 			state.status = "Image error: " + err.Error()
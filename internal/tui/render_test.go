@@ -3,11 +3,14 @@ package tui
 import (
 	"bufio"
 	"bytes"
+	"fmt"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/steipete/gifgrep/gifdecode"
+	"github.com/steipete/gifgrep/internal/iterm"
+	"github.com/steipete/gifgrep/internal/kitty"
 	"github.com/steipete/gifgrep/internal/model"
 	"github.com/steipete/gifgrep/internal/termcaps"
 )
@@ -331,3 +334,61 @@ func TestDrawHintsDoesNotColorWords(t *testing.T) {
 		t.Fatalf("expected hint labels")
 	}
 }
+
+func TestDrawPreviewKittyPlaceholders(t *testing.T) {
+	state := &appState{
+		inline: termcaps.InlineKitty,
+		kitty:  kitty.Options{Placeholders: true},
+		currentAnim: &gifAnimation{
+			ID:     5,
+			Frames: []gifdecode.Frame{{PNG: []byte{1, 2, 3}, Delay: 80 * time.Millisecond}},
+		},
+		previewNeedsSend: true,
+		opts:             model.Options{Source: "tenor"},
+	}
+	var buf bytes.Buffer
+	out := bufio.NewWriter(&buf)
+	drawPreview(state, out, 4, 2, 3, 7)
+	_ = out.Flush()
+	text := buf.String()
+	if !strings.Contains(text, "U=1") {
+		t.Fatalf("expected virtual placement")
+	}
+	for r := 0; r < 2; r++ {
+		want := fmt.Sprintf("\x1b[%d;7H", 3+r) + kitty.PlaceholderRow(5, r, 4)
+		if !strings.Contains(text, want) {
+			t.Fatalf("missing placeholder row %d: %q", r, text)
+		}
+	}
+}
+
+func TestDrawPreviewItermMultipartForLargeGIF(t *testing.T) {
+	raw := append([]byte("GIF89a\x01\x00\x01\x00"), make([]byte, iterm.MultipartThreshold)...)
+	state := &appState{
+		inline:         termcaps.InlineIterm,
+		itermMultipart: true,
+		currentAnim: &gifAnimation{
+			ID:     1,
+			RawGIF: raw,
+			Width:  1,
+			Height: 1,
+		},
+		previewNeedsSend: true,
+	}
+	var buf bytes.Buffer
+	out := bufio.NewWriter(&buf)
+	drawPreview(state, out, 20, 8, 2, 2)
+	_ = out.Flush()
+	if !strings.Contains(buf.String(), "\x1b]1337;MultipartFile=") || !strings.Contains(buf.String(), "\x1b]1337;FileEnd") {
+		t.Fatalf("expected multipart transfer")
+	}
+
+	state.itermMultipart = false
+	state.previewDirty = true
+	buf.Reset()
+	drawPreview(state, out, 20, 8, 2, 2)
+	_ = out.Flush()
+	if strings.Contains(buf.String(), "MultipartFile") || !strings.Contains(buf.String(), "\x1b]1337;File=") {
+		t.Fatalf("expected single-sequence fallback")
+	}
+}
@@ -5,8 +5,11 @@ import (
 	"bytes"
 	"errors"
 	"os"
+	"path/filepath"
+	"reflect"
 	"testing"
 
+	"github.com/steipete/gifgrep/internal/download"
 	"github.com/steipete/gifgrep/internal/model"
 )
 
@@ -17,17 +20,17 @@ func TestRevealSelectedUsesExistingDownload(t *testing.T) {
 	}
 	_ = tmp.Close()
 
-	origDownload := downloadToDownloadsFn
+	origDownload := saveFn
 	origReveal := revealFn
 	t.Cleanup(func() {
-		downloadToDownloadsFn = origDownload
+		saveFn = origDownload
 		revealFn = origReveal
 	})
 
 	downloadCalled := false
-	downloadToDownloadsFn = func(model.Result) (string, error) {
+	saveFn = func(model.Result, download.Options) (download.Saved, error) {
 		downloadCalled = true
-		return "", errors.New("unexpected download")
+		return download.Saved{}, errors.New("unexpected download")
 	}
 
 	var revealed string
@@ -56,24 +59,24 @@ func TestRevealSelectedUsesExistingDownload(t *testing.T) {
 }
 
 func TestRevealSelectedDownloadsWhenMissing(t *testing.T) {
-	origDownload := downloadToDownloadsFn
+	origDownload := saveFn
 	origReveal := revealFn
 	t.Cleanup(func() {
-		downloadToDownloadsFn = origDownload
+		saveFn = origDownload
 		revealFn = origReveal
 	})
 
 	downloadCalled := false
 	var downloadedPath string
-	downloadToDownloadsFn = func(model.Result) (string, error) {
+	saveFn = func(model.Result, download.Options) (download.Saved, error) {
 		downloadCalled = true
 		tmp, err := os.CreateTemp(t.TempDir(), "gifgrep-*.gif")
 		if err != nil {
-			return "", err
+			return download.Saved{}, err
 		}
 		_ = tmp.Close()
 		downloadedPath = tmp.Name()
-		return downloadedPath, nil
+		return download.Saved{Path: downloadedPath}, nil
 	}
 
 	var revealed string
@@ -103,3 +106,46 @@ func TestRevealSelectedDownloadsWhenMissing(t *testing.T) {
 		t.Fatalf("expected state.savedPaths to contain %q, got %q", downloadedPath, got)
 	}
 }
+
+func TestDownloadSelectedPassesOptionsAndReportsSkip(t *testing.T) {
+	orig := saveFn
+	t.Cleanup(func() { saveFn = orig })
+
+	var got download.Options
+	saveFn = func(_ model.Result, opts download.Options) (download.Saved, error) {
+		got = opts
+		return download.Saved{Path: "/tmp/gifs/tenor-1.gif", Skipped: true}, nil
+	}
+
+	state := &appState{
+		results: []model.Result{{ID: "1", URL: "https://example.test/1.gif"}},
+		opts:    model.Options{Source: "tenor", DownloadDir: "/tmp/gifs", DownloadName: "{source}-{id}", DownloadCollision: "skip"},
+	}
+	downloadSelected(state, bufio.NewWriter(&bytes.Buffer{}), false)
+
+	want := download.Options{Dir: "/tmp/gifs", Name: "{source}-{id}", Collision: download.CollisionSkip, Dedup: download.DedupOff, Source: "tenor"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("options = %+v, want %+v", got, want)
+	}
+	if state.headerFlash != "Already saved" || state.lastSavedPath != "/tmp/gifs/tenor-1.gif" {
+		t.Fatalf("unexpected state: flash %q, path %q", state.headerFlash, state.lastSavedPath)
+	}
+}
+
+func TestDownloadSelectedReportsDuplicate(t *testing.T) {
+	orig := saveFn
+	t.Cleanup(func() { saveFn = orig })
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	dup := filepath.Join(home, "Downloads", "cat.gif")
+	saveFn = func(model.Result, download.Options) (download.Saved, error) {
+		return download.Saved{Path: dup, Skipped: true, Duplicate: dup}, nil
+	}
+	state := &appState{results: []model.Result{{ID: "1", URL: "https://example.test/1.gif"}}}
+	downloadSelected(state, bufio.NewWriter(&bytes.Buffer{}), false)
+
+	if want := "Already saved at " + filepath.Join("~", "Downloads", "cat.gif"); state.headerFlash != want {
+		t.Fatalf("flash = %q, want %q", state.headerFlash, want)
+	}
+}
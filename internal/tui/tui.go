@@ -12,6 +12,7 @@ import (
 
 	"github.com/steipete/gifgrep/gifdecode"
 	"github.com/steipete/gifgrep/internal/assets"
+	"github.com/steipete/gifgrep/internal/blocks"
 	"github.com/steipete/gifgrep/internal/iterm"
 	"github.com/steipete/gifgrep/internal/kitty"
 	"github.com/steipete/gifgrep/internal/model"
@@ -35,6 +36,7 @@ const (
 	keyUp
 	keyDown
 	keyCtrlC
+	keyCtrlR
 	keyUnknown
 )
 
@@ -68,6 +70,9 @@ func initEnvDefaults(env Env) (Env, error) {
 	if env.GetSize == nil {
 		env.GetSize = term.GetSize
 	}
+	if env.CellSize == nil {
+		env.CellSize = termcaps.DetectCellSize
+	}
 	if env.FD == 0 {
 		env.FD = int(os.Stdin.Fd())
 	}
@@ -77,12 +82,14 @@ func initEnvDefaults(env Env) (Env, error) {
 	return env, nil
 }
 
-func detectInlineProtocol() (termcaps.InlineProtocol, error) {
+// detectInlineProtocol falls back to block art, which works in any terminal,
+// when no image protocol is available.
+func detectInlineProtocol() termcaps.InlineProtocol {
 	inline := termcaps.DetectInlineRobust(os.Getenv)
 	if inline == termcaps.InlineNone {
-		return inline, errUnsupportedInline(os.Getenv)
+		return termcaps.InlineBlocks
 	}
-	return inline, nil
+	return inline
 }
 
 func setupOutput(out *bufio.Writer, inline termcaps.InlineProtocol) func() {
@@ -124,6 +131,9 @@ func newAppState(inline termcaps.InlineProtocol, opts model.Options) *appState {
 		inline:          inline,
 		useSoftwareAnim: inline == termcaps.InlineKitty && useSoftwareAnimation(),
 		useColor:        opts.Color != "never",
+		blocks:          blocks.OptionsFromEnv(os.Getenv),
+		kitty:           kitty.DetectOptions(os.Getenv),
+		itermMultipart:  iterm.MultipartSupported(os.Getenv),
 		opts:            opts,
 	}
 }
@@ -134,16 +144,21 @@ func runInitialSearch(state *appState, query string, opts model.Options, out *bu
 	}
 	state.query = query
 	state.mode = modeBrowse
+	text, collection := collectionQuery(query)
+	if collection && openCollectionQuery(state, query, prefetchCh) {
+		return
+	}
 	state.status = "Searching..."
 	render(state, out, state.lastRows, state.lastCols)
 	_ = out.Flush()
 
-	results, err := search.Search(query, opts)
+	results, err := search.Search(text, opts)
 	if err != nil {
 		state.status = "Search error: " + err.Error()
 		state.renderDirty = true
 		return
 	}
+	recordSearch(state, query, len(results))
 
 	state.results = results
 	state.selected = 0
@@ -183,6 +198,12 @@ func updateSizeIfNeeded(state *appState, env Env) {
 		if rows != state.lastRows || cols != state.lastCols {
 			state.lastRows = rows
 			state.lastCols = cols
+			// Font size changes resize the grid; pick up the new cell size
+			// where the kernel knows it.
+			if cell := termcaps.WinsizeCellSize(env.FD); cell.Valid() && cell != state.cell {
+				state.cell = cell
+				state.gridCache = gridFrames{}
+			}
 			ensureVisible(state)
 			state.renderDirty = true
 			state.previewDirty = true
@@ -216,31 +237,19 @@ func handleEvents(state *appState, out *bufio.Writer, prefetchCh chan prefetchRe
 	return false
 }
 
-func errUnsupportedInline(getenv func(string) string) error {
-	if getenv == nil {
-		getenv = os.Getenv
-	}
-	termProgram := strings.TrimSpace(getenv("TERM_PROGRAM"))
-	term := strings.TrimSpace(getenv("TERM"))
-	return fmt.Errorf(
-		"gifgrep tui needs inline image support.\n\nSupported terminals:\n  - Kitty (Kitty graphics protocol)\n  - Ghostty (Kitty graphics protocol)\n  - iTerm2 (OSC 1337 inline images)\n\nDetected:\n  TERM_PROGRAM=%q\n  TERM=%q\n\nSee: docs/kitty.md and docs/iterm.md\n\nTip: You can force detection with GIFGREP_INLINE=kitty|iterm|none",
-		termProgram,
-		term,
-	)
-}
-
 func runWith(env Env, opts model.Options, query string) error {
 	var err error
 	env, err = initEnvDefaults(env)
 	if err != nil {
 		return err
 	}
-
-	inline, err := detectInlineProtocol()
+	keys, err := newKeymap(opts.Keys)
 	if err != nil {
 		return err
 	}
 
+	inline := detectInlineProtocol()
+
 	oldState, err := env.MakeRaw(env.FD)
 	if err != nil {
 		return err
@@ -250,8 +259,10 @@ func runWith(env Env, opts model.Options, query string) error {
 			_ = env.Restore(env.FD, oldState)
 		}()
 	}
+	// Probe before the input reader starts so it can't swallow the reply.
+	cell := env.CellSize()
 
-	out := bufio.NewWriter(env.Out)
+	out := bufio.NewWriter(termcaps.PassthroughWriter(env.Out, termcaps.DetectMultiplexer(os.Getenv)))
 	defer setupOutput(out, inline)()
 
 	sigs := setupSignals(env)
@@ -259,7 +270,10 @@ func runWith(env Env, opts model.Options, query string) error {
 	prefetchCh := make(chan prefetchResult, 64)
 
 	state := newAppState(inline, opts)
+	state.keys = keys
+	loadHistory(state)
 	defer cleanupTempDir(state)
+	state.cell = cell
 	if cols, rows, err := env.GetSize(env.FD); err == nil {
 		state.lastRows = rows
 		state.lastCols = cols
@@ -297,6 +311,8 @@ func readInput(r io.Reader, ch chan<- inputEvent, stop <-chan struct{}) {
 		switch b {
 		case 0x03:
 			ch <- inputEvent{kind: keyCtrlC}
+		case 0x12:
+			ch <- inputEvent{kind: keyCtrlR}
 		case '\r', '\n':
 			ch <- inputEvent{kind: keyEnter}
 		case 0x7f, 0x08:
@@ -333,7 +349,8 @@ func handleInput(state *appState, ev inputEvent, out *bufio.Writer, prefetchCh c
 	if ev.kind == keyCtrlC {
 		return true
 	}
-	if ev.kind == keyRune && ev.ch == 'q' {
+	// The quit key is an ordinary character in a Ctrl-R search term.
+	if ev.kind == keyRune && ev.ch == state.keys.key(actionQuit) && state.rsearch == nil {
 		return true
 	}
 
@@ -341,6 +358,12 @@ func handleInput(state *appState, ev inputEvent, out *bufio.Writer, prefetchCh c
 	case modeQuery:
 		return handleQueryInput(state, ev, out, prefetchCh)
 	case modeBrowse:
+		// Showing a collection replaces the results, which needs the prefetch
+		// channel handleBrowseInput does not have.
+		if ev.kind == keyRune && ev.ch == state.keys.key(actionBrowseCollections) {
+			showNextCollection(state, prefetchCh)
+			return false
+		}
 		return handleBrowseInput(state, ev, out)
 	}
 
@@ -348,13 +371,18 @@ func handleInput(state *appState, ev inputEvent, out *bufio.Writer, prefetchCh c
 }
 
 func handleQueryInput(state *appState, ev inputEvent, out *bufio.Writer, prefetchCh chan<- prefetchResult) bool {
+	if state.rsearch != nil && handleReverseSearchInput(state, ev) {
+		return false
+	}
 	switch ev.kind {
 	case keyRune:
 		state.query += string(ev.ch)
+		state.historyBack = 0
 		state.renderDirty = true
 	case keyBackspace:
 		if len(state.query) > 0 {
 			state.query = state.query[:len(state.query)-1]
+			state.historyBack = 0
 			state.renderDirty = true
 		}
 	case keyEnter:
@@ -363,14 +391,21 @@ func handleQueryInput(state *appState, ev inputEvent, out *bufio.Writer, prefetc
 			state.renderDirty = true
 			return false
 		}
+		text, collection := collectionQuery(state.query)
+		if collection && openCollectionQuery(state, state.query, prefetchCh) {
+			return false
+		}
 		state.status = "Searching..."
 		render(state, out, state.lastRows, state.lastCols)
 		_ = out.Flush()
 
-		results, err := search.Search(state.query, state.opts)
+		results, err := search.Search(text, state.opts)
 		if err != nil {
 			state.status = "Search error: " + err.Error()
 		} else {
+			recordSearch(state, state.query, len(results))
+			state.viewing = ""
+			state.sources = nil
 			state.results = results
 			state.selected = 0
 			state.scroll = 0
@@ -395,7 +430,13 @@ func handleQueryInput(state *appState, ev inputEvent, out *bufio.Writer, prefetc
 		}
 	case keyCtrlC:
 		return true
-	case keyUp, keyDown, keyUnknown:
+	case keyUp:
+		historyPrev(state)
+	case keyDown:
+		historyNext(state)
+	case keyCtrlR:
+		startReverseSearch(state)
+	case keyUnknown:
 		// ignore
 	}
 	return false
@@ -404,18 +445,34 @@ func handleQueryInput(state *appState, ev inputEvent, out *bufio.Writer, prefetc
 func handleBrowseInput(state *appState, ev inputEvent, out *bufio.Writer) bool {
 	switch ev.kind {
 	case keyRune:
-		if ev.ch == '/' {
+		if ev.ch == state.keys.key(actionSearch) {
 			state.mode = modeQuery
 			state.status = "Type a search and press Enter"
 			state.renderDirty = true
 			return false
 		}
 		switch ev.ch {
-		case 'd':
+		case state.keys.key(actionDownload):
 			downloadSelected(state, out, state.opts.Reveal)
 			return false
-		case 'f':
+		case state.keys.key(actionReveal):
 			return handleRevealSelected(state, out)
+		case state.keys.key(actionOpen):
+			openSelected(state)
+			return false
+		case state.keys.key(actionStar):
+			starSelected(state)
+			return false
+		case state.keys.key(actionShowURL):
+			state.showURL = !state.showURL
+			state.renderDirty = true
+			return false
+		case state.keys.key(actionCopy):
+			copySelected(state, out, false)
+			return false
+		case state.keys.key(actionCopyGIF):
+			copySelected(state, out, true)
+			return false
 		default:
 		}
 		if ev.ch >= 0x20 {
@@ -446,6 +503,9 @@ func handleBrowseInput(state *appState, ev inputEvent, out *bufio.Writer) bool {
 	case keyEsc:
 		state.mode = modeQuery
 		state.renderDirty = true
+	case keyCtrlR:
+		state.mode = modeQuery
+		startReverseSearch(state)
 	case keyCtrlC:
 		return true
 	case keyBackspace, keyUnknown:
@@ -497,6 +557,10 @@ func render(state *appState, out *bufio.Writer, rows, cols int) {
 		}
 		state.activeImageID = 0
 	}
+	if state.currentAnim == nil && state.gridLast.cols > 0 {
+		clearItermRectFn(out, state.gridLast.row, state.gridLast.col, state.gridLast.cols, state.gridLast.rows)
+		state.gridLast = itermRect{}
+	}
 
 	if !state.headerFlashAt.IsZero() && nowFn().After(state.headerFlashAt) {
 		state.headerFlash = ""
@@ -538,6 +602,7 @@ func render(state *appState, out *bufio.Writer, rows, cols int) {
 type layout struct {
 	rows, cols                     int
 	statusRow, searchRow, hintsRow int
+	urlLines                       []string
 	contentTop, contentBottom      int
 	contentHeight                  int
 	listCol, listWidth             int
@@ -551,8 +616,17 @@ type layout struct {
 
 func buildLayout(state *appState, rows, cols int) layout {
 	layout := layout{rows: rows, cols: cols}
-	layout.searchRow = rows - 2
-	layout.statusRow = rows - 1
+	footerRows := 2 // status + hints
+	if url, ok := selectedURLLine(state); ok {
+		layout.urlLines = wrapRunes(url, cols)
+		// Keep the header, one content row and the search row.
+		footerRows = maxInt(footerRows, minInt(len(layout.urlLines), rows-3))
+		if len(layout.urlLines) > footerRows {
+			layout.urlLines = layout.urlLines[:footerRows]
+		}
+	}
+	layout.searchRow = rows - footerRows
+	layout.statusRow = rows - footerRows + 1
 	layout.hintsRow = rows
 	if layout.searchRow < 2 {
 		return layout
@@ -579,7 +653,7 @@ func buildLayout(state *appState, rows, cols int) layout {
 	layout.showRight = showRight
 
 	if showRight {
-		layout.previewCols, layout.previewRows = fitPreviewSize(maxPreviewCols, layout.contentHeight, state.currentAnim)
+		layout.previewCols, layout.previewRows = fitPreviewSize(maxPreviewCols, layout.contentHeight, state.currentAnim, cellAspectRatio(state.cell))
 	} else {
 		availRows := layout.contentHeight / 2
 		if availRows < 6 {
@@ -588,7 +662,7 @@ func buildLayout(state *appState, rows, cols int) layout {
 		if availRows > layout.contentHeight-2 {
 			availRows = maxInt(0, layout.contentHeight-2)
 		}
-		layout.previewCols, layout.previewRows = fitPreviewSize(cols, availRows, state.currentAnim)
+		layout.previewCols, layout.previewRows = fitPreviewSize(cols, availRows, state.currentAnim, cellAspectRatio(state.cell))
 	}
 	if state.currentAnim == nil {
 		layout.previewCols = 0
@@ -689,6 +763,18 @@ func drawPreviewIfNeeded(out *bufio.Writer, state *appState, layout layout) {
 }
 
 func drawStatus(out *bufio.Writer, state *appState, layout layout) {
+	if len(layout.urlLines) > 0 {
+		// Plain text across the full width, so a mouse selection copies just
+		// the URL.
+		if state.giphyAttributionShown && state.inline == termcaps.InlineKitty {
+			kitty.DeleteImage(out, giphyAttributionImageID)
+			state.giphyAttributionShown = false
+		}
+		for i, line := range layout.urlLines {
+			writeLineAt(out, layout.statusRow+i, 1, line, layout.cols)
+		}
+		return
+	}
 	status := state.status
 	if status == "" {
 		status = fmt.Sprintf("%d results", len(state.results))
@@ -709,7 +795,7 @@ func drawStatus(out *bufio.Writer, state *appState, layout layout) {
 	writeLineAt(out, layout.statusRow, 1, line, statusWidth)
 	if showGiphyIcon && layout.cols >= logoCols {
 		moveCursor(out, layout.statusRow, maxInt(1, layout.cols-logoCols+1))
-		kitty.SendFrame(out, giphyAttributionImageID, gifdecode.Frame{PNG: assets.GiphyIcon32PNG()}, logoCols, logoRows)
+		kitty.SendFrame(out, giphyAttributionImageID, gifdecode.Frame{PNG: assets.GiphyIcon32PNG()}, logoCols, logoRows, kitty.Options{})
 		state.giphyAttributionShown = true
 	} else if state.giphyAttributionShown && state.inline == termcaps.InlineKitty {
 		kitty.DeleteImage(out, giphyAttributionImageID)
@@ -745,11 +831,21 @@ func drawSearch(out *bufio.Writer, state *appState, layout layout) {
 			pill = styleIf(true, " Search ", bg, "\x1b[90m")
 		}
 	}
+	if rs := state.rsearch; rs != nil {
+		label := "(reverse-i-search)`" + rs.term + "':"
+		if rs.failed {
+			label = "(failed reverse-i-search)`" + rs.term + "':"
+		}
+		pill = styleIf(state.useColor, label, "\x1b[33m")
+	}
 	searchLine := pill + " " + query
 	writeLineAt(out, layout.searchRow, 1, searchLine, layout.cols)
 }
 
 func drawHints(out *bufio.Writer, state *appState, layout layout) {
+	if layout.statusRow+len(layout.urlLines)-1 >= layout.hintsRow {
+		return // the wrapped URL covers the hints row
+	}
 	formatHint := func(key, label string) string {
 		if !state.useColor {
 			return key + " " + label
@@ -758,11 +854,16 @@ func drawHints(out *bufio.Writer, state *appState, layout layout) {
 	}
 	hints := strings.Join([]string{
 		formatHint("⏎", "Search"),
-		formatHint("/", "Edit"),
+		formatHint(string(state.keys.key(actionSearch)), "Edit"),
 		formatHint("↑↓", "Select"),
-		formatHint("d", "Download"),
-		formatHint("f", "Reveal"),
-		formatHint("q", "Quit"),
+		formatHint(string(state.keys.key(actionDownload)), "Download"),
+		formatHint(string(state.keys.key(actionReveal)), "Reveal"),
+		formatHint(string(state.keys.key(actionStar)), "Star"),
+		formatHint(string(state.keys.key(actionBrowseCollections)), "Collections"),
+		formatHint(string(state.keys.key(actionOpen)), "Open"),
+		formatHint(string(state.keys.key(actionShowURL)), "URL"),
+		formatHint(string(state.keys.key(actionCopy))+"/"+string(state.keys.key(actionCopyGIF)), "Copy"),
+		formatHint(string(state.keys.key(actionQuit)), "Quit"),
 	}, "  ")
 	// Hints live below the content area; center across the full terminal width,
 	// even when the content is split (preview left / list right).
@@ -773,7 +874,7 @@ func drawHints(out *bufio.Writer, state *appState, layout layout) {
 
 func clearUnused(out *bufio.Writer, layout layout) {
 	for row := 1; row <= layout.rows; row++ {
-		if row == 1 || (row >= layout.contentTop && row <= layout.contentBottom) || row == layout.statusRow || row == layout.searchRow || row == layout.hintsRow {
+		if row == 1 || (row >= layout.contentTop && row <= layout.contentBottom) || row == layout.searchRow || (row >= layout.statusRow && row <= layout.hintsRow) {
 			continue
 		}
 		writeLineAt(out, row, 1, "", layout.cols)
@@ -807,14 +908,15 @@ func availablePreviewSize(rows, cols, leftWidth int, showRight bool) (int, int)
 	return availCols, availRows
 }
 
-func fitPreviewSize(availCols, availRows int, anim *gifAnimation) (int, int) {
+// fitPreviewSize fits the animation into the available cells; aspect is cell
+// width/height.
+func fitPreviewSize(availCols, availRows int, anim *gifAnimation, aspect float64) (int, int) {
 	if availCols <= 0 || availRows <= 0 {
 		return 0, 0
 	}
 	if anim == nil || anim.Width <= 0 || anim.Height <= 0 {
 		return availCols, availRows
 	}
-	aspect := cellAspectRatio()
 	targetCols := availCols
 	targetRows := int(math.Round(float64(targetCols) * aspect * float64(anim.Height) / float64(anim.Width)))
 	if targetRows > availRows {
@@ -861,12 +963,13 @@ func drawPreview(state *appState, out *bufio.Writer, cols, rows int, row, col in
 		}
 		saveCursor(out)
 		moveCursor(out, row, col)
-		iterm.SendInlineFile(out, iterm.File{
+		// Large GIFs go in parts where iTerm2 supports it.
+		iterm.SendFile(out, iterm.File{
 			Name:        "gifgrep.gif",
 			Data:        state.currentAnim.RawGIF,
 			WidthCells:  cols,
 			HeightCells: rows,
-		})
+		}, state.itermMultipart)
 		restoreCursor(out)
 		state.previewNeedsSend = false
 		state.previewDirty = false
@@ -878,6 +981,10 @@ func drawPreview(state *appState, out *bufio.Writer, cols, rows int, row, col in
 		state.itermLast.rows = rows
 		return
 	}
+	if drawsInGrid(state.inline) {
+		drawPreviewGrid(state, out, cols, rows, row, col)
+		return
+	}
 	if len(state.currentAnim.Frames) == 0 {
 		return
 	}
@@ -890,7 +997,8 @@ func drawPreview(state *appState, out *bufio.Writer, cols, rows int, row, col in
 			kitty.DeleteImage(out, state.activeImageID)
 		}
 		state.activeImageID = state.currentAnim.ID
-		kitty.SendAnimation(out, state.currentAnim.ID, state.currentAnim.Frames, cols, rows)
+		kitty.SendAnimation(out, state.currentAnim.ID, state.currentAnim.Frames, cols, rows, state.kitty)
+		writeKittyPlaceholders(state, out, row, col, cols, rows)
 		state.previewNeedsSend = false
 		state.previewDirty = false
 		state.lastPreview.cols = cols
@@ -898,13 +1006,28 @@ func drawPreview(state *appState, out *bufio.Writer, cols, rows int, row, col in
 		return
 	}
 	if state.previewDirty || state.lastPreview.cols != cols || state.lastPreview.rows != rows {
-		kitty.PlaceImage(out, state.activeImageID, cols, rows)
+		kitty.PlaceImage(out, state.activeImageID, cols, rows, state.kitty)
+		writeKittyPlaceholders(state, out, row, col, cols, rows)
 		state.previewDirty = false
 		state.lastPreview.cols = cols
 		state.lastPreview.rows = rows
 	}
 }
 
+// writeKittyPlaceholders fills the preview rect with placeholder cells for
+// the active image; with U=1 nothing shows until they are in the text grid.
+func writeKittyPlaceholders(state *appState, out *bufio.Writer, row, col, cols, rows int) {
+	if !state.kitty.Placeholders {
+		return
+	}
+	saveCursor(out)
+	for r := 0; r < rows; r++ {
+		moveCursor(out, row+r, col)
+		_, _ = fmt.Fprint(out, kitty.PlaceholderRow(state.activeImageID, r, cols))
+	}
+	restoreCursor(out)
+}
+
 func writeLineAt(out *bufio.Writer, row, col int, text string, width int) {
 	moveCursor(out, row, col)
 	if width <= 0 {
@@ -930,8 +1053,9 @@ func drawPreviewSoftware(state *appState, out *bufio.Writer, cols, rows int, row
 		frame := state.currentAnim.Frames[state.manualFrame]
 		saveCursor(out)
 		moveCursor(out, row, col)
-		kitty.SendFrame(out, state.activeImageID, frame, cols, rows)
+		kitty.SendFrame(out, state.activeImageID, frame, cols, rows, state.kitty)
 		restoreCursor(out)
+		writeKittyPlaceholders(state, out, row, col, cols, rows)
 		state.manualNext = time.Now().Add(frame.Delay)
 		state.previewNeedsSend = false
 		state.previewDirty = false
@@ -943,8 +1067,9 @@ func drawPreviewSoftware(state *appState, out *bufio.Writer, cols, rows int, row
 		frame := state.currentAnim.Frames[state.manualFrame]
 		saveCursor(out)
 		moveCursor(out, row, col)
-		kitty.SendFrame(out, state.activeImageID, frame, cols, rows)
+		kitty.SendFrame(out, state.activeImageID, frame, cols, rows, state.kitty)
 		restoreCursor(out)
+		writeKittyPlaceholders(state, out, row, col, cols, rows)
 		state.previewDirty = false
 		state.lastPreview.cols = cols
 		state.lastPreview.rows = rows
@@ -970,10 +1095,19 @@ func advanceManualAnimation(state *appState, out *bufio.Writer) {
 	}
 	state.manualFrame = (state.manualFrame + 1) % len(state.currentAnim.Frames)
 	frame := state.currentAnim.Frames[state.manualFrame]
-	saveCursor(out)
-	moveCursor(out, state.previewRow, state.previewCol)
-	kitty.SendFrame(out, state.activeImageID, frame, state.lastPreview.cols, state.lastPreview.rows)
-	restoreCursor(out)
+	if drawsInGrid(state.inline) {
+		writeGridFrame(state, out, state.manualFrame, itermRect{
+			row:  state.previewRow,
+			col:  state.previewCol,
+			cols: state.lastPreview.cols,
+			rows: state.lastPreview.rows,
+		})
+	} else {
+		saveCursor(out)
+		moveCursor(out, state.previewRow, state.previewCol)
+		kitty.SendFrame(out, state.activeImageID, frame, state.lastPreview.cols, state.lastPreview.rows, state.kitty)
+		restoreCursor(out)
+	}
 	state.manualNext = now.Add(frame.Delay)
 	_ = out.Flush()
 }
@@ -28,7 +28,7 @@ func TestPreviewSize(t *testing.T) {
 	}
 
 	anim := &gifAnimation{Width: 200, Height: 100}
-	fc, fr := fitPreviewSize(78, 36, anim)
+	fc, fr := fitPreviewSize(78, 36, anim, 0.5)
 	if fc != 78 || fr != 20 {
 		t.Fatalf("unexpected fit size: %d %d", fc, fr)
 	}
@@ -137,7 +137,7 @@ func TestHandleInputAndLoad(t *testing.T) {
 }
 
 func TestReadInput(t *testing.T) {
-	data := []byte{0x03, 'a', 0x7f, '\r', 0x1b, '[', 'A', 0x1b, '[', 'B', 0x1b, '[', 'C', 0x1b}
+	data := []byte{0x03, 'a', 0x7f, '\r', 0x12, 0x1b, '[', 'A', 0x1b, '[', 'B', 0x1b, '[', 'C', 0x1b}
 	r := bytes.NewReader(data)
 	ch := make(chan inputEvent, 10)
 	stop := make(chan struct{})
@@ -156,6 +156,9 @@ func TestReadInput(t *testing.T) {
 	if len(kinds) < 6 {
 		t.Fatalf("expected events, got %d", len(kinds))
 	}
+	if kinds[4] != keyCtrlR {
+		t.Fatalf("expected Ctrl-R, got %v", kinds)
+	}
 }
 
 func TestDrawPreview(t *testing.T) {
@@ -4,6 +4,8 @@ import (
 	"time"
 
 	"github.com/steipete/gifgrep/gifdecode"
+	"github.com/steipete/gifgrep/internal/blocks"
+	"github.com/steipete/gifgrep/internal/kitty"
 	"github.com/steipete/gifgrep/internal/model"
 	"github.com/steipete/gifgrep/internal/termcaps"
 )
@@ -64,6 +66,13 @@ type appState struct {
 		cols int
 		rows int
 	}
+	gridLast              itermRect
+	gridCache             gridFrames
+	blocks                blocks.Options
+	kitty                 kitty.Options
+	itermMultipart        bool
+	keys                  keymap
+	cell                  termcaps.CellSize
 	previewNeedsSend      bool
 	previewDirty          bool
 	nextImageID           uint32
@@ -76,4 +85,12 @@ type appState struct {
 	opts                  model.Options
 	giphyAttributionShown bool
 	lastSavedPath         string
+	showURL               bool
+	collection            string
+	viewing               string
+	sources               map[string]string
+	history               []string
+	historyBack           int
+	historyDraft          string
+	rsearch               *reverseSearch
 }
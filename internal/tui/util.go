@@ -7,6 +7,7 @@ import (
 	"unicode/utf8"
 
 	"github.com/mattn/go-runewidth"
+	"github.com/steipete/gifgrep/internal/termcaps"
 )
 
 func truncateRunes(s string, width int) string {
@@ -20,6 +21,27 @@ func truncateRunes(s string, width int) string {
 	return string(runes[:width])
 }
 
+// wrapRunes splits s into lines of at most width terminal columns.
+func wrapRunes(s string, width int) []string {
+	if width <= 0 || s == "" {
+		return nil
+	}
+	var lines []string
+	var line strings.Builder
+	visible := 0
+	for _, r := range s {
+		w := maxInt(0, runewidth.RuneWidth(r))
+		if visible+w > width && visible > 0 {
+			lines = append(lines, line.String())
+			line.Reset()
+			visible = 0
+		}
+		line.WriteRune(r)
+		visible += w
+	}
+	return append(lines, line.String())
+}
+
 func truncateANSI(s string, width int) string {
 	if width <= 0 {
 		return ""
@@ -109,12 +131,17 @@ func minInt(a, b int) int {
 	return b
 }
 
-func cellAspectRatio() float64 {
+// cellAspectRatio returns cell width/height: GIFGREP_CELL_ASPECT, else the
+// detected cell size, else the common 1:2.
+func cellAspectRatio(cell termcaps.CellSize) float64 {
 	if raw := strings.TrimSpace(os.Getenv("GIFGREP_CELL_ASPECT")); raw != "" {
 		if v, err := strconv.ParseFloat(raw, 64); err == nil && v > 0.1 && v < 2 {
 			return v
 		}
 	}
+	if a := cell.Aspect(); a > 0.1 && a < 2 {
+		return a
+	}
 	return 0.5
 }
 
@@ -5,6 +5,8 @@ import (
 	"bytes"
 	"strings"
 	"testing"
+
+	"github.com/steipete/gifgrep/internal/termcaps"
 )
 
 func TestHelpers(t *testing.T) {
@@ -47,9 +49,16 @@ func TestHelpers(t *testing.T) {
 	}
 
 	t.Setenv("GIFGREP_CELL_ASPECT", "0.7")
-	if cellAspectRatio() != 0.7 {
+	if cellAspectRatio(termcaps.CellSize{Width: 10, Height: 20}) != 0.7 {
 		t.Fatalf("cellAspectRatio env override failed")
 	}
+	t.Setenv("GIFGREP_CELL_ASPECT", "")
+	if cellAspectRatio(termcaps.CellSize{Width: 9, Height: 18}) != 0.5 || cellAspectRatio(termcaps.CellSize{Width: 10, Height: 25}) != 0.4 {
+		t.Fatalf("expected detected cell aspect")
+	}
+	if cellAspectRatio(termcaps.CellSize{}) != 0.5 {
+		t.Fatalf("expected default cell aspect")
+	}
 
 	t.Setenv("GIFGREP_SOFTWARE_ANIM", "true")
 	if !useSoftwareAnimation() {